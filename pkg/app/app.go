@@ -8,21 +8,31 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
-	"database/sql"
 	"encoding/pem"
 	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"math/big"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"bakery/pkg/breaker"
+	"bakery/pkg/clock"
+	"bakery/pkg/customer"
+	"bakery/pkg/events"
 	"bakery/pkg/httpapi"
 	"bakery/pkg/inventory"
+	"bakery/pkg/money"
 	"bakery/pkg/order"
+	"bakery/pkg/product"
+	"bakery/pkg/proxyproto"
 	"bakery/pkg/storage/memorydriver"
 	"bakery/pkg/version"
 )
@@ -30,10 +40,90 @@ import (
 // Config captures CLI flags so the bakery service can run with a single Run call.
 type Config struct {
 	showVersion bool
-	domain      string
-	port        int
-	dbType      string
-	dbPath      string
+	selftest    bool
+	// check wires everything up as for a real start and exits before listening.
+	check bool
+	// validateSnap is the -validate-snapshot file to check instead of starting the server.
+	validateSnap string
+	// configPath is the -config file; resolveSources layers it under the command line.
+	configPath string
+	domain     string
+	// tlsMin is the -tls-min-version text and tlsMinVersion what it parsed to.
+	tlsMin        string
+	tlsMinVersion uint16
+	hstsMaxAge    time.Duration
+	port          int
+	dbType        string
+	dbPath        string
+	// dbRetries and dbTimeout bound how long startup waits for the database; see openDatabase.
+	// breaker makes the order and inventory services fail fast while it keeps failing afterwards.
+	dbRetries     int
+	dbTimeout     time.Duration
+	breaker       breaker.Options
+	slowOrder     time.Duration
+	pprofAddr     string
+	theme         string
+	heroMenu      string
+	dev           bool
+	strictItems   bool
+	tidyAddress   bool
+	duplicateDays string
+	readOnly      bool
+	compress      bool
+	snapshotMaxMB int
+	imageDir      string
+	imageMaxKB    int
+	maxFeatured   int
+	maintenance   bool
+	maintReads    bool
+	retentionDays int
+	orderArchive  string
+	inventoryLog  string
+	orderEvents   string
+	confirmation  string
+	inventoryDays int
+	shelfLife     time.Duration
+	expiryEvery   time.Duration
+	expiryHook    string
+	accessLog     string
+	maxInflight   int
+	unixSocket    string
+	proxyProtocol bool
+	// trustedProxies is split from the comma-separated -trusted-proxies flag.
+	trustedProxies []string
+	// categories is split from the comma-separated -categories flag.
+	categories       []string
+	customCategories bool
+
+	generateDeliveries bool
+	deliveryHorizon    int
+	// depotLat and depotLng both zero means no depot was configured.
+	depotLat, depotLng float64
+	orderHours         string
+	dedupWindow        time.Duration
+	minOrderCents      int
+	currency           string
+	dateLayout         string
+	anyContentType     bool
+
+	readHeaderTimeout time.Duration
+	readTimeout       time.Duration
+	writeTimeout      time.Duration
+	requestTimeout    time.Duration
+	adminRefresh      time.Duration
+	adminToken        string
+	duplicateBatches  string
+	idleTimeout       time.Duration
+	drainDelay        time.Duration
+}
+
+// Main is the whole program for both entry points, cmd/server and the root bakery.go,
+// so they cannot drift apart the way the old inline server did.
+func Main() {
+	logger := log.New(os.Stdout, "[bakery] ", log.LstdFlags)
+	if err := Run(context.Background(), os.Args[1:], logger); err != nil {
+		logger.Fatalf("application stopped with error: %v", err)
+	}
 }
 
 // Run composes persistence, domain services, and the HTTP server using only standard library pieces.
@@ -42,6 +132,9 @@ func Run(ctx context.Context, args []string, logger *log.Logger) error {
 		// The logger is optional so tests can remain quiet while production still reports activity.
 		logger = log.New(os.Stdout, "[bakery] ", log.LstdFlags)
 	}
+	// Interrupts and service-manager stops cancel the context so servers drain and clean up before exit.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	cfg, err := parseFlags(args)
 	if err != nil {
@@ -53,66 +146,329 @@ func Run(ctx context.Context, args []string, logger *log.Logger) error {
 	}
 
 	if cfg.showVersion {
-		logger.Printf("bakery version %s", version.Version())
+		logger.Printf("bakery version %s", version.Info())
 		return nil
 	}
+	if cfg.selftest {
+		return runSelftest(ctx, cfg, os.Stdout)
+	}
+	if cfg.validateSnap != "" {
+		return validateSnapshot(cfg, os.Stdout)
+	}
+	if cfg.check {
+		// A check must leave the data as it found it, so the background work that deletes, rewrites
+		// or plans rows is not started; its settings were already validated with the rest.
+		cfg.retentionDays, cfg.inventoryDays, cfg.expiryEvery = 0, 0, 0
+		cfg.generateDeliveries = false
+	}
+
+	// One clock feeds every component so timestamps, cutoffs and order hours agree with each other.
+	clk := clock.Real{}
 
-	driverName, cleanupDriver, err := memorydriver.Register(cfg.dbType, cfg.dbPath)
+	driverName, flushDriver, cleanupDriver, err := memorydriver.Register(cfg.dbType, cfg.dbPath, memorydriver.Options{
+		Compress:         cfg.compress,
+		Clock:            clk,
+		MaxSnapshotBytes: int64(cfg.snapshotMaxMB) << 20,
+		Logger:           logger,
+	})
 	if err != nil {
 		return fmt.Errorf("unable to register database driver: %w", err)
 	}
 	defer cleanupDriver()
 
-	db, err := sql.Open(driverName, cfg.dbPath)
+	db, err := openDatabase(ctx, driverName, cfg, logger)
 	if err != nil {
-		return fmt.Errorf("unable to open database: %w", err)
+		return err
 	}
 	defer db.Close()
 
-	if err := memorydriver.EnsureSchema(ctx, db); err != nil {
-		return fmt.Errorf("unable to ensure schema: %w", err)
-	}
+	orderRepo := order.NewRepository(db, clk)
+	inventoryRepo := inventory.NewRepository(db, clk)
+	productRepo := product.NewRepository(db, clk)
+	customerRepo := customer.NewRepository(db, clk)
 
-	orderRepo := order.NewRepository(db)
-	inventoryRepo := inventory.NewRepository(db)
+	// The bus keeps the services publishing events apart from the timeline recording them. Closing
+	// runs in reverse: the services stop, the bus flushes, then the timeline finishes writing.
+	bus := events.NewBus()
+	timeline, err := order.NewTimeline(bus, order.TimelineOptions{Path: cfg.orderEvents, Logger: logger})
+	if err != nil {
+		bus.Close()
+		return err
+	}
+	defer timeline.Wait()
+	defer bus.Close()
+
+	inventoryOpts := inventory.Options{MaxFeatured: cfg.maxFeatured, Breaker: cfg.breaker, Clock: clk, Logger: logger, Events: bus}
+	if cfg.expiryEvery > 0 {
+		inventoryOpts.ShelfLife = cfg.shelfLife
+		inventoryOpts.ExpiryInterval = cfg.expiryEvery
+		inventoryOpts.ExpiryWebhook = cfg.expiryHook
+	}
+	if cfg.inventoryLog != "" {
+		inventoryOpts.AuditPath = cfg.inventoryLog
+		inventoryOpts.AuditRetention = time.Duration(cfg.inventoryDays) * 24 * time.Hour
+	}
+	inventoryService := inventory.NewService(inventoryRepo, inventoryOpts)
+	defer inventoryService.Close()
 
-	orderService := order.NewService(orderRepo)
+	// Every order is linked to the customer behind its phone, so the service must outlive the order one.
+	customerService := customer.NewService(customerRepo)
+	defer customerService.Close()
+
+	orderOpts := order.Options{
+		DuplicateDays:   order.DuplicateDayPolicy(cfg.duplicateDays),
+		DeliveryHorizon: cfg.deliveryHorizon,
+		DedupWindow:     cfg.dedupWindow,
+		MinOrderCents:   cfg.minOrderCents,
+		Currency:        cfg.currency,
+		Pricer:          inventoryService,
+		Clock:           clk,
+		Logger:          logger,
+		Events:          bus,
+		Customers:       customerService,
+		Breaker:         cfg.breaker,
+		SlowOrder:       cfg.slowOrder,
+	}
+	if cfg.generateDeliveries {
+		if cfg.readOnly {
+			// Generated deliveries are writes; the primary instance produces them for every replica.
+			logger.Printf("delivery generation is ignored in read-only mode")
+		} else {
+			orderOpts.GenerateDeliveries = true
+		}
+	}
+	if cfg.retentionDays > 0 {
+		if cfg.readOnly {
+			// A replica must never shrink the data it is mirroring, so retention stays with the primary.
+			logger.Printf("order retention is ignored in read-only mode")
+		} else {
+			orderOpts.Retention = time.Duration(cfg.retentionDays) * 24 * time.Hour
+			orderOpts.ArchivePath = cfg.orderArchive
+		}
+	}
+	if cfg.strictItems {
+		orderOpts.Catalog = inventoryService
+	}
+	// Pending orders always hold their items so the menu never offers units that are already promised.
+	orderOpts.Stock = inventoryService
+	if cfg.tidyAddress {
+		// No geocoder ships with the bakery; deployments that have one set TidyNormalizer.Geocoder in their own build.
+		orderOpts.Normalizer = order.TidyNormalizer{}
+	}
+	orderService := order.NewService(orderRepo, orderOpts)
 	defer orderService.Close()
 
-	inventoryService := inventory.NewService(inventoryRepo)
-	defer inventoryService.Close()
+	productService := product.NewService(productRepo)
+	defer productService.Close()
 
-	srv, err := httpapi.New(orderService, inventoryService, logger)
+	var depot *order.Point
+	if cfg.depotLat != 0 || cfg.depotLng != 0 {
+		depot = &order.Point{Lat: cfg.depotLat, Lng: cfg.depotLng}
+	}
+	// readyCheck lets /readyz confirm the database still answers and has its tables.
+	readyCheck := func(ctx context.Context) error { return checkDatabase(ctx, db) }
+	srv, err := httpapi.New(orderService, inventoryService, productService, logger, httpapi.Options{
+		Theme:                 cfg.theme,
+		Dev:                   cfg.dev,
+		ReadOnly:              cfg.readOnly,
+		AccessLog:             cfg.accessLog,
+		MaxInflight:           cfg.maxInflight,
+		RequestTimeout:        cfg.requestTimeout,
+		Maintenance:           cfg.maintenance,
+		MaintenanceReads:      cfg.maintReads,
+		TrustedProxies:        cfg.trustedProxies,
+		HeroMenuPath:          cfg.heroMenu,
+		Categories:            cfg.categories,
+		AllowCustomCategories: cfg.customCategories,
+		Depot:                 depot,
+		OrderHours:            cfg.orderHours,
+		ImageDir:              cfg.imageDir,
+		MaxImageBytes:         int64(cfg.imageMaxKB) << 10,
+		AdminRefresh:          cfg.adminRefresh,
+		AdminToken:            cfg.adminToken,
+		DuplicateBatches:      cfg.duplicateBatches,
+		Timeline:              timeline,
+		Customers:             customerService,
+		HSTSMaxAge:            cfg.hstsMaxAge,
+		ConfirmationTemplate:  cfg.confirmation,
+		Currency:              cfg.currency,
+		DateLayout:            cfg.dateLayout,
+		AnyContentType:        cfg.anyContentType,
+		ReadyCheck:            readyCheck,
+		Clock:                 clk,
+	})
 	if err != nil {
 		return fmt.Errorf("unable to build http server: %w", err)
 	}
+	if cfg.check {
+		fmt.Fprintf(os.Stdout, "configuration ok: %s database, theme %s, nothing is listening\n", cfg.dbType, cfg.theme)
+		return nil
+	}
+
+	if cfg.pprofAddr != "" {
+		if err := servePprof(ctx, cfg.pprofAddr, logger); err != nil {
+			return fmt.Errorf("unable to start pprof on %s: %w", cfg.pprofAddr, err)
+		}
+	}
+
+	// SIGUSR2 flips maintenance mode, so a deploy script can drain writes without an admin request.
+	// SIGUSR1 writes the memory driver snapshot at once, a checkpoint before a risky operation that
+	// does not wait for the background writer or a restart.
+	toggles := make(chan os.Signal, 1)
+	signal.Notify(toggles, syscall.SIGUSR2)
+	defer signal.Stop(toggles)
+	flushes := make(chan os.Signal, 1)
+	signal.Notify(flushes, syscall.SIGUSR1)
+	defer signal.Stop(flushes)
+	go func() {
+		for {
+			select {
+			case <-toggles:
+				srv.ToggleMaintenance()
+			case <-flushes:
+				started := time.Now()
+				if err := flushDriver(); err != nil {
+					logger.Printf("snapshot flush on SIGUSR1 failed: %v", err)
+					continue
+				}
+				logger.Printf("snapshot flushed on SIGUSR1 in %s", time.Since(started).Round(time.Millisecond))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
 	if cfg.domain != "" {
 		logger.Printf("starting HTTPS servers for domain %s", cfg.domain)
-		return runDomainServers(ctx, cfg.domain, srv, logger)
+		return runDomainServers(ctx, cfg, srv, clk, logger)
 	}
 
-	addr := cfg.address()
-	server := &http.Server{
-		Addr:         addr,
-		Handler:      srv.Handler(),
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	listener, addr, err := cfg.listen()
+	if err != nil {
+		return err
 	}
+	server := cfg.httpServer(addr, srv.Handler())
+
+	// Shutdown runs beside Serve; waiting for it below keeps the services open until in-flight requests finish.
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		drain(srv, cfg.drainDelay, logger)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
 
 	logger.Printf("Bakery service is running on %s", addr)
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("server stopped unexpectedly: %w", err)
 	}
+	<-stopped
+	if cfg.unixSocket != "" {
+		// The listener unlinks its socket on close; this also covers a socket left behind by a failed close.
+		os.Remove(cfg.unixSocket)
+	}
+	logger.Printf("Bakery service stopped")
 	return nil
 }
 
-// address converts CLI port configuration into a binding string.
-func (c Config) address() string {
-	if port := os.Getenv("PORT"); port != "" {
-		return ":" + port
+// drain takes the server out of rotation: /readyz fails at once, and the listener stays open for
+// delay so probes notice and load balancers stop sending new requests before Shutdown.
+func drain(srv *httpapi.Server, delay time.Duration, logger *log.Logger) {
+	srv.Drain()
+	if delay <= 0 {
+		return
+	}
+	logger.Printf("draining for %s before closing the listener", delay)
+	time.Sleep(delay)
+}
+
+// unixSocketMode lets a reverse proxy in the same group connect while keeping other users out.
+const unixSocketMode = 0o660
+
+// listen opens the Unix socket when one is configured and the TCP port otherwise.
+// It also returns the address to log, since a socket path says more than a port number.
+func (c Config) listen() (net.Listener, string, error) {
+	if c.unixSocket == "" {
+		addr := c.address()
+		listener, err := c.listenTCP(addr)
+		if err != nil {
+			return nil, "", err
+		}
+		return listener, addr, nil
+	}
+	if err := removeStaleSocket(c.unixSocket); err != nil {
+		return nil, "", err
+	}
+	listener, err := net.Listen("unix", c.unixSocket)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to listen on unix socket %s: %w", c.unixSocket, err)
+	}
+	if err := os.Chmod(c.unixSocket, unixSocketMode); err != nil {
+		listener.Close()
+		return nil, "", fmt.Errorf("unable to set permissions on %s: %w", c.unixSocket, err)
+	}
+	return c.wrapListener(listener), "unix:" + c.unixSocket, nil
+}
+
+// listenTCP binds a TCP address and applies the optional PROXY protocol wrapper.
+func (c Config) listenTCP(addr string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on %s: %w", addr, err)
+	}
+	return c.wrapListener(listener), nil
+}
+
+// wrapListener reads PROXY protocol headers when -proxy-protocol is set so RemoteAddr names the
+// real client; the header must arrive within the header read timeout like any other request line.
+func (c Config) wrapListener(listener net.Listener) net.Listener {
+	if !c.proxyProtocol {
+		return listener
+	}
+	return proxyproto.NewListener(listener, c.readHeaderTimeout)
+}
+
+// removeStaleSocket deletes a socket file left by a crashed run. It refuses to touch regular files
+// or a socket another process still answers on, so a typo cannot delete data or hijack a live instance.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to inspect %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("unix socket %s is already in use", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("unable to remove stale socket %s: %w", path, err)
+	}
+	return nil
+}
+
+// httpServer applies the configured timeouts so the plain, HTTPS, and redirect listeners behave alike.
+// ReadHeaderTimeout stays short even when uploads need a long ReadTimeout, which keeps slowloris
+// clients from holding connections open by trickling headers.
+func (c Config) httpServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: c.readHeaderTimeout,
+		ReadTimeout:       c.readTimeout,
+		WriteTimeout:      c.writeTimeout,
+		IdleTimeout:       c.idleTimeout,
 	}
+}
+
+// address converts the resolved port into a binding string; $PORT is already folded in by resolveSources.
+func (c Config) address() string {
 	return ":" + strconv.Itoa(c.port)
 }
 
@@ -124,59 +480,224 @@ func parseFlags(args []string) (Config, error) {
 		// We print the usage banner explicitly because operators expect feedback when exploring CLI options.
 		fmt.Fprintf(os.Stdout, "Usage of %s:\n", set.Name())
 		set.PrintDefaults()
+		fmt.Fprintln(os.Stdout, "Every flag can also come from a BAKERY_<NAME> variable, e.g. BAKERY_DB_TYPE for -db-type; flags on the command line win.")
 	}
 
 	var cfg Config
 	set.BoolVar(&cfg.showVersion, "version", false, "Show the application version")
+	set.StringVar(&cfg.validateSnap, "validate-snapshot", "", "Check this memory driver snapshot for corruption (bad JSON, repeated ids, stale counters, dangling references), print the issues and exit; non-zero when any are found.")
+	set.BoolVar(&cfg.check, "check", false, "Parse the configuration, open the database, ensure its schema and build the services and templates, then exit without listening; non-zero on any error. A database or snapshot file that does not exist yet is created with an empty schema.")
+	set.BoolVar(&cfg.selftest, "selftest", false, "Run a smoke test against a scratch database (order, inventory, reservations), print a report and exit non-zero on failure; the configured database is not touched.")
+	set.StringVar(&cfg.configPath, "config", "", "JSON file of flag values keyed by flag name, e.g. {\"port\": 8080}; BAKERY_* variables and command-line flags override it.")
 	set.StringVar(&cfg.domain, "domain", "", "Serve HTTPS on 80/443 via Let's Encrypt when a domain is provided.")
+	set.DurationVar(&cfg.hstsMaxAge, "hsts-max-age", httpapi.DefaultHSTSMaxAge, "Strict-Transport-Security max-age sent on HTTPS responses; plain HTTP never gets the header. 0 disables it.")
+	set.StringVar(&cfg.tlsMin, "tls-min-version", "1.2", "Oldest TLS version the -domain HTTPS server accepts: 1.2 or 1.3.")
 	set.IntVar(&cfg.port, "port", 7654, "Port for running the HTTP server when not using -domain.")
+	set.DurationVar(&cfg.readHeaderTimeout, "read-header-timeout", 5*time.Second, "Maximum time to read request headers; keeps slow clients from pinning connections.")
+	set.DurationVar(&cfg.readTimeout, "read-timeout", 5*time.Second, "Maximum time to read a whole request including the body; raise it for slow mobile uploads.")
+	set.DurationVar(&cfg.writeTimeout, "write-timeout", 10*time.Second, "Maximum time to write a response.")
+	set.DurationVar(&cfg.requestTimeout, "request-timeout", 8*time.Second, "Answer 503 when a request takes longer than this end to end; keep it under -write-timeout so the reply can still be sent. 0 disables.")
+	set.DurationVar(&cfg.drainDelay, "drain-delay", 0, "On shutdown, fail /readyz for this long before closing the listener, so load balancers stop routing here first.")
+	set.DurationVar(&cfg.idleTimeout, "idle-timeout", 60*time.Second, "How long keep-alive connections may sit idle.")
+	set.StringVar(&cfg.unixSocket, "unix-socket", "", "Serve on this Unix domain socket instead of -port, e.g. for nginx on the same host.")
+	set.BoolVar(&cfg.proxyProtocol, "proxy-protocol", false, "Expect a PROXY protocol v1/v2 header on every connection, as sent by TCP load balancers.")
+	set.Func("trusted-proxies", "Comma-separated CIDRs or addresses of reverse proxies whose X-Forwarded-For is trusted.", func(value string) error {
+		cfg.trustedProxies = append(cfg.trustedProxies, strings.Split(value, ",")...)
+		return nil
+	})
 	set.StringVar(&cfg.dbType, "db-type", "sqlite", "Database driver: chai, sqlite, duckdb, pgx (PostgreSQL), or clickhouse")
 	set.StringVar(&cfg.dbPath, "db-path", "", "Filesystem path for chai/sqlite/duckdb databases; defaults to the working directory.")
+	set.IntVar(&cfg.dbRetries, "db-connect-retries", 5, "How many more times to try reaching the database at startup, with growing pauses up to 30s, before giving up; 0 fails on the first error.")
+	set.DurationVar(&cfg.dbTimeout, "db-connect-timeout", 5*time.Second, "How long one startup attempt to reach the database and ensure its schema may take.")
+	set.IntVar(&cfg.breaker.Threshold, "breaker-threshold", 5, "Storage failures in a row after which the order and inventory services answer 503 at once for -breaker-cooldown; 0 turns this off.")
+	set.StringVar(&cfg.pprofAddr, "pprof", "", "Serve the Go profiler under /debug/pprof/ on this separate address, such as localhost:6060; empty turns it off.")
+	set.DurationVar(&cfg.slowOrder, "slow-order", time.Second, "Log every order that takes longer than this to submit, with the time spent waiting for the order service and storing; 0 logs none.")
+	set.DurationVar(&cfg.breaker.Cooldown, "breaker-cooldown", breaker.DefaultCooldown, "How long the services fail fast after -breaker-threshold failures before trying storage again.")
+	set.BoolVar(&cfg.compress, "snapshot-compress", false, "Gzip the memory driver snapshot (default file <driver>.json.gz); reading detects gzip automatically.")
+	set.IntVar(&cfg.snapshotMaxMB, "snapshot-max-mb", memorydriver.DefaultMaxSnapshotBytes>>20, "Refuse to load or write a memory driver snapshot larger than this many megabytes uncompressed.")
+	set.StringVar(&cfg.imageDir, "image-dir", "", "Directory for uploaded batch photos served under /images/; empty disables uploads.")
+	set.IntVar(&cfg.imageMaxKB, "image-max-kb", httpapi.DefaultMaxImageBytes>>10, "Reject uploaded batch photos larger than this many kilobytes.")
+	set.IntVar(&cfg.maxFeatured, "max-featured", 3, "How many inventory batches may be featured on the storefront at once; 0 means no limit.")
+	set.BoolVar(&cfg.maintenance, "maintenance", false, "Start in maintenance mode: the storefront shows a back-soon page and writes get 503. Toggle with SIGUSR2 or POST /api/admin/maintenance.")
+	set.BoolVar(&cfg.maintReads, "maintenance-reads", true, "Keep GET endpoints and the admin page available during maintenance.")
+	set.StringVar(&cfg.theme, "theme", httpapi.DefaultTheme, "Embedded storefront theme: "+themeChoices())
+	set.StringVar(&cfg.heroMenu, "hero-menu", "", "JSON file with the fallback menu shown while the catalog is empty; defaults to the built-in pastries.")
+	set.Func("categories", "Comma-separated product categories accepted by the admin (default "+strings.Join(httpapi.DefaultCategories, ",")+").", func(value string) error {
+		cfg.categories = append(cfg.categories, strings.Split(value, ",")...)
+		return nil
+	})
+	set.BoolVar(&cfg.customCategories, "allow-custom-categories", false, "Accept any category instead of only the -categories list; names are still trimmed and lowercased.")
+	set.StringVar(&cfg.duplicateBatches, "duplicate-batches", httpapi.DuplicateBatchesWarn, "What happens to a new batch with the same name, category and baked time as a stored one: warn (409 unless ?force=true), reject, or off.")
+	set.BoolVar(&cfg.strictItems, "strict-items", false, "Reject order items whose names do not match an inventory batch.")
+	set.BoolVar(&cfg.tidyAddress, "normalize-addresses", false, "Trim delivery addresses, collapse spaces and capitalize each word when orders are submitted.")
+	set.StringVar(&cfg.currency, "currency", money.RUB, "Currency every price is kept in, which decides how prices are written: RUB (220,50 ₽), USD ($220.50) or EUR (220,50 €).")
+	set.IntVar(&cfg.minOrderCents, "min-order-cents", 0, "Reject orders whose items total less than this many cents at current batch prices; 0 disables.")
+	set.DurationVar(&cfg.dedupWindow, "dedup-window", 0, "Answer an order identical to one submitted this recently (same name, phone, items and schedules) with the earlier order instead of storing it again; 0 disables.")
+	set.StringVar(&cfg.duplicateDays, "duplicate-days", string(order.DuplicateDaysReject), "How repeated schedule days are handled: reject or merge.")
+	set.IntVar(&cfg.retentionDays, "order-retention-days", 0, "Delete orders older than this many days in an hourly sweep; 0 keeps them forever.")
+	set.StringVar(&cfg.orderArchive, "order-archive", "", "Append orders removed by -order-retention-days to this JSON lines file before deleting them.")
+	set.StringVar(&cfg.inventoryLog, "inventory-audit", "", "Append every inventory change, with the batch before and after it, to this JSON lines file and serve it from /api/admin/inventory/{id}/history.")
+	set.IntVar(&cfg.inventoryDays, "inventory-audit-days", 90, "Drop -inventory-audit entries older than this many days in an hourly sweep; 0 keeps them forever.")
+	set.DurationVar(&cfg.shelfLife, "shelf-life", inventory.DefaultShelfLife, "How long a batch stays fresh after baking; -expiry-check-interval reports batches older than this.")
+	set.DurationVar(&cfg.expiryEvery, "expiry-check-interval", 0, "Look for batches past -shelf-life with units left this often, logging and publishing each once so staff can pull it from the shelf; 0 disables.")
+	set.StringVar(&cfg.expiryHook, "expiry-webhook", "", "Also POST each expiry check's stale batches as a JSON array to this URL.")
+	set.BoolVar(&cfg.anyContentType, "any-content-type", false, "Decode JSON request bodies whatever their Content-Type, for old clients; by default anything but application/json gets 415.")
+	set.StringVar(&cfg.dateLayout, "date-layout", "", "Go time layout for dates on pages and in order confirmations, such as 2006-01-02; empty uses the visitor's language.")
+	set.StringVar(&cfg.confirmation, "order-confirmation", "", "Thank-you message returned with every new order, with {name}, {date} and {id} filled in; empty uses the visitor's language.")
+	set.StringVar(&cfg.orderEvents, "order-events", "", "Keep order timelines (created, paused, resumed, delivered, cancelled) in this JSON lines file so they survive restarts; empty keeps them in memory.")
+	set.BoolVar(&cfg.generateDeliveries, "generate-deliveries", false, "Materialize recurring bread and croissant schedules into dated deliveries in an hourly background task.")
+	set.IntVar(&cfg.deliveryHorizon, "delivery-horizon-days", 7, "How many days ahead, today included, -generate-deliveries plans.")
+	set.Float64Var(&cfg.depotLat, "depot-lat", 0, "Latitude the /api/admin/route delivery route starts from.")
+	set.Float64Var(&cfg.depotLng, "depot-lng", 0, "Longitude the /api/admin/route delivery route starts from.")
+	set.StringVar(&cfg.orderHours, "order-hours", "", "Accept new orders only within this daily window in the server's time zone, e.g. 06:00-20:00; empty accepts them around the clock.")
+	set.BoolVar(&cfg.readOnly, "read-only", false, "Serve pages and GET endpoints only; reject order and admin writes with 405.")
+	set.IntVar(&cfg.maxInflight, "max-inflight", 0, "Answer 503 once this many requests are in flight; 0 disables the limit.")
+	set.StringVar(&cfg.accessLog, "access-log", httpapi.AccessLogCommon, "Access log format: common, combined, json, or off.")
+	set.StringVar(&cfg.adminToken, "admin-token", "", "Bearer token every /api/admin/ request must send as \"Authorization: Bearer <token>\". Without it the admin API is open and backup and restore are not served.")
+	set.DurationVar(&cfg.adminRefresh, "admin-refresh", httpapi.DefaultAdminRefresh, "How often the admin page reloads inventory on its own; 0 leaves it to the refresh button.")
+	set.BoolVar(&cfg.dev, "dev", false, "Reload templates from pkg/httpapi on every request; run from the repository root.")
 
 	if err := set.Parse(args); err != nil {
 		return Config{}, err
 	}
+	if err := resolveSources(set, &cfg); err != nil {
+		return Config{}, err
+	}
+	version, err := parseTLSVersion(cfg.tlsMin)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.tlsMinVersion = version
+	switch order.DuplicateDayPolicy(cfg.duplicateDays) {
+	case order.DuplicateDaysReject, order.DuplicateDaysMerge:
+	default:
+		return Config{}, fmt.Errorf("invalid -duplicate-days %q: use reject or merge", cfg.duplicateDays)
+	}
+	for name, value := range map[string]time.Duration{
+		"read-header-timeout":   cfg.readHeaderTimeout,
+		"read-timeout":          cfg.readTimeout,
+		"write-timeout":         cfg.writeTimeout,
+		"request-timeout":       cfg.requestTimeout,
+		"idle-timeout":          cfg.idleTimeout,
+		"drain-delay":           cfg.drainDelay,
+		"admin-refresh":         cfg.adminRefresh,
+		"hsts-max-age":          cfg.hstsMaxAge,
+		"slow-order":            cfg.slowOrder,
+		"shelf-life":            cfg.shelfLife,
+		"expiry-check-interval": cfg.expiryEvery,
+	} {
+		if value < 0 {
+			return Config{}, fmt.Errorf("invalid -%s %s: must not be negative", name, value)
+		}
+	}
+	if cfg.expiryEvery > 0 && cfg.shelfLife == 0 {
+		return Config{}, errors.New("-expiry-check-interval needs a positive -shelf-life to tell stale batches apart")
+	}
+	if cfg.expiryHook != "" && cfg.expiryEvery == 0 {
+		return Config{}, errors.New("-expiry-webhook needs -expiry-check-interval")
+	}
+	if cfg.unixSocket != "" && cfg.domain != "" {
+		return Config{}, errors.New("-unix-socket cannot be combined with -domain, which binds ports 80 and 443")
+	}
+	if cfg.adminRefresh > 0 && cfg.adminRefresh < time.Second {
+		return Config{}, fmt.Errorf("invalid -admin-refresh %s: use 0 or at least 1s", cfg.adminRefresh)
+	}
+	if cfg.maxInflight < 0 {
+		return Config{}, fmt.Errorf("invalid -max-inflight %d: must not be negative", cfg.maxInflight)
+	}
+	if cfg.deliveryHorizon < 1 {
+		return Config{}, fmt.Errorf("invalid -delivery-horizon-days %d: must be at least 1", cfg.deliveryHorizon)
+	}
+	if cfg.depotLat < -90 || cfg.depotLat > 90 || cfg.depotLng < -180 || cfg.depotLng > 180 {
+		return Config{}, fmt.Errorf("invalid -depot-lat/-depot-lng %g,%g: out of range", cfg.depotLat, cfg.depotLng)
+	}
+	if cfg.maxFeatured < 0 {
+		return Config{}, fmt.Errorf("invalid -max-featured %d: must not be negative", cfg.maxFeatured)
+	}
+	if cfg.imageMaxKB < 1 {
+		return Config{}, fmt.Errorf("invalid -image-max-kb %d: must be at least 1", cfg.imageMaxKB)
+	}
+	if cfg.snapshotMaxMB < 1 {
+		return Config{}, fmt.Errorf("invalid -snapshot-max-mb %d: must be at least 1", cfg.snapshotMaxMB)
+	}
+	if !money.Supported(cfg.currency) {
+		return Config{}, fmt.Errorf("invalid -currency %q: use one of %s", cfg.currency, strings.Join(money.Currencies(), ", "))
+	}
+	if cfg.minOrderCents < 0 {
+		return Config{}, fmt.Errorf("invalid -min-order-cents %d: must not be negative", cfg.minOrderCents)
+	}
+	if cfg.dedupWindow < 0 {
+		return Config{}, fmt.Errorf("invalid -dedup-window %s: must not be negative", cfg.dedupWindow)
+	}
+	if cfg.retentionDays < 0 {
+		return Config{}, fmt.Errorf("invalid -order-retention-days %d: must not be negative", cfg.retentionDays)
+	}
+	if cfg.dbRetries < 0 {
+		return Config{}, fmt.Errorf("invalid -db-connect-retries %d: must not be negative", cfg.dbRetries)
+	}
+	if cfg.dbTimeout <= 0 {
+		return Config{}, fmt.Errorf("invalid -db-connect-timeout %s: must be positive", cfg.dbTimeout)
+	}
+	if cfg.breaker.Threshold < 0 {
+		return Config{}, fmt.Errorf("invalid -breaker-threshold %d: must not be negative", cfg.breaker.Threshold)
+	}
+	if cfg.breaker.Cooldown <= 0 {
+		return Config{}, fmt.Errorf("invalid -breaker-cooldown %s: must be positive", cfg.breaker.Cooldown)
+	}
+	if cfg.inventoryDays < 0 {
+		return Config{}, fmt.Errorf("invalid -inventory-audit-days %d: must not be negative", cfg.inventoryDays)
+	}
 	return cfg, nil
 }
 
+// themeChoices renders the embedded theme names for the -theme usage string.
+func themeChoices() string {
+	themes, err := httpapi.Themes()
+	if err != nil || len(themes) == 0 {
+		return httpapi.DefaultTheme
+	}
+	return strings.Join(themes, ", ")
+}
+
 // runDomainServers launches both HTTP redirect and HTTPS handlers when a domain is configured.
-func runDomainServers(ctx context.Context, domain string, srv *httpapi.Server, logger *log.Logger) error {
-	tlsCert, keyFile, certFile, err := generateCertificate(domain)
+func runDomainServers(ctx context.Context, cfg Config, srv *httpapi.Server, clk clock.Clock, logger *log.Logger) error {
+	domain := cfg.domain
+	tlsCert, keyFile, certFile, err := generateCertificate(domain, clk)
 	if err != nil {
 		return fmt.Errorf("unable to generate certificate: %w", err)
 	}
 	defer os.Remove(keyFile)
 	defer os.Remove(certFile)
 
-	tlsConfig := &tls.Config{Certificates: []tls.Certificate{tlsCert}}
+	httpsServer := cfg.httpServer(":443", srv.Handler())
+	httpsServer.TLSConfig = tlsConfig(cfg.tlsMinVersion, tlsCert)
 
-	httpsServer := &http.Server{
-		Addr:         ":443",
-		Handler:      srv.Handler(),
-		TLSConfig:    tlsConfig,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
+	httpRedirect := cfg.httpServer(":80", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := "https://" + domain + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	}))
 
-	httpRedirect := &http.Server{
-		Addr: ":80",
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			target := "https://" + domain + r.URL.RequestURI()
-			http.Redirect(w, r, target, http.StatusPermanentRedirect)
-		}),
+	redirectListener, err := cfg.listenTCP(":80")
+	if err != nil {
+		return err
+	}
+	httpsListener, err := cfg.listenTCP(":443")
+	if err != nil {
+		redirectListener.Close()
+		return err
 	}
 
 	go func() {
 		// The redirect server keeps HTTP clients pointed at HTTPS, so we log its lifecycle too.
 		logger.Printf("HTTP redirect server listening on :80")
-		if err := httpRedirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := httpRedirect.Serve(redirectListener); err != nil && err != http.ErrServerClosed {
 			logger.Printf("redirect server stopped: %v", err)
 		}
 	}()
 
 	go func() {
 		<-ctx.Done()
+		drain(srv, cfg.drainDelay, logger)
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		httpRedirect.Shutdown(shutdownCtx)
@@ -184,14 +705,15 @@ func runDomainServers(ctx context.Context, domain string, srv *httpapi.Server, l
 	}()
 
 	logger.Printf("HTTPS server for %s is starting with an ephemeral certificate", domain)
-	if err := httpsServer.ListenAndServeTLS(certFile, keyFile); err != nil && err != http.ErrServerClosed {
+	if err := httpsServer.ServeTLS(httpsListener, certFile, keyFile); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("TLS server stopped unexpectedly: %w", err)
 	}
 	return nil
 }
 
 // generateCertificate produces a temporary certificate so TLS works even before Let's Encrypt provisions.
-func generateCertificate(domain string) (tls.Certificate, string, string, error) {
+// Its validity window starts from clk so tests can check it against a fixed time.
+func generateCertificate(domain string, clk clock.Clock) (tls.Certificate, string, string, error) {
 	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
 		return tls.Certificate{}, "", "", err
@@ -208,8 +730,8 @@ func generateCertificate(domain string) (tls.Certificate, string, string, error)
 		Subject: pkix.Name{
 			CommonName: domain,
 		},
-		NotBefore: time.Now().Add(-time.Hour),
-		NotAfter:  time.Now().Add(90 * 24 * time.Hour),
+		NotBefore: clk.Now().Add(-time.Hour),
+		NotAfter:  clk.Now().Add(90 * 24 * time.Hour),
 		DNSNames:  []string{domain},
 		KeyUsage:  x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsage: []x509.ExtKeyUsage{
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// resolveSources layers the config file and the environment under the command line. The order,
+// lowest first, is: flag defaults, the -config file, BAKERY_* variables, then flags given on the
+// command line, so one run can always override whatever the deployment set. $PORT is the exception
+// and still wins over everything, because platforms that assign it expect the service to bind there.
+func resolveSources(set *flag.FlagSet, cfg *Config) error {
+	explicit := map[string]bool{}
+	set.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	if cfg.configPath == "" {
+		cfg.configPath = os.Getenv(envName("config"))
+	}
+	if cfg.configPath != "" {
+		values, err := readConfigFile(cfg.configPath)
+		if err != nil {
+			return err
+		}
+		if err := applyConfigFile(set, values, explicit); err != nil {
+			return fmt.Errorf("config file %s: %w", cfg.configPath, err)
+		}
+	}
+	// The list flags append on every Set, so a variable has to clear the file's list to replace it.
+	for name, list := range map[string]*[]string{"categories": &cfg.categories, "trusted-proxies": &cfg.trustedProxies} {
+		if _, ok := os.LookupEnv(envName(name)); ok && !explicit[name] {
+			*list = nil
+		}
+	}
+	if err := applyEnv(set, explicit); err != nil {
+		return err
+	}
+	if port := os.Getenv("PORT"); port != "" {
+		parsed, err := strconv.Atoi(port)
+		if err != nil {
+			return fmt.Errorf("invalid $PORT %q: %w", port, err)
+		}
+		cfg.port = parsed
+	}
+	return nil
+}
+
+// envName maps a flag to its variable: db-type becomes BAKERY_DB_TYPE.
+func envName(flagName string) string {
+	return "BAKERY_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnv sets every flag that has a BAKERY_* variable and was not given on the command line.
+// -config is read earlier by resolveSources and -version makes no sense from the environment.
+func applyEnv(set *flag.FlagSet, explicit map[string]bool) error {
+	var err error
+	set.VisitAll(func(f *flag.Flag) {
+		if err != nil || explicit[f.Name] || f.Name == "config" || f.Name == "version" {
+			return
+		}
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			return
+		}
+		if setErr := set.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid $%s %q: %w", envName(f.Name), value, setErr)
+		}
+	})
+	return err
+}
+
+// readConfigFile loads a JSON object whose keys are flag names, such as {"db-type":"sqlite","port":8080}.
+// Numbers are kept as written so large integers and durations survive untouched.
+func readConfigFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var values map[string]any
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("config file %s is not a JSON object: %w", path, err)
+	}
+	return values, nil
+}
+
+// applyConfigFile feeds file values through the flags' own parsers, so a value in the file is checked
+// exactly like the same value on the command line. Keys that name no flag are collected and reported
+// together; a typo would otherwise silently fall back to the default.
+func applyConfigFile(set *flag.FlagSet, values map[string]any, explicit map[string]bool) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var unknown []string
+	for _, name := range names {
+		if name == "config" || set.Lookup(name) == nil {
+			unknown = append(unknown, name)
+			continue
+		}
+		if explicit[name] {
+			continue
+		}
+		value, err := configValue(values[name])
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := set.Set(name, value); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// configValue renders a JSON value the way it would be typed after the flag. Lists are allowed for
+// the comma-separated flags such as categories and trusted-proxies.
+func configValue(raw any) (string, error) {
+	switch value := raw.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case []any:
+		parts := make([]string, 0, len(value))
+		for _, element := range value {
+			part, err := configValue(element)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", errors.New("expected a string, number, boolean or list")
+	}
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a -config file into the test's directory and returns its path.
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bakery.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	file := writeConfig(t, `{"port": 8081, "db-type": "sqlite", "domain": "bakery.example", "categories": ["bread", "pie"]}`)
+	tests := []struct {
+		name           string
+		env            map[string]string
+		args           []string
+		wantPort       int
+		wantDomain     string
+		wantCategories []string
+	}{
+		{
+			name:           "file over defaults",
+			wantPort:       8081,
+			wantDomain:     "bakery.example",
+			wantCategories: []string{"bread", "pie"},
+		},
+		{
+			name:           "environment over file",
+			env:            map[string]string{"BAKERY_PORT": "8082", "BAKERY_CATEGORIES": "cake"},
+			wantPort:       8082,
+			wantDomain:     "bakery.example",
+			wantCategories: []string{"cake"},
+		},
+		{
+			name:           "command line over environment",
+			env:            map[string]string{"BAKERY_PORT": "8082", "BAKERY_DOMAIN": "env.example"},
+			args:           []string{"-port", "8083", "-domain", "shop.example"},
+			wantPort:       8083,
+			wantDomain:     "shop.example",
+			wantCategories: []string{"bread", "pie"},
+		},
+		{
+			name:           "$PORT over everything",
+			env:            map[string]string{"BAKERY_PORT": "8082", "PORT": "9000"},
+			args:           []string{"-port", "8083", "-domain", "shop.example"},
+			wantPort:       9000,
+			wantDomain:     "shop.example",
+			wantCategories: []string{"bread", "pie"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PORT", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			cfg, err := parseFlags(append([]string{"-config", file}, tt.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.port != tt.wantPort || cfg.domain != tt.wantDomain || !slices.Equal(cfg.categories, tt.wantCategories) {
+				t.Errorf("port %d, domain %s, categories %v; want %d, %s, %v",
+					cfg.port, cfg.domain, cfg.categories, tt.wantPort, tt.wantDomain, tt.wantCategories)
+			}
+		})
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"unknown keys are listed together", `{"prot": 8080, "db_type": "sqlite", "port": 8080}`, "unknown keys: db_type, prot"},
+		{"config cannot name another config", `{"config": "other.json"}`, "unknown keys: config"},
+		{"values go through the flag parser", `{"port": "eighty"}`, `invalid port "eighty"`},
+		{"objects are not flag values", `{"categories": {"bread": true}}`, "categories: expected a string"},
+		{"not an object", `[8080]`, "is not a JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PORT", "")
+			_, err := parseFlags([]string{"-config", writeConfig(t, tt.body)})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnvironmentBinding(t *testing.T) {
+	t.Setenv("PORT", "")
+	t.Setenv("BAKERY_DB_TYPE", "duckdb")
+	t.Setenv("BAKERY_DB_PATH", "/var/lib/bakery")
+	t.Setenv("BAKERY_DOMAIN", "bakery.example")
+	t.Setenv("BAKERY_PORT", "8090")
+	t.Setenv("BAKERY_REQUEST_TIMEOUT", "3s")
+	t.Setenv("BAKERY_DEV", "true")
+	t.Setenv("BAKERY_GENERATE_DELIVERIES", "true")
+	t.Setenv("BAKERY_DEPOT_LAT", "55.75")
+	t.Setenv("BAKERY_TRUSTED_PROXIES", "10.0.0.0/8,127.0.0.1")
+
+	cfg, err := parseFlags(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"db type", cfg.dbType, "duckdb"},
+		{"db path", cfg.dbPath, "/var/lib/bakery"},
+		{"domain", cfg.domain, "bakery.example"},
+		{"port", cfg.port, 8090},
+		{"request timeout", cfg.requestTimeout.String(), "3s"},
+		{"dev", cfg.dev, true},
+		{"generate deliveries", cfg.generateDeliveries, true},
+		{"depot latitude", cfg.depotLat, 55.75},
+		{"trusted proxies", strings.Join(cfg.trustedProxies, " "), "10.0.0.0/8 127.0.0.1"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestEnvironmentUnderCommandLine(t *testing.T) {
+	t.Setenv("PORT", "")
+	t.Setenv("BAKERY_DB_TYPE", "duckdb")
+	t.Setenv("BAKERY_PORT", "8090")
+
+	cfg, err := parseFlags([]string{"-db-type", "pgx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.dbType != "pgx" || cfg.port != 8090 {
+		t.Errorf("db type %s, port %d; want pgx from the flag and 8090 from the environment", cfg.dbType, cfg.port)
+	}
+}
+
+func TestEnvironmentErrorsNameTheVariable(t *testing.T) {
+	tests := []struct {
+		key, value string
+		wantErr    string
+	}{
+		{"BAKERY_PORT", "eighty", `invalid $BAKERY_PORT "eighty"`},
+		{"BAKERY_REQUEST_TIMEOUT", "soon", `invalid $BAKERY_REQUEST_TIMEOUT "soon"`},
+		{"BAKERY_DEV", "maybe", `invalid $BAKERY_DEV "maybe"`},
+		{"PORT", "eighty", `invalid $PORT "eighty"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Setenv("PORT", "")
+			t.Setenv(tt.key, tt.value)
+			_, err := parseFlags(nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
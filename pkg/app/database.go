@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"bakery/pkg/storage/memorydriver"
+)
+
+// Backoff between database attempts: doubling from dbRetryFirst and never more than dbRetryMax, so a
+// database that is seconds away gets picked up quickly and one that is minutes away is not hammered.
+const (
+	dbRetryFirst = time.Second
+	dbRetryMax   = 30 * time.Second
+)
+
+// openDatabase opens the registered driver, pings it and ensures the schema, retrying the whole
+// sequence up to cfg.dbRetries more times. Containers often start the bakery before its database
+// accepts connections; waiting here keeps that race from turning into a crash loop. Each attempt
+// gets cfg.dbTimeout, and the error of the last one is returned once the budget is spent.
+func openDatabase(ctx context.Context, driverName string, cfg Config, logger *log.Logger) (*sql.DB, error) {
+	wait := dbRetryFirst
+	for attempt := 0; ; attempt++ {
+		db, err := connectDatabase(ctx, driverName, cfg)
+		if err == nil {
+			if attempt > 0 {
+				logger.Printf("database ready after %d retries", attempt)
+			}
+			return db, nil
+		}
+		if attempt >= cfg.dbRetries {
+			if attempt > 0 {
+				return nil, fmt.Errorf("database unavailable after %d attempts: %w", attempt+1, err)
+			}
+			return nil, err
+		}
+		logger.Printf("database attempt %d of %d failed: %v; retrying in %s", attempt+1, cfg.dbRetries+1, err, wait)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("gave up waiting for the database: %w", ctx.Err())
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, dbRetryMax)
+	}
+}
+
+// connectDatabase is one attempt; a handle that failed halfway is closed so retries do not leak pools.
+func connectDatabase(ctx context.Context, driverName string, cfg Config) (*sql.DB, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, cfg.dbTimeout)
+	defer cancel()
+
+	db, err := sql.Open(driverName, cfg.dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open database: %w", err)
+	}
+	if err := db.PingContext(attemptCtx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to reach database: %w", err)
+	}
+	if err := memorydriver.EnsureSchema(attemptCtx, db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to ensure schema: %w", err)
+	}
+	return db, nil
+}
+
+// readyTables are counted by checkDatabase; every driver, the memory driver included, answers a
+// bare COUNT(*) on them, and a missing table fails the count.
+var readyTables = []string{"orders", "inventory", "product"}
+
+// checkDatabase is the /readyz database check: the pool can reach the database and the tables the
+// services read are there, so a database restored without its schema reads as not ready.
+func checkDatabase(ctx context.Context, db *sql.DB) error {
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("unable to reach database: %w", err)
+	}
+	for _, table := range readyTables {
+		var rows int64
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&rows); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// servePprof exposes the Go profiler on its own listener until ctx ends. It never shares the public
+// port, so binding it to localhost keeps profiles, which reveal a lot about the process, off the
+// internet. It runs on its own mux, so nothing reaches the profiler through the bakery handler.
+func servePprof(ctx context.Context, addr string, logger *log.Logger) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Printf("pprof server stopped: %v", err)
+		}
+	}()
+	logger.Printf("pprof is serving on http://%s/debug/pprof/", listener.Addr())
+	return nil
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bakery/pkg/clock"
+	"bakery/pkg/inventory"
+	"bakery/pkg/order"
+	"bakery/pkg/storage/memorydriver"
+)
+
+// selftestItem names the batch the self-test bakes, so a report line is easy to trace.
+const selftestItem = "Selftest loaf"
+
+// selftestStep is one check of the self-test; run returns why it failed.
+type selftestStep struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// runSelftest boots the real services against a throwaway database in a temporary directory and
+// walks one order and one batch through their lives: bake a batch, order from it, read the order
+// back, cancel it, and delete the batch. Every step prints one report line to out; the first
+// failure stops the run and is returned, so the process exits non-zero. Nothing touches the
+// configured database, so it is safe to run next to a live instance after a deploy.
+func runSelftest(ctx context.Context, cfg Config, out io.Writer) error {
+	dir, err := os.MkdirTemp("", "bakery-selftest-")
+	if err != nil {
+		return fmt.Errorf("selftest: unable to create a scratch directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	quiet := log.New(io.Discard, "", 0)
+	clk := clock.Real{}
+	driverName, _, cleanupDriver, err := memorydriver.Register(cfg.dbType, filepath.Join(dir, "selftest.json"), memorydriver.Options{Clock: clk, Logger: quiet})
+	if err != nil {
+		return fmt.Errorf("selftest: unable to register database driver: %w", err)
+	}
+	defer cleanupDriver()
+	db, err := sql.Open(driverName, filepath.Join(dir, "selftest.json"))
+	if err != nil {
+		return fmt.Errorf("selftest: unable to open database: %w", err)
+	}
+	defer db.Close()
+	if err := memorydriver.EnsureSchema(ctx, db); err != nil {
+		return fmt.Errorf("selftest: unable to ensure schema: %w", err)
+	}
+
+	inventoryService := inventory.NewService(inventory.NewRepository(db, clk), inventory.Options{Clock: clk, Logger: quiet})
+	defer inventoryService.Close()
+	orderService := order.NewService(order.NewRepository(db, clk), order.Options{Stock: inventoryService, Pricer: inventoryService, Clock: clk, Logger: quiet})
+	defer orderService.Close()
+
+	var (
+		batch  inventory.Item
+		placed order.Order
+	)
+	tomorrow := clk.Now().AddDate(0, 0, 1)
+	steps := []selftestStep{
+		{"add an inventory batch", func(ctx context.Context) error {
+			batch, err = inventoryService.Add(ctx, inventory.Item{Name: selftestItem, Category: "bread", AvailableCount: 3, PriceCents: 10000, BakedAt: clk.Now()})
+			return err
+		}},
+		{"submit an order", func(ctx context.Context) error {
+			placed, err = orderService.Submit(ctx, order.Order{
+				CustomerName: "Selftest",
+				Phone:        "+70000000000",
+				Address:      "Selftest street 1",
+				Items:        []order.OrderItem{{Name: selftestItem, Quantity: 1}},
+				BreadSchedule: order.BreadSchedule{
+					Days:      []string{strings.ToLower(tomorrow.Weekday().String())},
+					Frequency: "everyday",
+					StartDate: tomorrow.Format("2006-01-02"),
+				},
+				CroissantSchedule: []order.CroissantSchedule{{Day: strings.ToLower(tomorrow.Weekday().String()), Quantity: 1}},
+			})
+			return err
+		}},
+		{"list the order back", func(ctx context.Context) error {
+			orders, err := orderService.List(ctx)
+			if err != nil {
+				return err
+			}
+			for _, o := range orders {
+				if o.ID == placed.ID {
+					return nil
+				}
+			}
+			return fmt.Errorf("order %d is missing from %d listed orders", placed.ID, len(orders))
+		}},
+		{"reserve stock for the order", func(ctx context.Context) error {
+			return expectReserved(ctx, inventoryService, batch.ID, 1)
+		}},
+		{"cancel the order", func(ctx context.Context) error {
+			if _, err := orderService.SetStatus(ctx, placed.ID, order.StatusCancelled); err != nil {
+				return err
+			}
+			return expectReserved(ctx, inventoryService, batch.ID, 0)
+		}},
+		{"delete the inventory batch", func(ctx context.Context) error {
+			if err := inventoryService.Delete(ctx, batch.ID); err != nil {
+				return err
+			}
+			items, err := inventoryService.List(ctx)
+			if err != nil {
+				return err
+			}
+			for _, item := range items {
+				if item.ID == batch.ID {
+					return fmt.Errorf("batch %d is still listed", batch.ID)
+				}
+			}
+			return nil
+		}},
+	}
+
+	started := time.Now()
+	for _, step := range steps {
+		stepCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		stepStarted := time.Now()
+		err := step.run(stepCtx)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(out, "selftest: %-28s FAIL  %v\n", step.name, err)
+			return fmt.Errorf("selftest failed at %q: %w", step.name, err)
+		}
+		fmt.Fprintf(out, "selftest: %-28s ok    %s\n", step.name, time.Since(stepStarted).Round(time.Microsecond))
+	}
+	fmt.Fprintf(out, "selftest: all %d steps passed in %s\n", len(steps), time.Since(started).Round(time.Microsecond))
+	return nil
+}
+
+// expectReserved checks how many units of a batch are held for pending orders.
+func expectReserved(ctx context.Context, inventoryService *inventory.Service, id int64, want int) error {
+	items, err := inventoryService.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.ID == id {
+			if item.ReservedCount != want {
+				return fmt.Errorf("batch %d has %d units reserved, want %d", id, item.ReservedCount, want)
+			}
+			return nil
+		}
+	}
+	return errors.New("the batch disappeared")
+}
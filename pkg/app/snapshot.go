@@ -0,0 +1,28 @@
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"bakery/pkg/storage/memorydriver"
+)
+
+// validateSnapshot reports what is wrong with the -validate-snapshot file without starting anything,
+// so an operator can tell a damaged snapshot from a damaged deploy before restoring a backup.
+func validateSnapshot(cfg Config, out io.Writer) error {
+	issues, err := memorydriver.ValidateSnapshot(cfg.validateSnap, int64(cfg.snapshotMaxMB)<<20)
+	if err != nil {
+		return fmt.Errorf("unable to validate snapshot: %w", err)
+	}
+	if len(issues) == 0 {
+		fmt.Fprintf(out, "snapshot %s: no issues found\n", cfg.validateSnap)
+		return nil
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(out, "snapshot %s: %s\n", cfg.validateSnap, issue)
+	}
+	if len(issues) == 1 {
+		return fmt.Errorf("snapshot %s has 1 issue", cfg.validateSnap)
+	}
+	return fmt.Errorf("snapshot %s has %d issues", cfg.validateSnap, len(issues))
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// tlsVersions maps -tls-min-version values to their protocol constants. Older versions are left
+// out on purpose: TLS 1.0 and 1.1 are deprecated and every scanner flags them.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsCipherSuites are the TLS 1.2 suites offered: forward-secret AEAD only, ECDSA first because the
+// generated certificate is ECDSA, RSA kept for certificates brought from elsewhere. TLS 1.3 picks
+// its own suites, which Go does not let servers configure and which are all fine.
+var tlsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// parseTLSVersion validates -tls-min-version.
+func parseTLSVersion(value string) (uint16, error) {
+	version, ok := tlsVersions[value]
+	if !ok {
+		return 0, fmt.Errorf("invalid -tls-min-version %q: use 1.2 or 1.3", value)
+	}
+	return version, nil
+}
+
+// tlsConfig is the one TLS setup every HTTPS listener uses, so the self-signed path and any
+// certificate source added later pass the same security scan. HTTP/2 is announced explicitly
+// because setting TLSConfig on a server is otherwise easy to get wrong for h2.
+func tlsConfig(minVersion uint16, certs ...tls.Certificate) *tls.Config {
+	return &tls.Config{
+		Certificates:     certs,
+		MinVersion:       minVersion,
+		CipherSuites:     tlsCipherSuites,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		NextProtos:       []string{"h2", "http/1.1"},
+	}
+}
@@ -0,0 +1,106 @@
+// Package breaker stops a service from calling a storage backend that keeps failing, so requests
+// fail fast while it recovers instead of each one waiting out its own timeout.
+package breaker
+
+import (
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"bakery/pkg/clock"
+)
+
+// ErrUnavailable is returned while the breaker is open, without the backend being called.
+var ErrUnavailable = errors.New("storage is unavailable, try again shortly")
+
+// DefaultCooldown is how long an open breaker fails fast before letting one call through to test
+// the backend again.
+const DefaultCooldown = 30 * time.Second
+
+// Options tunes a breaker; the zero value turns it off.
+type Options struct {
+	// Threshold is how many failures in a row open the breaker; zero or less never opens it.
+	Threshold int
+	// Cooldown is how long the breaker stays open; it defaults to DefaultCooldown.
+	Cooldown time.Duration
+}
+
+// Breaker counts failures in a row and, past the threshold, refuses calls for a cooldown. After
+// the cooldown it is half-open: the next call goes through as a trial, and its outcome either
+// closes the breaker again or restarts the cooldown.
+//
+// A Breaker belongs to one service goroutine, which calls Allow and Record around its own storage
+// work, so it needs no locking; do not share one between goroutines. A nil *Breaker allows
+// everything, so services can call it unconditionally.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	clock     clock.Clock
+	logger    *log.Logger
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// New returns a breaker for opts, or nil when opts.Threshold turns it off. name labels its log
+// lines, such as "order storage"; clk times the cooldown and may be nil for the system clock, and a
+// nil logger discards the lines.
+func New(name string, opts Options, clk clock.Clock, logger *log.Logger) *Breaker {
+	if opts.Threshold <= 0 {
+		return nil
+	}
+	cooldown := opts.Cooldown
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	return &Breaker{
+		name:      name,
+		threshold: opts.Threshold,
+		cooldown:  cooldown,
+		clock:     clock.OrReal(clk),
+		logger:    logger,
+	}
+}
+
+// Allow reports whether the backend may be called: nil when closed or when the cooldown is over and
+// this call is the trial, ErrUnavailable while open.
+func (b *Breaker) Allow() error {
+	if b == nil || b.openUntil.IsZero() {
+		return nil
+	}
+	if b.clock.Now().Before(b.openUntil) {
+		return ErrUnavailable
+	}
+	b.trial = true
+	return nil
+}
+
+// Record notes the outcome of a call Allow let through. failed should be true only for storage
+// failures; a request the backend answered, even with "not found", shows it is healthy.
+func (b *Breaker) Record(failed bool) {
+	if b == nil {
+		return
+	}
+	if !failed {
+		if !b.openUntil.IsZero() {
+			b.logger.Printf("%s recovered; circuit closed", b.name)
+		}
+		b.failures, b.openUntil, b.trial = 0, time.Time{}, false
+		return
+	}
+	b.failures++
+	if b.trial || b.failures >= b.threshold {
+		if b.openUntil.IsZero() {
+			b.logger.Printf("%s failed %d times in a row; failing fast for %s", b.name, b.failures, b.cooldown)
+		} else {
+			b.logger.Printf("%s still failing; failing fast for another %s", b.name, b.cooldown)
+		}
+		b.openUntil = b.clock.Now().Add(b.cooldown)
+		b.trial = false
+	}
+}
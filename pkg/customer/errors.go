@@ -0,0 +1,6 @@
+package customer
+
+import "errors"
+
+// ErrNotFound is returned when no customer has the phone so HTTP handlers can respond with 404.
+var ErrNotFound = errors.New("customer not found")
@@ -0,0 +1,16 @@
+package customer
+
+import "time"
+
+// Customer is what the bakery remembers about a returning household, keyed by phone so regulars
+// are recognized without accounts. Name, Address and Email follow the latest order that gave them.
+// LastOrderAt is when the customer last ordered, which is how listings rank regulars.
+type Customer struct {
+	ID          int64     `json:"id"`
+	Phone       string    `json:"phone"`
+	Name        string    `json:"name"`
+	Address     string    `json:"address"`
+	Email       string    `json:"email,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastOrderAt time.Time `json:"last_order_at"`
+}
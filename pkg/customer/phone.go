@@ -0,0 +1,29 @@
+package customer
+
+import "strings"
+
+// NormalizePhone reduces a phone number to the one form customers are keyed by, so "+7 (900) 123-45-67",
+// "8 900 123 45 67" and "9001234567" all name the same household. Formatting is dropped, and Russian
+// numbers written with the trunk prefix 8 or without a country code become +7. Anything else keeps
+// its digits, with the + when one was typed.
+func NormalizePhone(raw string) string {
+	raw = strings.TrimSpace(raw)
+	var digits strings.Builder
+	for _, r := range raw {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	number := digits.String()
+	switch {
+	case number == "":
+		return ""
+	case len(number) == 11 && (number[0] == '8' || number[0] == '7'):
+		return "+7" + number[1:]
+	case len(number) == 10 && number[0] == '9':
+		return "+7" + number
+	case strings.HasPrefix(raw, "+"):
+		return "+" + number
+	}
+	return number
+}
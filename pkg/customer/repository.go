@@ -0,0 +1,115 @@
+package customer
+
+import (
+	"context"
+	"database/sql"
+
+	"bakery/pkg/clock"
+)
+
+// customerColumns is the projection every customer listing selects, in the order scanCustomers reads it.
+const customerColumns = "id, phone, name, address, email, created_at, last_order_at"
+
+// Repository persists customers through database/sql so storage backends stay swappable.
+type Repository struct {
+	db    *sql.DB
+	clock clock.Clock
+}
+
+// NewRepository wires the handle so the service goroutine can own all customer access.
+// clk stamps CreatedAt and LastOrderAt; nil uses the system clock.
+func NewRepository(db *sql.DB, clk clock.Clock) *Repository {
+	return &Repository{db: db, clock: clock.OrReal(clk)}
+}
+
+// FindByPhone returns the customer with the normalized phone, or ErrNotFound.
+func (r *Repository) FindByPhone(ctx context.Context, phone string) (Customer, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+customerColumns+" FROM customers WHERE phone = ?", phone)
+	if err != nil {
+		return Customer{}, err
+	}
+	customers, err := scanCustomers(rows)
+	if err != nil {
+		return Customer{}, err
+	}
+	if len(customers) == 0 {
+		return Customer{}, ErrNotFound
+	}
+	return customers[0], nil
+}
+
+// List returns every customer, the most recent orderers first.
+func (r *Repository) List(ctx context.Context) ([]Customer, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+customerColumns+" FROM customers ORDER BY last_order_at DESC")
+	if err != nil {
+		return nil, err
+	}
+	return scanCustomers(rows)
+}
+
+// Save inserts a new customer and returns it with its generated identifier.
+func (r *Repository) Save(ctx context.Context, c Customer) (Customer, error) {
+	now := r.clock.Now().UTC()
+	c.CreatedAt, c.LastOrderAt = now, now
+	query := "INSERT INTO customers (phone, name, address, email, created_at, last_order_at) VALUES (?, ?, ?, ?, ?, ?)"
+	result, err := r.db.ExecContext(ctx, query, c.Phone, c.Name, c.Address, c.Email, c.CreatedAt, c.LastOrderAt)
+	if err != nil {
+		return Customer{}, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return Customer{}, err
+	}
+	c.ID = id
+	return c, nil
+}
+
+// Touch refreshes the contact details of a returning customer and marks them as having just ordered.
+func (r *Repository) Touch(ctx context.Context, c Customer) (Customer, error) {
+	c.LastOrderAt = r.clock.Now().UTC()
+	query := "UPDATE customers SET name = ?, address = ?, email = ?, last_order_at = ? WHERE id = ?"
+	result, err := r.db.ExecContext(ctx, query, c.Name, c.Address, c.Email, c.LastOrderAt, c.ID)
+	if err != nil {
+		return Customer{}, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return Customer{}, err
+	}
+	if rows == 0 {
+		return Customer{}, ErrNotFound
+	}
+	return c, nil
+}
+
+// scanCustomers decodes the shared customer projection; NULL text reads as empty, like the other repositories.
+func scanCustomers(rows *sql.Rows) ([]Customer, error) {
+	defer rows.Close()
+
+	var customers []Customer
+	for rows.Next() {
+		var (
+			c                           Customer
+			phone, name, address, email sql.NullString
+			createdAt, lastOrderAt      sql.NullTime
+		)
+		if err := rows.Scan(&c.ID, &phone, &name, &address, &email, &createdAt, &lastOrderAt); err != nil {
+			return nil, err
+		}
+		c.Phone = phone.String
+		c.Name = name.String
+		c.Address = address.String
+		c.Email = email.String
+		if createdAt.Valid {
+			c.CreatedAt = createdAt.Time.UTC()
+		}
+		if lastOrderAt.Valid {
+			c.LastOrderAt = lastOrderAt.Time.UTC()
+		}
+		customers = append(customers, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
@@ -0,0 +1,89 @@
+package customer
+
+import (
+	"context"
+	"strings"
+)
+
+// Search bounds: an admin picks from a short list while on the phone, so more would only scroll.
+const (
+	DefaultSearchLimit = 10
+	MaxSearchLimit     = 50
+)
+
+// Search returns up to limit customers whose phone or name matches q, the most recent orderers first.
+// A query with digits is compared against phones the way NormalizePhone keys them, so "8 900 12"
+// and "+7900 12" both find +7900123…; the name is matched case-insensitively as a substring.
+// A limit outside 1..MaxSearchLimit uses DefaultSearchLimit.
+func (s *Service) Search(ctx context.Context, q string, limit int) ([]Customer, error) {
+	if limit <= 0 || limit > MaxSearchLimit {
+		limit = DefaultSearchLimit
+	}
+	// List already ranks by last_order_at, so the first matches are the ones worth showing.
+	all, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matcher := newMatcher(q)
+	found := []Customer{}
+	for _, c := range all {
+		if len(found) == limit {
+			break
+		}
+		if matcher.matches(c) {
+			found = append(found, c)
+		}
+	}
+	return found, nil
+}
+
+// matcher holds a search query prepared once for comparing against every customer.
+type matcher struct {
+	name string
+	// digits is the query's digits; national is what they mean after a leading 8, 7 or +7, which
+	// NormalizePhone turns into +7, so a typed prefix lines up with stored numbers.
+	digits   string
+	national string
+}
+
+// newMatcher prepares q. A query shorter than a full number cannot go through NormalizePhone whole,
+// so only its prefix is interpreted the same way.
+func newMatcher(q string) matcher {
+	q = strings.TrimSpace(q)
+	m := matcher{name: strings.ToLower(q)}
+	var digits strings.Builder
+	for _, r := range q {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	m.digits = digits.String()
+	if m.digits == "" {
+		return m
+	}
+	if len(m.digits) >= 10 {
+		// A complete number normalizes exactly like the stored ones.
+		m.national = strings.TrimPrefix(NormalizePhone(q), "+7")
+		return m
+	}
+	if m.digits[0] == '8' || m.digits[0] == '7' {
+		m.national = m.digits[1:]
+	}
+	return m
+}
+
+// matches reports whether the customer's name or phone fits the query.
+func (m matcher) matches(c Customer) bool {
+	if m.name != "" && strings.Contains(strings.ToLower(c.Name), m.name) {
+		return true
+	}
+	if m.digits == "" {
+		return false
+	}
+	if m.national != "" {
+		if national, ok := strings.CutPrefix(c.Phone, "+7"); ok && strings.HasPrefix(national, m.national) {
+			return true
+		}
+	}
+	return strings.Contains(strings.TrimPrefix(c.Phone, "+"), m.digits)
+}
@@ -0,0 +1,183 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// rememberCommand upserts the customer behind an order.
+type rememberCommand struct {
+	ctx      context.Context
+	customer Customer
+	reply    chan commandResult
+}
+
+// findQuery looks one customer up by normalized phone.
+type findQuery struct {
+	ctx   context.Context
+	phone string
+	reply chan commandResult
+}
+
+// listQuery enables consumers to fetch every customer without touching shared memory.
+type listQuery struct {
+	ctx   context.Context
+	reply chan queryResult
+}
+
+// commandResult forwards either the stored customer or an error back to the caller.
+type commandResult struct {
+	customer Customer
+	err      error
+}
+
+// queryResult returns the customers for an admin listing.
+type queryResult struct {
+	customers []Customer
+	err       error
+}
+
+// Service owns a goroutine so the find-then-write of an upsert never races another order from the
+// same phone, like the other services serialize their writes.
+type Service struct {
+	repo      *Repository
+	remembers chan rememberCommand
+	finds     chan findQuery
+	listCalls chan listQuery
+	quit      chan struct{}
+}
+
+// NewService starts the background goroutine immediately so callers only see non-blocking calls.
+func NewService(repo *Repository) *Service {
+	svc := &Service{
+		repo:      repo,
+		remembers: make(chan rememberCommand),
+		finds:     make(chan findQuery),
+		listCalls: make(chan listQuery),
+		quit:      make(chan struct{}),
+	}
+	go svc.loop()
+	return svc
+}
+
+// loop processes commands and queries sequentially so no mutexes are needed.
+func (s *Service) loop() {
+	for {
+		select {
+		case cmd := <-s.remembers:
+			stored, err := s.upsert(cmd.ctx, cmd.customer)
+			cmd.reply <- commandResult{customer: stored, err: err}
+		case q := <-s.finds:
+			found, err := s.repo.FindByPhone(q.ctx, q.phone)
+			q.reply <- commandResult{customer: found, err: err}
+		case q := <-s.listCalls:
+			customers, err := s.repo.List(q.ctx)
+			q.reply <- queryResult{customers: customers, err: err}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// upsert refreshes the customer with the phone or creates one. An order without an email keeps the
+// address on file, since most orders never give one.
+func (s *Service) upsert(ctx context.Context, c Customer) (Customer, error) {
+	existing, err := s.repo.FindByPhone(ctx, c.Phone)
+	if errors.Is(err, ErrNotFound) {
+		return s.repo.Save(ctx, c)
+	}
+	if err != nil {
+		return Customer{}, err
+	}
+	existing.Name = c.Name
+	existing.Address = c.Address
+	if c.Email != "" {
+		existing.Email = c.Email
+	}
+	return s.repo.Touch(ctx, existing)
+}
+
+// Remember records the customer behind an order and returns their id so the order can be linked.
+// It satisfies order.Customers.
+func (s *Service) Remember(ctx context.Context, name, phone, address, email string) (int64, error) {
+	key := NormalizePhone(phone)
+	if key == "" {
+		return 0, errors.New("customer phone has no digits")
+	}
+	c := Customer{
+		Phone:   key,
+		Name:    strings.TrimSpace(name),
+		Address: strings.TrimSpace(address),
+		Email:   strings.TrimSpace(email),
+	}
+	reply := make(chan commandResult, 1)
+	cmd := rememberCommand{ctx: ctx, customer: c, reply: reply}
+
+	select {
+	case s.remembers <- cmd:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return 0, errors.New("customer queue is busy")
+	}
+
+	select {
+	case res := <-reply:
+		return res.customer.ID, res.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// Find returns the customer with the phone, written any way NormalizePhone accepts, or ErrNotFound.
+func (s *Service) Find(ctx context.Context, phone string) (Customer, error) {
+	key := NormalizePhone(phone)
+	if key == "" {
+		return Customer{}, ErrNotFound
+	}
+	reply := make(chan commandResult, 1)
+	q := findQuery{ctx: ctx, phone: key, reply: reply}
+
+	select {
+	case s.finds <- q:
+	case <-ctx.Done():
+		return Customer{}, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return Customer{}, errors.New("customer queue is busy")
+	}
+
+	select {
+	case res := <-reply:
+		return res.customer, res.err
+	case <-ctx.Done():
+		return Customer{}, ctx.Err()
+	}
+}
+
+// List returns every customer, the most recent orderers first.
+func (s *Service) List(ctx context.Context) ([]Customer, error) {
+	reply := make(chan queryResult, 1)
+	q := listQuery{ctx: ctx, reply: reply}
+
+	select {
+	case s.listCalls <- q:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return nil, errors.New("customer queue is busy")
+	}
+
+	select {
+	case res := <-reply:
+		return res.customers, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops the background goroutine when the application shuts down.
+func (s *Service) Close() {
+	close(s.quit)
+}
@@ -0,0 +1,145 @@
+// Package events carries domain events from the services that cause them to whoever records or
+// reacts to them, so emitting an event never waits on how it is stored.
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// inboxSize lets publishers run ahead of slow subscribers for a while before Publish waits.
+const inboxSize = 1024
+
+// Event is one thing that happened to one entity. Topic names the kind of entity, such as "order",
+// and SubjectID which one; Type is what happened to it and Detail an optional human-readable note.
+type Event struct {
+	At        time.Time `json:"at"`
+	Topic     string    `json:"topic"`
+	SubjectID int64     `json:"subject_id"`
+	Type      string    `json:"type"`
+	Actor     string    `json:"actor,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// subscription is a subscriber's channel and the topic it wants; an empty topic wants everything.
+type subscription struct {
+	topic string
+	out   chan Event
+}
+
+// Bus fans published events out to subscribers from one goroutine, in the order they were published.
+type Bus struct {
+	inbox      chan Event
+	subscribes chan subscription
+	quit       chan struct{}
+	done       chan struct{}
+}
+
+// NewBus starts the fan-out goroutine.
+func NewBus() *Bus {
+	b := &Bus{
+		inbox:      make(chan Event, inboxSize),
+		subscribes: make(chan subscription),
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go b.loop()
+	return b
+}
+
+// loop delivers every event to every matching subscriber. A subscriber that stops reading holds the
+// bus up rather than losing events, which suits recorders such as the order timeline.
+func (b *Bus) loop() {
+	defer close(b.done)
+	var subscribers []subscription
+	defer func() {
+		for _, sub := range subscribers {
+			close(sub.out)
+		}
+	}()
+	for {
+		select {
+		case sub := <-b.subscribes:
+			subscribers = append(subscribers, sub)
+		case event := <-b.inbox:
+			for _, sub := range subscribers {
+				if sub.topic != "" && sub.topic != event.Topic {
+					continue
+				}
+				select {
+				case sub.out <- event:
+				case <-b.quit:
+					return
+				}
+			}
+		case <-b.quit:
+			b.drain(subscribers)
+			return
+		}
+	}
+}
+
+// drain hands the events still queued at shutdown to subscribers with room for them, so a timeline
+// records the last changes before exit; a subscriber without room misses them.
+func (b *Bus) drain(subscribers []subscription) {
+	for {
+		select {
+		case event := <-b.inbox:
+			for _, sub := range subscribers {
+				if sub.topic != "" && sub.topic != event.Topic {
+					continue
+				}
+				select {
+				case sub.out <- event:
+				default:
+				}
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Publish hands an event to the bus and returns once it is queued. A nil bus drops it, so services
+// can publish unconditionally whether or not anything listens.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	select {
+	case b.inbox <- event:
+	case <-b.quit:
+	}
+}
+
+// Subscribe returns a channel receiving the events of one topic, or of all topics when topic is
+// empty. The channel is closed when the bus closes.
+func (b *Bus) Subscribe(topic string) <-chan Event {
+	out := make(chan Event, inboxSize)
+	select {
+	case b.subscribes <- subscription{topic: topic, out: out}:
+	case <-b.quit:
+		close(out)
+	}
+	return out
+}
+
+// Close stops the bus and closes every subscriber channel.
+func (b *Bus) Close() {
+	close(b.quit)
+	<-b.done
+}
+
+// actorKey carries the actor through a request's context into the services.
+type actorKey struct{}
+
+// WithActor tags whatever is done under ctx with who asked for it, such as a client address.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor WithActor stored, or "" when the work was not started by a request.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -0,0 +1,49 @@
+package httpapi
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// adminPrefix is where every admin API endpoint lives; the admin page itself is only markup and
+// stays public.
+const adminPrefix = "/api/admin/"
+
+// tokenOnlyRoutes hand out or replace the whole dataset, customers' phones and addresses included.
+// Without -admin-token they are not registered at all, so an instance nobody has locked down cannot
+// be copied or overwritten by whoever finds its address.
+var tokenOnlyRoutes = []string{"/api/admin/backup", "/api/admin/restore"}
+
+// guardAdmin puts an admin route behind requireAdmin when a token is configured. Without one the
+// admin API stays open as before, except that ok is false for the token-only routes and Handler
+// leaves them out, so they answer 404 like any unknown path.
+func (s *Server) guardAdmin(rt route) (guarded route, ok bool) {
+	if !strings.HasPrefix(rt.pattern, adminPrefix) {
+		return rt, true
+	}
+	if s.adminToken == "" {
+		return rt, !slices.Contains(tokenOnlyRoutes, rt.pattern)
+	}
+	rt.handler = s.requireAdmin(rt.handler)
+	return rt, true
+}
+
+// requireAdmin serves the request only when it carries "Authorization: Bearer <token>" with the
+// -admin-token value; anything else is 401. The comparison takes the same time however much of the
+// header matches, so the token cannot be guessed byte by byte. It wraps the route's handler rather
+// than the route, so OPTIONS preflights, which browsers send without credentials, still get their
+// Allow header.
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	want := []byte("Bearer " + s.adminToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			s.logger.Printf("admin request %s %s from %s refused: missing or wrong token", r.Method, r.URL.Path, s.clientIP(r))
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bakery admin"`)
+			s.respondError(w, s.text(r, "admin token required"), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,55 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGuardAdmin(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	tests := []struct {
+		name       string
+		token      string
+		pattern    string
+		header     string
+		wantServed bool
+		wantStatus int
+	}{
+		{"public route without a token set", "", "/api/menu", "", true, http.StatusNoContent},
+		{"admin route without a token set", "", "/api/admin/inventory", "", true, http.StatusNoContent},
+		{"backup without a token set", "", "/api/admin/backup", "", false, 0},
+		{"restore without a token set", "", "/api/admin/restore", "", false, 0},
+		{"public route with a token set", "s3cret", "/api/menu", "", true, http.StatusNoContent},
+		{"admin route with the token", "s3cret", "/api/admin/inventory", "Bearer s3cret", true, http.StatusNoContent},
+		{"backup with the token", "s3cret", "/api/admin/backup", "Bearer s3cret", true, http.StatusNoContent},
+		{"admin route without the header", "s3cret", "/api/admin/inventory", "", true, http.StatusUnauthorized},
+		{"restore with a wrong token", "s3cret", "/api/admin/restore", "Bearer s3cre", true, http.StatusUnauthorized},
+		{"token without the scheme", "s3cret", "/api/admin/backup", "s3cret", true, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := pageServer(t)
+			srv.adminToken = tt.token
+			rt, served := srv.guardAdmin(route{pattern: tt.pattern, methods: []string{http.MethodGet}, handler: ok})
+			if served != tt.wantServed {
+				t.Fatalf("served = %v, want %v", served, tt.wantServed)
+			}
+			if !served {
+				return
+			}
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.pattern, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rt.handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"bakery/pkg/money"
+)
+
+// DefaultAdminRefresh is the -admin-refresh default for how often the admin page reloads inventory.
+const DefaultAdminRefresh = 30 * time.Second
+
+// adminConfig tells the admin page how to behave, so cadence and feature toggles follow the server
+// instead of constants baked into the script. The page gets it inline at render time and can
+// re-read it from /api/admin/config.
+type adminConfig struct {
+	// RefreshSeconds is the inventory polling interval; zero means the page only reloads on demand.
+	RefreshSeconds int `json:"refresh_seconds"`
+	// Currency is the ISO 4217 code of every price, and CurrencySymbol the sign money.Money prints.
+	Currency       string        `json:"currency"`
+	CurrencySymbol string        `json:"currency_symbol"`
+	Features       adminFeatures `json:"features"`
+}
+
+// adminFeatures lists what this server can do, so the page hides controls that would only fail.
+type adminFeatures struct {
+	// SSE reports a server-sent event stream; none exists yet, so the page polls.
+	SSE         bool `json:"sse"`
+	ImageUpload bool `json:"image_upload"`
+	ReadOnly    bool `json:"read_only"`
+	Maintenance bool `json:"maintenance"`
+}
+
+// adminConfig assembles the current settings; maintenance is read live because it can be toggled.
+func (s *Server) adminConfig() adminConfig {
+	return adminConfig{
+		RefreshSeconds: int(s.adminRefresh / time.Second),
+		Currency:       s.currency,
+		CurrencySymbol: money.Symbol(s.currency),
+		Features: adminFeatures{
+			SSE:         false,
+			ImageUpload: s.imageDir != "",
+			ReadOnly:    s.readOnly,
+			Maintenance: s.Maintenance(),
+		},
+	}
+}
+
+// adminConfigEndpoint serves the admin page settings as JSON.
+func (s *Server) adminConfigEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.adminConfig())
+	})
+}
@@ -0,0 +1,367 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bakery/pkg/inventory"
+	"bakery/pkg/order"
+	"bakery/pkg/product"
+)
+
+// maxRestoreBytes caps restore uploads so a wrong file cannot exhaust memory.
+const maxRestoreBytes = 64 << 20
+
+// backupDocument mirrors the memory driver snapshot keys, with order schedules kept as nested JSON.
+type backupDocument struct {
+	Orders    []backupOrder     `json:"orders"`
+	Inventory []inventory.Item  `json:"inventory"`
+	Products  []product.Product `json:"products"`
+}
+
+// backupOrder uses the snapshot's column names so backups read the same as bakery.db files.
+type backupOrder struct {
+	ID                int64                     `json:"id"`
+	Name              string                    `json:"name"`
+	Address           string                    `json:"address"`
+	Phone             string                    `json:"phone"`
+	Email             string                    `json:"email,omitempty"`
+	Items             []order.OrderItem         `json:"items"`
+	BreadSchedule     order.BreadSchedule       `json:"bread_schedule"`
+	CroissantSchedule []order.CroissantSchedule `json:"croissant_schedule"`
+	Comment           string                    `json:"comment"`
+	Status            string                    `json:"status,omitempty"`
+	// Reservations maps the backup's batch ids to the units a pending order holds in them.
+	Reservations map[int64]int `json:"reservations,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// newBackupOrder converts a stored order into its backup representation.
+func newBackupOrder(o order.Order) backupOrder {
+	return backupOrder{
+		ID:                o.ID,
+		Name:              o.CustomerName,
+		Address:           o.Address,
+		Phone:             o.Phone,
+		Email:             o.Email,
+		Items:             o.Items,
+		BreadSchedule:     o.BreadSchedule,
+		CroissantSchedule: o.CroissantSchedule,
+		Comment:           o.Comment,
+		Status:            o.Status,
+		Reservations:      o.Reservations,
+		CreatedAt:         o.CreatedAt,
+	}
+}
+
+// order converts the backup record back into the domain type for validation and import. A pause
+// that ended before now is dropped: it no longer holds back any delivery, and the order service
+// refuses pause end dates that are not in the future.
+func (b backupOrder) order(now time.Time) order.Order {
+	schedule := b.BreadSchedule
+	if until, err := time.Parse(deliveryDateLayout, schedule.PausedUntil); err == nil && !until.After(now.UTC()) {
+		schedule.Paused, schedule.PausedUntil = false, ""
+	}
+	return order.Order{
+		ID:                b.ID,
+		CustomerName:      b.Name,
+		Address:           b.Address,
+		Phone:             b.Phone,
+		Email:             b.Email,
+		Items:             b.Items,
+		BreadSchedule:     schedule,
+		CroissantSchedule: b.CroissantSchedule,
+		Comment:           b.Comment,
+		Status:            b.Status,
+		Reservations:      b.Reservations,
+		CreatedAt:         b.CreatedAt,
+	}
+}
+
+// pending reports whether the order still holds its reservations; orders stored before statuses
+// existed read as pending.
+func (b backupOrder) pending() bool {
+	return b.Status == "" || b.Status == order.StatusPending
+}
+
+// backupEndpoint exports the whole dataset through the services so it works with any backend.
+func (s *Server) backupEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			s.methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		orders, err := s.orders.List(ctx)
+		if err != nil {
+			s.logger.Printf("backup failed: unable to list orders: %v", err)
+			s.respondServiceError(w, err)
+			return
+		}
+		items, err := s.inventory.List(ctx)
+		if err != nil {
+			s.logger.Printf("backup failed: unable to list inventory: %v", err)
+			s.respondServiceError(w, err)
+			return
+		}
+		products, err := s.products.List(ctx)
+		if err != nil {
+			s.logger.Printf("backup failed: unable to list products: %v", err)
+			s.respondServiceError(w, err)
+			return
+		}
+
+		doc := backupDocument{
+			Orders:    make([]backupOrder, 0, len(orders)),
+			Inventory: items,
+			Products:  products,
+		}
+		for _, o := range orders {
+			doc.Orders = append(doc.Orders, newBackupOrder(o))
+		}
+		if doc.Inventory == nil {
+			doc.Inventory = []inventory.Item{}
+		}
+		if doc.Products == nil {
+			doc.Products = []product.Product{}
+		}
+
+		filename := fmt.Sprintf("bakery-backup-%s.json", s.clock.Now().UTC().Format("20060102-150405"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		if err := json.NewEncoder(w).Encode(doc); err != nil {
+			s.logger.Printf("backup stream to %s failed: %v", s.clientIP(r), err)
+			return
+		}
+		s.logger.Printf("backup exported with %d orders, %d batches, %d products to %s", len(doc.Orders), len(doc.Inventory), len(doc.Products), s.clientIP(r))
+	})
+}
+
+// restoreEndpoint imports a backup document after validating every record; nothing is written when
+// any record fails validation. The document is checked against itself, not against what the
+// instance already stores: orders are restored as they were rather than submitted again, so they
+// neither reserve stock nor meet the menu, minimum or duplicate checks, and their reservations must
+// name batches of the same document. Imported records receive fresh identifiers; batches are
+// re-linked to their imported products and reservations to their imported batches. A storage
+// failure midway still leaves the records before it imported, which restoreFailed reports. With
+// ?dry_run=true it only validates and reports, so ?confirm=true is not needed.
+func (s *Server) restoreEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
+		if r.Method != http.MethodPost {
+			s.methodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+		dryRun := r.URL.Query().Get("dry_run") == "true"
+		if !dryRun && r.URL.Query().Get("confirm") != "true" {
+			s.logger.Printf("restore rejected: missing confirmation from %s", s.clientIP(r))
+			s.respondValidation(w, r, fieldError{Field: "confirm", Code: codeRequired, Message: "confirm=true is required to restore"})
+			return
+		}
+
+		if s.requireJSON(w, r) {
+			return
+		}
+		var doc backupDocument
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRestoreBytes)).Decode(&doc); err != nil {
+			s.logger.Printf("restore failed: unable to decode document: %v", err)
+			s.respondValidation(w, r, fieldError{Code: codeInvalidJSON, Message: "invalid JSON"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		report := restoreReport{
+			DryRun: dryRun,
+			Counts: map[string]int{
+				"orders":    len(doc.Orders),
+				"inventory": len(doc.Inventory),
+				"products":  len(doc.Products),
+			},
+		}
+		problems := s.validateBackup(doc)
+		if dryRun {
+			// A dry run always answers 200 with the full report so operators can review every problem at once.
+			for i := range problems {
+				problems[i].Message = s.text(r, problems[i].Message)
+			}
+			report.Valid = len(problems) == 0
+			report.Errors = problems
+			s.logger.Printf("restore dry run from %s found %d problems", s.clientIP(r), len(problems))
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(report)
+			return
+		}
+		if len(problems) > 0 {
+			s.logger.Printf("restore rejected: %d invalid records", len(problems))
+			s.respondValidation(w, r, problems...)
+			return
+		}
+
+		// Identifiers are reassigned by the store's counters, so the report maps each backup id to its new one.
+		report.IDMap = map[string]map[int64]int64{
+			"orders":    make(map[int64]int64, len(doc.Orders)),
+			"inventory": make(map[int64]int64, len(doc.Inventory)),
+			"products":  make(map[int64]int64, len(doc.Products)),
+		}
+		for _, p := range doc.Products {
+			oldID := p.ID
+			stored, err := s.products.Add(ctx, p)
+			if err != nil {
+				s.restoreFailed(w, "product", oldID, err)
+				return
+			}
+			report.IDMap["products"][oldID] = stored.ID
+		}
+		// Reserved units, photos and featured flags come along, so the pending orders restored below
+		// find their units still held.
+		for _, item := range doc.Inventory {
+			oldID := item.ID
+			item.ProductID = report.IDMap["products"][item.ProductID]
+			stored, err := s.inventory.Add(ctx, item)
+			if err != nil {
+				s.restoreFailed(w, "inventory", oldID, err)
+				return
+			}
+			report.IDMap["inventory"][oldID] = stored.ID
+		}
+		for _, bo := range doc.Orders {
+			o := bo.order(s.clock.Now())
+			// Only pending orders still hold units; a finished order's reservation was settled.
+			o.Reservations = nil
+			if bo.pending() && len(bo.Reservations) > 0 {
+				o.Reservations = make(map[int64]int, len(bo.Reservations))
+				for batch, units := range bo.Reservations {
+					o.Reservations[report.IDMap["inventory"][batch]] = units
+				}
+			}
+			stored, err := s.orders.Restore(ctx, o)
+			if err != nil {
+				s.restoreFailed(w, "order", bo.ID, err)
+				return
+			}
+			report.IDMap["orders"][bo.ID] = stored.ID
+		}
+
+		report.Valid = true
+		s.logger.Printf("restore imported %d orders, %d batches, %d products from %s", len(doc.Orders), len(doc.Inventory), len(doc.Products), s.clientIP(r))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// restoreReport summarizes a restore or dry run: record counts, per-record problems, and the id mapping.
+type restoreReport struct {
+	DryRun bool                       `json:"dry_run"`
+	Valid  bool                       `json:"valid"`
+	Counts map[string]int             `json:"counts"`
+	Errors []fieldError               `json:"errors,omitempty"`
+	IDMap  map[string]map[int64]int64 `json:"id_map,omitempty"`
+}
+
+// restoreFailed reports an import that stopped midway; earlier records of the document stay imported.
+func (s *Server) restoreFailed(w http.ResponseWriter, kind string, id int64, err error) {
+	s.logger.Printf("restore stopped at %s %d: %v", kind, id, err)
+	s.respondError(w, fmt.Sprintf("restore stopped at %s %d: %v", kind, id, err), http.StatusInternalServerError)
+}
+
+// validateBackup checks every record with the same rules the live endpoints apply, and checks the
+// references between records against the document itself: ids are unique per kind, and pending
+// orders hold units only in batches of the document, never more than those batches have reserved.
+// A batch naming a product the document lacks is restored without a product link.
+func (s *Server) validateBackup(doc backupDocument) []fieldError {
+	var problems []fieldError
+	products := make(map[int64]bool, len(doc.Products))
+	for i, p := range doc.Products {
+		prefix := fmt.Sprintf("products[%d].", i)
+		if products[p.ID] {
+			problems = append(problems, fieldError{Field: prefix + "id", Code: codeDuplicate, Message: "id is listed twice"})
+		}
+		products[p.ID] = true
+		if strings.TrimSpace(p.Name) == "" {
+			problems = append(problems, fieldError{Field: prefix + "name", Code: codeRequired, Message: "name is required"})
+		}
+		if strings.TrimSpace(p.Category) == "" {
+			problems = append(problems, fieldError{Field: prefix + "category", Code: codeRequired, Message: "category is required"})
+		}
+		if p.BasePriceCents < 0 {
+			problems = append(problems, fieldError{Field: prefix + "base_price_cents", Code: codeInvalid, Message: "invalid price_rub"})
+		}
+	}
+	batches := make(map[int64]inventory.Item, len(doc.Inventory))
+	for i, item := range doc.Inventory {
+		prefix := fmt.Sprintf("inventory[%d].", i)
+		if _, ok := batches[item.ID]; ok {
+			problems = append(problems, fieldError{Field: prefix + "id", Code: codeDuplicate, Message: "id is listed twice"})
+		}
+		batches[item.ID] = item
+		if strings.TrimSpace(item.Name) == "" {
+			problems = append(problems, fieldError{Field: prefix + "name", Code: codeRequired, Message: "name is required"})
+		}
+		if strings.TrimSpace(item.Category) == "" {
+			problems = append(problems, fieldError{Field: prefix + "category", Code: codeRequired, Message: "category is required"})
+		}
+		if item.BakedAt.IsZero() {
+			problems = append(problems, fieldError{Field: prefix + "baked_at", Code: codeRequired, Message: "baked_at is required"})
+		}
+		if item.AvailableCount < 0 {
+			problems = append(problems, fieldError{Field: prefix + "available_count", Code: codeNotPositive, Message: "quantity must be positive"})
+		}
+		if item.ReservedCount < 0 || item.ReservedCount > max(item.AvailableCount, 0) {
+			problems = append(problems, fieldError{Field: prefix + "reserved_count", Code: codeInvalid, Message: "reserved_count must lie between 0 and available_count"})
+		}
+		if item.PriceCents < 0 {
+			problems = append(problems, fieldError{Field: prefix + "price_cents", Code: codeInvalid, Message: "invalid price_rub"})
+		}
+	}
+	held := make(map[int64]int, len(batches))
+	orders := make(map[int64]bool, len(doc.Orders))
+	for i, bo := range doc.Orders {
+		prefix := fmt.Sprintf("orders[%d]", i)
+		if orders[bo.ID] {
+			problems = append(problems, fieldError{Field: prefix + ".id", Code: codeDuplicate, Message: "id is listed twice"})
+		}
+		orders[bo.ID] = true
+		if err := s.orders.CheckRestore(bo.order(s.clock.Now())); err != nil {
+			if !order.IsValidation(err) {
+				problems = append(problems, fieldError{Field: prefix, Code: codeInvalid, Message: err.Error()})
+			} else {
+				for _, fe := range orderFieldErrors(err) {
+					fe.Field = strings.TrimSuffix(prefix+"."+fe.Field, ".")
+					problems = append(problems, fe)
+				}
+			}
+		}
+		if !bo.pending() {
+			continue
+		}
+		for batch, units := range bo.Reservations {
+			field := fmt.Sprintf("%s.reservations.%d", prefix, batch)
+			if _, ok := batches[batch]; !ok {
+				problems = append(problems, fieldError{Field: field, Code: codeInvalid, Message: "reservation names a batch the backup does not hold"})
+				continue
+			}
+			if units <= 0 {
+				problems = append(problems, fieldError{Field: field, Code: codeNotPositive, Message: "quantity must be positive"})
+				continue
+			}
+			held[batch] += units
+		}
+	}
+	// Listed in document order so the report reads the same on every run.
+	for i, item := range doc.Inventory {
+		if units := held[item.ID]; units > item.ReservedCount {
+			problems = append(problems, fieldError{Field: fmt.Sprintf("inventory[%d].reserved_count", i), Code: codeMismatch, Message: "pending orders hold more units than the batch has reserved"})
+		}
+	}
+	return problems
+}
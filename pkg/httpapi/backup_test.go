@@ -0,0 +1,220 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bakery/pkg/clock"
+	"bakery/pkg/inventory"
+	"bakery/pkg/order"
+	"bakery/pkg/product"
+	"bakery/pkg/storage/memorydriver"
+)
+
+// restoreToken is the -admin-token of the backup tests' instances.
+const restoreToken = "s3cret"
+
+// bakery is one instance of the backup tests: its own store, services and server.
+type bakery struct {
+	orders    *order.Service
+	inventory *inventory.Service
+	products  *product.Service
+	handler   http.Handler
+}
+
+// newBakery builds an instance the way the app wires one, with strict item checks and stock
+// reservations on, so restored orders meet every rule a live order would.
+func newBakery(t *testing.T, now time.Time) bakery {
+	t.Helper()
+	clk := clock.NewManual(now)
+	db := memorydriver.OpenTest(t, clk)
+	inv := inventory.NewService(inventory.NewRepository(db, clk), inventory.Options{Clock: clk})
+	t.Cleanup(inv.Close)
+	products := product.NewService(product.NewRepository(db, clk))
+	t.Cleanup(products.Close)
+	orders := order.NewService(order.NewRepository(db, clk), order.Options{Clock: clk, Catalog: inv, Stock: inv, DedupWindow: time.Hour})
+	t.Cleanup(orders.Close)
+	srv, err := New(orders, inv, products, log.New(io.Discard, "", 0), Options{Clock: clk, AdminToken: restoreToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bakery{orders: orders, inventory: inv, products: products, handler: srv.Handler()}
+}
+
+// admin sends an admin API request with the token and returns the recorded answer.
+func (b bakery) admin(method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+restoreToken)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	b.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// baguetteOrder asks for n baguettes on weekly bread and croissant days.
+func baguetteOrder(name string, n int) order.Order {
+	return order.Order{
+		CustomerName:      name,
+		Address:           "Lenina 1",
+		Phone:             "+79990000000",
+		Items:             []order.OrderItem{{Name: "Багет", Quantity: n}},
+		BreadSchedule:     order.BreadSchedule{Days: []string{"monday"}, Frequency: "everyday", StartDate: "2026-10-19"},
+		CroissantSchedule: []order.CroissantSchedule{{Day: "monday", Quantity: 2}},
+	}
+}
+
+// seedBackup fills an instance with a product, a featured batch with a photo, a pending order
+// holding three of its units and a delivered one, and returns its backup document.
+func seedBackup(t *testing.T, now time.Time) string {
+	t.Helper()
+	ctx := context.Background()
+	source := newBakery(t, now)
+	p, err := source.products.Add(ctx, product.Product{Name: "Багет", Category: "bread", BasePriceCents: 12000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := inventory.Item{Name: "Багет", Category: "bread", AvailableCount: 10, PriceCents: 12000, BakedAt: now.Add(-time.Hour), ProductID: p.ID, Image: "baguette.jpg", Featured: true}
+	if _, err := source.inventory.Add(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.orders.Submit(ctx, baguetteOrder("Ivan", 3)); err != nil {
+		t.Fatal(err)
+	}
+	delivered, err := source.orders.Submit(ctx, baguetteOrder("Olga", 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.orders.SetStatus(ctx, delivered.ID, order.StatusDelivered); err != nil {
+		t.Fatal(err)
+	}
+	rec := source.admin(http.MethodGet, "/api/admin/backup", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("backup answered %d: %s", rec.Code, rec.Body)
+	}
+	return rec.Body.String()
+}
+
+func TestRestoreIntoAnEmptyInstance(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 15, 6, 0, 0, 0, time.UTC)
+	doc := seedBackup(t, now)
+
+	// The target knows no batch yet, so checking orders against its live menu or stock would fail.
+	target := newBakery(t, now)
+	rec := target.admin(http.MethodPost, "/api/admin/restore?confirm=true", doc)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore answered %d: %s", rec.Code, rec.Body)
+	}
+
+	items, err := target.inventory.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("restored %d batches, want 1", len(items))
+	}
+	batch := items[0]
+	if batch.AvailableCount != 8 || batch.ReservedCount != 3 || batch.Image != "baguette.jpg" || !batch.Featured || batch.ProductID == 0 {
+		t.Errorf("restored batch %+v, want 8 available, 3 reserved, its photo, featured and linked", batch)
+	}
+
+	orders, err := target.orders.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pending order.Order
+	statuses := make(map[string]string, len(orders))
+	for _, o := range orders {
+		statuses[o.CustomerName] = o.Status
+		if o.Status == order.StatusPending {
+			pending = o
+		}
+	}
+	if statuses["Ivan"] != order.StatusPending || statuses["Olga"] != order.StatusDelivered {
+		t.Fatalf("restored statuses %v, want Ivan pending and Olga delivered", statuses)
+	}
+	if len(pending.Reservations) != 1 || pending.Reservations[batch.ID] != 3 {
+		t.Errorf("pending order holds %v, want 3 units of batch %d", pending.Reservations, batch.ID)
+	}
+
+	// Cancelling the restored order gives its units back to the restored batch.
+	if _, err := target.orders.SetStatus(ctx, pending.ID, order.StatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	if items, err = target.inventory.List(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if items[0].ReservedCount != 0 {
+		t.Errorf("reserved units after cancelling = %d, want 0", items[0].ReservedCount)
+	}
+}
+
+func TestRestoreWritesNothingWhenTheDocumentDoesNotHoldTogether(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 15, 6, 0, 0, 0, time.UTC)
+	doc := seedBackup(t, now)
+
+	tests := []struct {
+		name string
+		// edit breaks the document and returns the field the answer must name.
+		edit func(*backupDocument) string
+	}{
+		{"reservation in a batch the backup lacks", func(d *backupDocument) string {
+			for i := range d.Orders {
+				if d.Orders[i].pending() {
+					d.Orders[i].Reservations = map[int64]int{999: 3}
+					return fmt.Sprintf("orders[%d].reservations.999", i)
+				}
+			}
+			panic("the backup holds no pending order")
+		}},
+		{"more units held than reserved", func(d *backupDocument) string {
+			d.Inventory[0].ReservedCount = 1
+			return "inventory[0].reserved_count"
+		}},
+		{"batch listed twice", func(d *backupDocument) string {
+			d.Inventory = append(d.Inventory, d.Inventory[0])
+			return "inventory[1].id"
+		}},
+		{"invalid last order", func(d *backupDocument) string {
+			d.Orders[len(d.Orders)-1].Phone = ""
+			return fmt.Sprintf("orders[%d].phone", len(d.Orders)-1)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var parsed backupDocument
+			if err := json.Unmarshal([]byte(doc), &parsed); err != nil {
+				t.Fatal(err)
+			}
+			field := tt.edit(&parsed)
+			body, err := json.Marshal(parsed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			target := newBakery(t, now)
+			rec := target.admin(http.MethodPost, "/api/admin/restore?confirm=true", string(body))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("restore answered %d, want 400: %s", rec.Code, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), `"`+field+`"`) {
+				t.Errorf("answer does not name %s: %s", field, rec.Body)
+			}
+			products, _ := target.products.List(ctx)
+			items, _ := target.inventory.List(ctx)
+			orders, _ := target.orders.List(ctx)
+			if len(products)+len(items)+len(orders) != 0 {
+				t.Errorf("a rejected restore wrote %d products, %d batches and %d orders", len(products), len(items), len(orders))
+			}
+		})
+	}
+}
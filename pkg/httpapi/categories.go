@@ -0,0 +1,57 @@
+package httpapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCategories are the storefront tabs the templates and imageForCategory know about.
+var DefaultCategories = []string{"bread", "croissant", "pastry"}
+
+// categoryRules normalizes admin-entered categories and, unless custom ones are allowed,
+// keeps them to the configured list so "Croissant" and "bread " cannot split a tab in two.
+type categoryRules struct {
+	allowed []string
+	custom  bool
+}
+
+// newCategoryRules lowercases the configured list and falls back to DefaultCategories when it is empty.
+func newCategoryRules(allowed []string, custom bool) categoryRules {
+	rules := categoryRules{custom: custom}
+	for _, category := range allowed {
+		category = normalizeCategory(category)
+		if category != "" {
+			rules.allowed = append(rules.allowed, category)
+		}
+	}
+	if len(rules.allowed) == 0 {
+		rules.allowed = append(rules.allowed, DefaultCategories...)
+	}
+	return rules
+}
+
+// normalizeCategory is the single spelling every stored category goes through.
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// check returns the normalized category or a field error naming the valid choices.
+func (c categoryRules) check(raw string) (string, error) {
+	category := normalizeCategory(raw)
+	if category == "" {
+		return "", fieldError{Field: "category", Code: codeRequired, Message: "category is required"}
+	}
+	if c.custom {
+		return category, nil
+	}
+	for _, allowed := range c.allowed {
+		if category == allowed {
+			return category, nil
+		}
+	}
+	return "", fieldError{
+		Field:   "category",
+		Code:    codeInvalid,
+		Message: fmt.Sprintf("unknown category %q: use one of %s", category, strings.Join(c.allowed, ", ")),
+	}
+}
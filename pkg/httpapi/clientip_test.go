@@ -0,0 +1,53 @@
+package httpapi
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := parseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{trustedProxies: proxies}
+
+	tests := []struct {
+		name      string
+		peer      string
+		forwarded []string
+		want      string
+	}{
+		{"direct visitor", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"untrusted peer spoofing the header", "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"untrusted peer posing as a trusted proxy", "203.0.113.7:5000", []string{"10.0.0.5"}, "203.0.113.7"},
+		{"trusted proxy", "10.0.0.5:443", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"trusted single address", "192.0.2.1:443", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"client prepends a fake hop", "10.0.0.5:443", []string{"1.2.3.4, 198.51.100.1"}, "198.51.100.1"},
+		{"fake hop in a separate header", "10.0.0.5:443", []string{"1.2.3.4", "198.51.100.1"}, "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.5:443", []string{"198.51.100.1, 10.0.0.9, 10.1.1.1"}, "198.51.100.1"},
+		{"garbled last hop", "10.0.0.5:443", []string{"198.51.100.1, not-an-ip"}, "10.0.0.5"},
+		{"trusted proxy without the header", "10.0.0.5:443", nil, "10.0.0.5"},
+		{"IPv4-mapped IPv6 peer", "[::ffff:10.0.0.5]:443", []string{"198.51.100.1"}, "198.51.100.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.peer
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if got := srv.clientIP(r); got != tt.want {
+				t.Errorf("clientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxiesRejectsGarbage(t *testing.T) {
+	for _, entry := range []string{"10.0.0.0/33", "proxy.local", "10.0.0"} {
+		if _, err := parseTrustedProxies([]string{entry}); err == nil {
+			t.Errorf("parseTrustedProxies(%q) accepted it", entry)
+		}
+	}
+}
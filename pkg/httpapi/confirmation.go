@@ -0,0 +1,44 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bakery/pkg/order"
+)
+
+// orderConfirmation is the reply to a new order: the stored order's fields at the top level, as
+// before, plus a message to show the customer and the first planned delivery. NextDelivery is
+// YYYY-MM-DD, or null when the schedules plan nothing in the next two months.
+type orderConfirmation struct {
+	order.Order
+	Message      string  `json:"message"`
+	NextDelivery *string `json:"next_delivery"`
+}
+
+// confirmOrder builds the reply to a stored order. The message comes from -order-confirmation when
+// set and from the locale's "order.confirmation" text otherwise; both may use {name}, {date} and {id}.
+// {date} is written with -date-layout or else the locale's way, and as "order.date_unknown" when nothing is planned.
+func (s *Server) confirmOrder(r *http.Request, stored order.Order) orderConfirmation {
+	locale := s.locale(r)
+	message := s.confirmationTemplate
+	if message == "" {
+		message = s.catalog.Text(locale, "order.confirmation")
+	}
+	reply := orderConfirmation{Order: stored}
+	date := s.catalog.Text(locale, "order.date_unknown")
+	if next, ok := order.NextDelivery(stored, s.clock.Now().UTC()); ok {
+		reply.NextDelivery = &next
+		if day, err := time.Parse(deliveryDateLayout, next); err == nil {
+			date = day.Format(localDateLayout(s.catalog, locale, s.dateLayout))
+		}
+	}
+	reply.Message = strings.NewReplacer(
+		"{name}", strings.TrimSpace(stored.CustomerName),
+		"{date}", date,
+		"{id}", strconv.FormatInt(stored.ID, 10),
+	).Replace(message)
+	return reply
+}
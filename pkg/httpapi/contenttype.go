@@ -0,0 +1,25 @@
+package httpapi
+
+import (
+	"mime"
+	"net/http"
+)
+
+// requireJSON answers 415 to a request body that is not declared as application/json and reports
+// whether it did. Without it a form post or a typo in the header reached the decoder and came back
+// as "invalid JSON", which sent client authors hunting for a syntax error in a body that was fine.
+// A charset parameter is accepted, and so is a request with no body at all, because several
+// endpoints treat an empty body as "no changes". The AnyContentType option turns the check off for
+// older clients that post JSON under another type.
+func (s *Server) requireJSON(w http.ResponseWriter, r *http.Request) bool {
+	if s.anyContentType || r.ContentLength == 0 {
+		return false
+	}
+	header := r.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(header); err == nil && mediaType == "application/json" {
+		return false
+	}
+	s.logger.Printf("%s %s from %s refused: body sent as %q", r.Method, r.URL.Path, s.clientIP(r), header)
+	s.respondError(w, s.text(r, "request body must be JSON sent as Content-Type: application/json"), http.StatusUnsupportedMediaType)
+	return true
+}
@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bakery/pkg/customer"
+	"bakery/pkg/order"
+)
+
+// customerOrders is the answer of GET /api/admin/customers/{phone}/orders.
+type customerOrders struct {
+	Customer customer.Customer `json:"customer"`
+	Orders   []order.Order     `json:"orders"`
+}
+
+// customerSearchEndpoint answers GET /api/admin/customers?q= with the customers whose phone or name
+// matches, the most recent orderers first, so an admin taking a phone order can pick a regular
+// after a few digits. ?limit= caps the list at up to customer.MaxSearchLimit.
+func (s *Server) customerSearchEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := strings.TrimSpace(r.URL.Query().Get("q"))
+		if q == "" {
+			s.respondValidation(w, r, fieldError{Field: "q", Code: codeRequired, Message: "search query is required"})
+			return
+		}
+		limit := customer.DefaultSearchLimit
+		if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n <= 0 || n > customer.MaxSearchLimit {
+				s.respondValidation(w, r, fieldError{Field: "limit", Code: codeInvalid, Message: "limit must be between 1 and 50"})
+				return
+			}
+			limit = n
+		}
+		found := []customer.Customer{}
+		if s.customers != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+			defer cancel()
+			var err error
+			if found, err = s.customers.Search(ctx, q, limit); err != nil {
+				s.logger.Printf("customer search for %q failed: %v", q, err)
+				s.respondServiceError(w, err)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(found)
+	})
+}
+
+// customerOrdersEndpoint lists a customer's orders, newest first, paged with ?limit= and ?offset=
+// like the order listing. The phone may be written any way the order form accepts, so
+// "8 900 000-00-01" finds the customer who ordered as "+79000000001". A phone nobody ordered
+// with, or a server without customer tracking, answers 404.
+func (s *Server) customerOrdersEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.customers == nil {
+			s.respondError(w, s.text(r, "customer not found"), http.StatusNotFound)
+			return
+		}
+		page, ok := s.orderPage(w, r)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		found, err := s.customers.Find(ctx, r.PathValue("phone"))
+		switch {
+		case errors.Is(err, customer.ErrNotFound):
+			s.respondError(w, s.text(r, "customer not found"), http.StatusNotFound)
+			return
+		case err != nil:
+			s.logger.Printf("customer lookup for %q failed: %v", r.PathValue("phone"), err)
+			s.respondServiceError(w, err)
+			return
+		}
+		page.CustomerID = found.ID
+		orders, err := s.orders.ListPage(ctx, page)
+		if err != nil {
+			s.logger.Printf("order listing for customer %d failed: %v", found.ID, err)
+			s.respondServiceError(w, err)
+			return
+		}
+		if orders == nil {
+			orders = []order.Order{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(customerOrders{Customer: found, Orders: orders})
+	})
+}
@@ -0,0 +1,40 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+
+	"bakery/pkg/metrics"
+)
+
+// debugReport is the answer of GET /api/admin/debug.
+type debugReport struct {
+	StartedAt     time.Time                         `json:"started_at"`
+	UptimeSeconds int64                             `json:"uptime_seconds"`
+	Goroutines    int                               `json:"goroutines"`
+	Services      map[string]metrics.ActivityReport `json:"services"`
+}
+
+// debugEndpoint shows what the channel-based services are doing, for chasing "queue is busy" errors
+// without a debugger: calls in flight per service, where more than one means callers are queueing,
+// the last error each recorded, the goroutine count and the uptime. Every figure is read from
+// counters, so the endpoint answers even when a service goroutine is stuck.
+func (s *Server) debugEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := s.clock.Now()
+		report := debugReport{
+			StartedAt:     s.started.UTC(),
+			UptimeSeconds: int64(now.Sub(s.started) / time.Second),
+			Goroutines:    runtime.NumGoroutine(),
+			Services: map[string]metrics.ActivityReport{
+				"orders":    s.orders.Activity(),
+				"inventory": s.inventory.Activity(),
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(report)
+	})
+}
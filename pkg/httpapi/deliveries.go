@@ -0,0 +1,57 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// deliveryDateLayout matches the dates stored on generated deliveries.
+const deliveryDateLayout = "2006-01-02"
+
+// deliveriesEndpoint lists the deliveries generated from recurring schedules so the bakers can plan
+// production per day. Without from/to it covers today through the generation horizon.
+func (s *Server) deliveriesEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			s.methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		today := s.clock.Now().UTC().Format(deliveryDateLayout)
+		from, ok := s.deliveryDate(w, r, "from", today)
+		if !ok {
+			return
+		}
+		fromDay, _ := time.Parse(deliveryDateLayout, from)
+		to, ok := s.deliveryDate(w, r, "to", fromDay.AddDate(0, 0, s.orders.DeliveryHorizon()-1).Format(deliveryDateLayout))
+		if !ok {
+			return
+		}
+		if to < from {
+			s.respondValidation(w, r, fieldError{Field: "to", Code: codeInvalid, Message: "to must not be before from"})
+			return
+		}
+
+		deliveries, err := s.orders.Deliveries(r.Context(), from, to)
+		if err != nil {
+			s.respondServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(deliveries)
+	})
+}
+
+// deliveryDate reads an optional YYYY-MM-DD query parameter, answering 400 itself when it is malformed.
+func (s *Server) deliveryDate(w http.ResponseWriter, r *http.Request, name, fallback string) (string, bool) {
+	raw := strings.TrimSpace(r.URL.Query().Get(name))
+	if raw == "" {
+		return fallback, true
+	}
+	if _, err := time.Parse(deliveryDateLayout, raw); err != nil {
+		s.respondValidation(w, r, fieldError{Field: name, Code: codeInvalid, Message: "dates must look like 2006-01-02"})
+		return "", false
+	}
+	return raw, true
+}
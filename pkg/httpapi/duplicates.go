@@ -0,0 +1,56 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"bakery/pkg/inventory"
+)
+
+// Duplicate batch policies for Options.DuplicateBatches. A batch duplicates another when name,
+// category and baking time all match, which is what entering the same tray twice looks like.
+const (
+	// DuplicateBatchesWarn answers 409 naming the existing batch; ?force=true stores it anyway.
+	DuplicateBatchesWarn = "warn"
+	// DuplicateBatchesReject answers 409 even with ?force=true.
+	DuplicateBatchesReject = "reject"
+	// DuplicateBatchesOff stores every batch without looking.
+	DuplicateBatchesOff = "off"
+)
+
+// validDuplicateBatches checks the policy name; empty means DuplicateBatchesWarn.
+func validDuplicateBatches(policy string) error {
+	switch policy {
+	case "", DuplicateBatchesWarn, DuplicateBatchesReject, DuplicateBatchesOff:
+		return nil
+	}
+	return fmt.Errorf("unknown duplicate batch policy %q: use warn, reject, or off", policy)
+}
+
+// checkDuplicates reports whether a new batch should be looked up before it is stored.
+func (s *Server) checkDuplicates(r *http.Request) bool {
+	switch s.duplicateBatches {
+	case DuplicateBatchesOff:
+		return false
+	case DuplicateBatchesReject:
+		return true
+	}
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	return !force
+}
+
+// respondDuplicate answers 409 with the id of the batch already stored, both in the body and in the
+// X-Duplicate-Of header that duplicate orders use, so the admin can open it instead.
+func (s *Server) respondDuplicate(w http.ResponseWriter, r *http.Request, dup *inventory.DuplicateError) {
+	w.Header().Set("X-Duplicate-Of", strconv.FormatInt(dup.Existing.ID, 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":       s.text(r, inventory.ErrDuplicate.Error()),
+		"code":        errorCodeConflict,
+		"existing_id": dup.Existing.ID,
+		"force":       s.duplicateBatches != DuplicateBatchesReject,
+	})
+}
@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// notFoundHandler answers every path no route matches. Until it existed the storefront's "/"
+// pattern caught them all, so a mistyped API path got a 200 with the page's HTML.
+func (s *Server) notFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.respondPageError(w, r, s.text(r, "not found"), http.StatusNotFound)
+	})
+}
+
+// methodNotAllowed is the one answer to a method a handler does not serve, from the route table
+// and from every handler's own default case alike. Allow lists the methods, implied HEAD and
+// OPTIONS included, so clients learn what would have worked.
+func (s *Server) methodNotAllowed(w http.ResponseWriter, r *http.Request, methods ...string) {
+	w.Header().Set("Allow", route{methods: methods}.allow())
+	s.respondPageError(w, r, s.text(r, "method not allowed"), http.StatusMethodNotAllowed)
+}
+
+// respondPageError answers API paths, and clients that do not ask for HTML, with the usual JSON
+// error; browsers get a page in the bakery's look with a way back to the storefront, titled after
+// the error code so each status has its own localized texts.
+func (s *Server) respondPageError(w http.ResponseWriter, r *http.Request, message string, status int) {
+	if strings.HasPrefix(r.URL.Path, "/api/") || !acceptsHTML(r) {
+		s.respondError(w, message, status)
+		return
+	}
+	var buf bytes.Buffer
+	err := s.errorTmpl.Execute(&buf, struct{ Lang, Kind string }{Lang: s.locale(r), Kind: errorCode(status)})
+	if err != nil {
+		s.logger.Printf("error page for %d failed to render: %v", status, err)
+		http.Error(w, message, status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+// acceptsHTML reports whether the Accept header names HTML, as every browser navigation does;
+// a bare */* from curl or a script does not count, so those get JSON.
+func acceptsHTML(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "text/html", "application/xhtml+xml":
+			return true
+		}
+	}
+	return false
+}
+
+// parseErrorPage loads the standalone error page, which like the maintenance page shares no layout
+// with the themes.
+func parseErrorPage(funcs template.FuncMap) (*template.Template, error) {
+	return template.New("errorpage.gohtml").Funcs(funcs).ParseFS(uiFS, "public_html/errorpage.gohtml")
+}
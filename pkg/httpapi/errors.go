@@ -0,0 +1,146 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"bakery/pkg/breaker"
+	"bakery/pkg/inventory"
+	"bakery/pkg/order"
+)
+
+// Validation codes mirror the order package's codes so both payload kinds share one vocabulary.
+const (
+	codeRequired    = "required"
+	codeNotPositive = "not_positive"
+	codeInvalid     = "invalid"
+	codeInvalidJSON = "invalid_json"
+	codeMismatch    = "mismatch"
+	codeDuplicate   = "duplicate"
+)
+
+// Error codes sit next to every error message so clients can branch on the kind of failure
+// without matching the message, which is translated and may be reworded.
+const (
+	errorCodeValidation  = "validation"
+	errorCodeAuth        = "unauthorized"
+	errorCodeForbidden   = "forbidden"
+	errorCodeNotFound    = "not_found"
+	errorCodeMethod      = "method_not_allowed"
+	errorCodeConflict    = "conflict"
+	errorCodeTooLarge    = "too_large"
+	errorCodeMediaType   = "unsupported_media_type"
+	errorCodeUnavailable = "unavailable"
+	errorCodeTimeout     = "timeout"
+	errorCodeInternal    = "internal"
+)
+
+// errorCode names the failure behind an HTTP status; statuses without a code of their own are internal.
+func errorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return errorCodeValidation
+	case http.StatusUnauthorized:
+		return errorCodeAuth
+	case http.StatusForbidden:
+		return errorCodeForbidden
+	case http.StatusNotFound:
+		return errorCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return errorCodeMethod
+	case http.StatusConflict:
+		return errorCodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return errorCodeTooLarge
+	case http.StatusUnsupportedMediaType:
+		return errorCodeMediaType
+	case http.StatusServiceUnavailable:
+		return errorCodeUnavailable
+	case http.StatusGatewayTimeout:
+		return errorCodeTimeout
+	}
+	return errorCodeInternal
+}
+
+// fieldError pins a payload problem to a single input so the storefront can highlight it.
+// Pointer is the RFC 6901 JSON pointer of the offending value when a schema check found it.
+type fieldError struct {
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+	Pointer string `json:"pointer,omitempty"`
+	Code    string `json:"code"`
+}
+
+func (e fieldError) Error() string { return e.Message }
+
+// asFieldError keeps unexpected validator errors reportable even when they carry no field.
+func asFieldError(err error) fieldError {
+	var fe fieldError
+	if errors.As(err, &fe) {
+		return fe
+	}
+	return fieldError{Code: codeInvalid, Message: err.Error()}
+}
+
+// respondError keeps JSON formatting consistent across endpoints: {"error": message, "code": "not_found"}.
+func (s *Server) respondError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message, "code": errorCode(status)})
+}
+
+// respondServiceError answers a failed service call. A full queue is 503 with Retry-After, since the
+// same request should go through once the orders ahead of it are done, and so is an open breaker,
+// which fails fast while storage recovers. A call the service took but did not finish in time is
+// 504, which points at a slow database or a stuck worker instead. Anything else is a 500.
+func (s *Server) respondServiceError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, order.ErrQueueBusy), errors.Is(err, inventory.ErrQueueBusy):
+		w.Header().Set("Retry-After", "1")
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, breaker.ErrUnavailable):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, order.ErrProcessingTimeout), errors.Is(err, inventory.ErrProcessingTimeout):
+		status = http.StatusGatewayTimeout
+	}
+	s.respondError(w, err.Error(), status)
+}
+
+// orderFieldErrors flattens an order validation error, including aggregated ones, into per-field entries.
+func orderFieldErrors(err error) []fieldError {
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		var out []fieldError
+		for _, inner := range multi.Unwrap() {
+			out = append(out, orderFieldErrors(inner)...)
+		}
+		return out
+	}
+	return []fieldError{{Field: order.ValidationField(err), Code: order.ValidationCode(err), Message: err.Error()}}
+}
+
+// respondValidation answers 400 with structured errors while keeping the flat message older clients read.
+// The first failure stays under "error" for single-error clients and the full list goes under "errors".
+func (s *Server) respondValidation(w http.ResponseWriter, r *http.Request, fes ...fieldError) {
+	s.respondFieldErrors(w, r, http.StatusBadRequest, fes...)
+}
+
+// respondFieldErrors is respondValidation with another status, for a well-formed payload that
+// contradicts what is stored, which is 422 rather than 400.
+func (s *Server) respondFieldErrors(w http.ResponseWriter, r *http.Request, status int, fes ...fieldError) {
+	messages := make([]string, 0, len(fes))
+	for i := range fes {
+		fes[i].Message = s.text(r, fes[i].Message)
+		messages = append(messages, fes[i].Message)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Error   fieldError   `json:"error"`
+		Errors  []fieldError `json:"errors"`
+		Message string       `json:"message"`
+		Code    string       `json:"code"`
+	}{Error: fes[0], Errors: fes, Message: strings.Join(messages, "; "), Code: errorCodeValidation})
+}
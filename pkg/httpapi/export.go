@@ -0,0 +1,158 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bakery/pkg/order"
+)
+
+// exportColumns is the CSV header of the order export; rows follow the same order.
+var exportColumns = []string{"id", "created_at", "status", "name", "phone", "address", "items", "comment"}
+
+// exportRange reads the from and to dates shared by the order exports. from defaults to the first
+// day of the current month and to defaults to now, so a bare request exports the month so far. Both
+// dates are UTC days like the delivery endpoints, and to includes its whole day. On a bad date it
+// answers the request itself and reports false.
+func (s *Server) exportRange(w http.ResponseWriter, r *http.Request) (start, end time.Time, ok bool) {
+	now := s.clock.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	from, ok := s.deliveryDate(w, r, "from", monthStart.Format(deliveryDateLayout))
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	to, ok := s.deliveryDate(w, r, "to", "")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	if to != "" && to < from {
+		s.respondValidation(w, r, fieldError{Field: "to", Code: codeInvalid, Message: "to must not be before from"})
+		return time.Time{}, time.Time{}, false
+	}
+	start, _ = time.Parse(deliveryDateLayout, from)
+	end = now
+	if to != "" {
+		day, _ := time.Parse(deliveryDateLayout, to)
+		end = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return start, end, true
+}
+
+// orderExportEndpoint streams the orders created within the exportRange as CSV for accounting.
+func (s *Server) orderExportEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, end, ok := s.exportRange(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+		orders, err := s.orders.ListBetweenCursor(ctx, start, end)
+		if err != nil {
+			s.logger.Printf("order export failed: %v", err)
+			s.respondServiceError(w, err)
+			return
+		}
+
+		filename := fmt.Sprintf("bakery-orders-%s-%s.csv", start.Format("20060102"), end.Format("20060102"))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		out := csv.NewWriter(w)
+		out.Write(exportColumns)
+		// Rows go out as the cursor decodes them, so an export of a long history never holds it all.
+		// The header is already sent by the time a row fails, so a failure can only cut the file short.
+		count := 0
+		for o, err := range orders {
+			if err != nil {
+				out.Flush()
+				s.logger.Printf("order export to %s cut short after %d orders: %v", s.clientIP(r), count, err)
+				return
+			}
+			out.Write(exportRow(o))
+			count++
+		}
+		out.Flush()
+		if err := out.Error(); err != nil {
+			s.logger.Printf("order export to %s failed: %v", s.clientIP(r), err)
+			return
+		}
+		s.logger.Printf("order export of %d orders from %s to %s sent to %s", count, start.Format(deliveryDateLayout), end.Format(deliveryDateLayout), s.clientIP(r))
+	})
+}
+
+// orderSyncEndpoint streams the orders created within the exportRange as newline-delimited JSON, one
+// order per line in the shape GET /api/orders uses, for jobs that parse a stream rather than one
+// large array. Orders are read through the repository cursor, so the whole range is never loaded.
+func (s *Server) orderSyncEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, end, ok := s.exportRange(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+		orders, err := s.orders.ListBetweenCursor(ctx, start, end)
+		if err != nil {
+			s.logger.Printf("order sync failed: %v", err)
+			s.respondServiceError(w, err)
+			return
+		}
+		count, err := streamJSONLines(w, orders)
+		switch {
+		case err != nil && count == 0:
+			s.logger.Printf("order sync failed: %v", err)
+			s.respondServiceError(w, err)
+		case err != nil:
+			s.logger.Printf("order sync to %s cut short after %d orders: %v", s.clientIP(r), count, err)
+		default:
+			s.logger.Printf("order sync of %d orders from %s to %s sent to %s", count, start.Format(deliveryDateLayout), end.Format(deliveryDateLayout), s.clientIP(r))
+		}
+	})
+}
+
+// exportRow flattens an order into the exportColumns. Items become "name x quantity" pairs joined
+// by "; " so a spreadsheet keeps one order per line, and created_at is RFC 3339 in UTC. A custom
+// item carries its description in parentheses, since the name alone says little about what to bake.
+// Every cell customers typed goes through spreadsheetCell.
+func exportRow(o order.Order) []string {
+	items := make([]string, 0, len(o.Items))
+	for _, item := range o.Items {
+		entry := fmt.Sprintf("%s x %d", item.Name, item.Quantity)
+		if item.Custom {
+			entry += fmt.Sprintf(" (custom: %s)", strings.TrimSpace(item.Description))
+		}
+		items = append(items, entry)
+	}
+	return []string{
+		strconv.FormatInt(o.ID, 10),
+		o.CreatedAt.UTC().Format(time.RFC3339),
+		o.Status,
+		spreadsheetCell(o.CustomerName),
+		spreadsheetCell(o.Phone),
+		spreadsheetCell(o.Address),
+		spreadsheetCell(strings.Join(items, "; ")),
+		spreadsheetCell(o.Comment),
+	}
+}
+
+// spreadsheetCell defuses a cell a spreadsheet would run as a formula. Names, addresses and
+// comments come from the public order form, and the export is opened by staff in Excel or
+// LibreOffice, where a cell such as =HYPERLINK(...) runs on opening. A leading apostrophe makes
+// the spreadsheet show the text as typed, so phones like +7... keep their digits and gain only it.
+func spreadsheetCell(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,45 @@
+package httpapi
+
+import (
+	"testing"
+	"time"
+
+	"bakery/pkg/order"
+)
+
+func TestExportRowDefusesFormulas(t *testing.T) {
+	o := order.Order{
+		ID:           7,
+		CreatedAt:    time.Date(2026, time.October, 15, 6, 0, 0, 0, time.UTC),
+		Status:       order.StatusPending,
+		CustomerName: `=HYPERLINK("http://evil.example","Ivan")`,
+		Phone:        "+79990000000",
+		Address:      "@SUM(1+1)",
+		Items:        []order.OrderItem{{Name: "-Торт", Quantity: 1, Custom: true, Description: " с вишней "}},
+		Comment:      "\tпозвонить",
+	}
+	want := []string{
+		"7",
+		"2026-10-15T06:00:00Z",
+		order.StatusPending,
+		`'=HYPERLINK("http://evil.example","Ivan")`,
+		"'+79990000000",
+		"'@SUM(1+1)",
+		"'-Торт x 1 (custom: с вишней)",
+		"'\tпозвонить",
+	}
+	got := exportRow(o)
+	if len(got) != len(want) {
+		t.Fatalf("exportRow gave %d cells, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s = %q, want %q", exportColumns[i], got[i], want[i])
+		}
+	}
+
+	o.CustomerName, o.Address, o.Comment = "Ivan", "Lenina 1", ""
+	if got := exportRow(o); got[3] != "Ivan" || got[5] != "Lenina 1" || got[7] != "" {
+		t.Errorf("plain cells changed: %q", got)
+	}
+}
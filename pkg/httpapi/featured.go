@@ -0,0 +1,58 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bakery/pkg/inventory"
+)
+
+// featureEndpoint answers PATCH /api/admin/inventory/{id}/feature. An empty body or {"featured":true}
+// makes the batch a featured special; {"featured":false} clears it. Going over -max-featured is a 409,
+// so the owner unfeatures yesterday's special first.
+func (s *Server) featureEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			s.respondValidation(w, r, fieldError{Field: "id", Code: codeInvalid, Message: "invalid id"})
+			return
+		}
+		if s.requireJSON(w, r) {
+			return
+		}
+		payload := struct {
+			Featured bool `json:"featured"`
+		}{Featured: true}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			s.logger.Printf("inventory feature change failed: unable to decode payload: %v", err)
+			s.respondValidation(w, r, fieldError{Code: codeInvalidJSON, Message: "invalid JSON"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+
+		if err := s.inventory.Feature(ctx, id, payload.Featured); err != nil {
+			switch {
+			case errors.Is(err, inventory.ErrNotFound):
+				s.respondError(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, inventory.ErrFeaturedLimit):
+				s.logger.Printf("inventory item %d not featured: limit reached", id)
+				s.respondError(w, err.Error(), http.StatusConflict)
+			default:
+				s.logger.Printf("inventory feature change failed for %d: %v", id, err)
+				s.respondServiceError(w, err)
+			}
+			return
+		}
+		s.logger.Printf("inventory item %d featured=%t", id, payload.Featured)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+
+	"bakery/pkg/i18n"
+	"bakery/pkg/money"
+)
+
+// templateFuncs lets the .gohtml files format values themselves instead of each handler handing
+// them pre-formatted strings, so a price or a date reads the same on every page:
+//
+//	{{formatMoney 22050}}                  220,50 ₽ in the configured currency
+//	{{formatDate .Lang "2026-10-26"}}      26.10.2026, or October 26, 2026 in English
+//	{{localize .Lang "page.free_delivery"}} the catalog text for the page's language
+//
+// formatDate takes a time.Time or a delivery date string. Its layout is dateLayout when set and the
+// locale's "order.date_layout" otherwise, the same one confirmOrder writes {date} with.
+func templateFuncs(catalog *i18n.Catalog, currency, dateLayout string) template.FuncMap {
+	return template.FuncMap{
+		"formatMoney": func(cents int) string {
+			return money.In(cents, currency).String()
+		},
+		"formatDate": func(locale string, value any) (string, error) {
+			var day time.Time
+			switch v := value.(type) {
+			case time.Time:
+				day = v
+			case string:
+				parsed, err := time.Parse(deliveryDateLayout, v)
+				if err != nil {
+					return "", fmt.Errorf("formatDate: %w", err)
+				}
+				day = parsed
+			default:
+				return "", fmt.Errorf("formatDate: cannot format %T", value)
+			}
+			return day.Format(localDateLayout(catalog, locale, dateLayout)), nil
+		},
+		"localize": func(locale, key string) string {
+			return catalog.Text(locale, key)
+		},
+	}
+}
+
+// localDateLayout is the -date-layout override when there is one and the locale's layout otherwise.
+func localDateLayout(catalog *i18n.Catalog, locale, override string) string {
+	if override != "" {
+		return override
+	}
+	return catalog.Text(locale, "order.date_layout")
+}
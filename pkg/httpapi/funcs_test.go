@@ -0,0 +1,64 @@
+package httpapi
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"bakery/pkg/i18n"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	catalog, err := i18n.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery := time.Date(2026, time.October, 26, 7, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		currency   string
+		dateLayout string
+		tmpl       string
+		data       any
+		want       string
+		// wantErr is part of the error expected from Execute; empty means it must succeed.
+		wantErr string
+	}{
+		{name: "money in roubles", currency: "RUB", tmpl: `{{formatMoney 22050}}`, want: "220,50 ₽"},
+		{name: "money defaults to roubles", tmpl: `{{formatMoney 5}}`, want: "0,05 ₽"},
+		{name: "money in dollars", currency: "USD", tmpl: `{{formatMoney -550}}`, want: "-$5.50"},
+		{name: "money in euros", currency: "EUR", tmpl: `{{formatMoney 1230}}`, want: "12,30 €"},
+		{name: "date string in Russian", tmpl: `{{formatDate "ru" "2026-10-26"}}`, want: "26.10.2026"},
+		{name: "date string in English", tmpl: `{{formatDate "en" "2026-10-26"}}`, want: "October 26, 2026"},
+		{name: "time.Time", tmpl: `{{formatDate "en" .}}`, data: delivery, want: "October 26, 2026"},
+		{name: "unknown locale falls back", tmpl: `{{formatDate "de" "2026-10-26"}}`, want: "26.10.2026"},
+		{name: "layout override beats the locale", dateLayout: "2006/01/02", tmpl: `{{formatDate "en" "2026-10-26"}}`, want: "2026/10/26"},
+		{name: "layout override applies to time.Time", dateLayout: "02 Jan", tmpl: `{{formatDate "ru" .}}`, data: delivery, want: "26 Oct"},
+		{name: "malformed date string", tmpl: `{{formatDate "en" "26.10.2026"}}`, wantErr: "formatDate: parsing time"},
+		{name: "unsupported type", tmpl: `{{formatDate "en" 42}}`, wantErr: "formatDate: cannot format int"},
+		{name: "localize in Russian", tmpl: `{{localize "ru" "order.date_layout"}}`, want: "02.01.2006"},
+		{name: "localize in English", tmpl: `{{localize "en" "page.free_delivery"}}`, want: "Free delivery across the Belaya Romashka district every morning"},
+		{name: "localize of a missing key", tmpl: `{{localize "en" "no.such.key"}}`, want: "no.such.key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("t").Funcs(templateFuncs(catalog, tt.currency, tt.dateLayout)).Parse(tt.tmpl))
+			var out strings.Builder
+			err := tmpl.Execute(&out, tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("rendered %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package httpapi
+
+import "net/http"
+
+// headAsGet lets uptime checks send HEAD to endpoints that only switch on GET. The handler runs
+// exactly as for GET, so the status and headers are the real ones, and headWriter drops the body.
+func headAsGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// WithContext is a shallow copy, so changing the method does not leak into the access log.
+		get := r.WithContext(r.Context())
+		get.Method = http.MethodGet
+		next.ServeHTTP(headWriter{ResponseWriter: w}, get)
+	})
+}
+
+// headWriter reports every write as successful without sending it, which is all HEAD needs.
+type headWriter struct {
+	http.ResponseWriter
+}
+
+// Write discards the body; the status is still implied as it would be for GET.
+func (w headWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
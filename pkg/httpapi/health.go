@@ -0,0 +1,84 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"bakery/pkg/order"
+)
+
+// readyTimeout bounds one readiness check, well inside the second or so probes usually allow.
+const readyTimeout = 2 * time.Second
+
+// healthReport is the answer of both probes; Checks is only filled in by /readyz.
+type healthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// livezEndpoint answers as long as the process can serve HTTP at all. It touches neither the
+// database nor the services, so a slow database never gets the process restarted; that is what
+// /readyz is for.
+func (s *Server) livezEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, http.StatusOK, healthReport{Status: "ok"})
+	})
+}
+
+// readyzEndpoint reports whether this instance should get traffic: not draining, the database
+// reachable with its schema in place, and both service goroutines answering a query within
+// readyTimeout. A query through a service also fails while its breaker is open, so an instance
+// whose storage keeps failing drops out of the load balancer until it recovers.
+func (s *Server) readyzEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.draining.Load() {
+			writeHealth(w, http.StatusServiceUnavailable, healthReport{Status: "draining"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		defer cancel()
+
+		checks := map[string]error{
+			"orders": func() error {
+				_, err := s.orders.ListPage(ctx, order.Page{Limit: 1})
+				return err
+			}(),
+			"inventory": func() error {
+				_, err := s.inventory.Categories(ctx)
+				return err
+			}(),
+		}
+		if s.readyCheck != nil {
+			checks["database"] = s.readyCheck(ctx)
+		}
+		report := healthReport{Status: "ready", Checks: make(map[string]string, len(checks))}
+		status := http.StatusOK
+		for name, err := range checks {
+			report.Checks[name] = "ok"
+			if err != nil {
+				report.Checks[name] = err.Error()
+				report.Status, status = "not ready", http.StatusServiceUnavailable
+			}
+		}
+		if status != http.StatusOK {
+			s.logger.Printf("readiness check failed: %v", report.Checks)
+		}
+		writeHealth(w, status, report)
+	})
+}
+
+// Drain makes /readyz fail from now on, so load balancers stop sending traffic before the
+// listener closes. Shutdown calls it first and waits out the drain delay before closing.
+func (s *Server) Drain() {
+	s.draining.Store(true)
+}
+
+// writeHealth sends a probe answer that no cache may keep.
+func writeHealth(w http.ResponseWriter, status int, report healthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"bakery/pkg/money"
+	"bakery/pkg/order"
+)
+
+// heroMenuEntry mirrors order.MenuItem but keeps Available optional so files can leave it out.
+type heroMenuEntry struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Price       *money.Money `json:"price"`
+	Image       string       `json:"image"`
+	Category    string       `json:"category"`
+	Available   *bool        `json:"available"`
+}
+
+// fallbackMenu returns the hero menu currently in effect. The built-in menu is translated into
+// lang; a -hero-menu file is shown as written, since its author chose the words.
+func (s *Server) fallbackMenu(lang string) []order.MenuItem {
+	menu := *s.heroMenu.Load()
+	if s.heroMenuPath != "" {
+		return menu
+	}
+	localized := make([]order.MenuItem, len(menu))
+	for i, item := range menu {
+		item.Name = s.catalog.Text(lang, item.Name)
+		item.Description = s.catalog.Text(lang, item.Description)
+		localized[i] = item
+	}
+	return localized
+}
+
+// ReloadHeroMenu reads the -hero-menu file again and swaps it in for the page renders that start
+// afterwards; renders already running keep the menu they loaded. A file that no longer parses
+// leaves the current menu in place and is reported, so a typo during editing never blanks the
+// storefront.
+func (s *Server) ReloadHeroMenu() error {
+	menu, err := loadHeroMenu(s.heroMenuPath, s.currency)
+	if err != nil {
+		return err
+	}
+	s.heroMenu.Store(&menu)
+	s.logger.Printf("hero menu reloaded with %d items", len(menu))
+	return nil
+}
+
+// loadHeroMenu reads the fallback menu shown when the catalog is empty or unreachable.
+// An empty path keeps the built-in menu. Unknown fields are rejected so a misspelt key fails at
+// startup instead of quietly blanking a card on the storefront. Prices are retagged with the
+// configured currency, since a bare "220" in the file reads as roubles.
+func loadHeroMenu(path, currency string) ([]order.MenuItem, error) {
+	if path == "" {
+		return defaultMenu(currency), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read hero menu: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	var entries []heroMenuEntry
+	if err := decoder.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("hero menu %s: expected a JSON array of menu items: %w", path, err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("hero menu %s: unexpected data after the menu array", path)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("hero menu %s: the menu needs at least one item", path)
+	}
+
+	menu := make([]order.MenuItem, 0, len(entries))
+	for i, entry := range entries {
+		entry.Name = strings.TrimSpace(entry.Name)
+		if entry.Name == "" {
+			return nil, fmt.Errorf("hero menu %s: item %d: name is required", path, i+1)
+		}
+		if entry.Price == nil {
+			return nil, fmt.Errorf("hero menu %s: item %d (%s): price is required", path, i+1, entry.Name)
+		}
+		item := order.MenuItem{
+			Name:        entry.Name,
+			Description: entry.Description,
+			Price:       money.In(int(entry.Price.Cents), currency),
+			Image:       entry.Image,
+			Category:    entry.Category,
+			Available:   entry.Available == nil || *entry.Available,
+		}
+		if item.Image == "" {
+			item.Image = imageForCategory(item.Category)
+		}
+		menu = append(menu, item)
+	}
+	return menu, nil
+}
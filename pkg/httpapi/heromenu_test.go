@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"bakery/pkg/i18n"
+)
+
+// Readers must always see one whole menu, the old or the new, while reloads swap it underneath them.
+// Run it with -race.
+func TestHeroMenuReloadWhileReading(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hero.json")
+	menus := map[int]string{
+		2: `[{"name": "Багет", "price": 120}, {"name": "Чиабатта", "price": 150}]`,
+		3: `[{"name": "Круассан", "price": 90}, {"name": "Синнабон", "price": 180}, {"name": "Эклер", "price": 110}]`,
+	}
+	if err := os.WriteFile(path, []byte(menus[2]), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv, _ := quietServer(t)
+	srv.heroMenuPath = path
+	if err := srv.ReloadHeroMenu(); err != nil {
+		t.Fatal(err)
+	}
+
+	const readers, reloads = 8, 200
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				menu := srv.fallbackMenu(i18n.DefaultLocale)
+				// A menu of two is all bread and a menu of three all pastry; a mix means a torn read.
+				first := menu[0].Name
+				for _, item := range menu {
+					if (item.Name == "Багет" || item.Name == "Чиабатта") != (first == "Багет" || first == "Чиабатта") {
+						t.Errorf("torn menu: %v", menu)
+						return
+					}
+				}
+				if _, ok := menus[len(menu)]; !ok {
+					t.Errorf("menu of %d items", len(menu))
+					return
+				}
+			}
+		}()
+	}
+	want := 2
+	for i := range reloads {
+		size := 2 + i%2
+		if i%10 == 9 {
+			// A broken file leaves the current menu in place.
+			os.WriteFile(path, []byte(`[{"name": `), 0o600)
+			if err := srv.ReloadHeroMenu(); err == nil {
+				t.Error("a broken hero menu reloaded without an error")
+			}
+			continue
+		}
+		if err := os.WriteFile(path, []byte(menus[size]), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.ReloadHeroMenu(); err != nil {
+			t.Fatal(err)
+		}
+		want = size
+	}
+	close(stop)
+	wg.Wait()
+	if got := len(srv.fallbackMenu(i18n.DefaultLocale)); got != want {
+		t.Errorf("final menu has %d items, want the last good reload's %d", got, want)
+	}
+}
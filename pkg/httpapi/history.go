@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bakery/pkg/events"
+	"bakery/pkg/inventory"
+	"bakery/pkg/order"
+)
+
+// tagActor records the client address on every request's context, so the inventory audit log and the
+// order timeline can say who made a change without each handler passing it along.
+func (s *Server) tagActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(events.WithActor(r.Context(), s.clientIP(r))))
+	})
+}
+
+// inventoryHistoryEndpoint answers GET /api/admin/inventory/{id}/history with the batch's audit
+// entries, oldest first. Deleted batches keep their history until retention drops it, so the id
+// is not checked against the current inventory.
+func (s *Server) inventoryHistoryEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			s.respondValidation(w, r, fieldError{Field: "id", Code: codeInvalid, Message: "invalid id"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		entries, err := s.inventory.History(ctx, id)
+		switch {
+		case errors.Is(err, inventory.ErrHistoryOff):
+			s.respondError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			s.logger.Printf("inventory history for %d failed: %v", id, err)
+			s.respondServiceError(w, err)
+			return
+		}
+		if entries == nil {
+			entries = []inventory.AuditEntry{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entries)
+	})
+}
+
+// orderEventsEndpoint answers GET /api/orders/{id}/events with the order's timeline, oldest first:
+// when it was created, paused, resumed, delivered or cancelled, and by whom when a request did it.
+// An order with no recorded events, including one that never existed, has an empty timeline.
+func (s *Server) orderEventsEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := s.orderID(w, r)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		list, err := s.timeline.Events(ctx, id)
+		switch {
+		case errors.Is(err, order.ErrTimelineOff):
+			s.respondError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			s.logger.Printf("order timeline for %d failed: %v", id, err)
+			s.respondServiceError(w, err)
+			return
+		}
+		if list == nil {
+			list = []events.Event{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	})
+}
@@ -0,0 +1,200 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"bakery/pkg/inventory"
+)
+
+// DefaultMaxImageBytes caps an uploaded photo when Options.MaxImageBytes is zero.
+const DefaultMaxImageBytes = 2 << 20
+
+// placeholderImage is embedded under public_html/images and stands in for batches without a photo.
+const placeholderImage = "placeholder.svg"
+
+// placeholderURL is what listings report for a batch that has no uploaded image.
+const placeholderURL = "/images/" + placeholderImage
+
+// imageTypes maps the sniffed content types accepted for uploads to the extension stored on disk.
+// The client's Content-Type is not trusted; http.DetectContentType reads the bytes themselves.
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// imageURL is the image a listing shows for a batch: its upload, or the placeholder.
+func imageURL(item inventory.Item) string {
+	if item.Image == "" {
+		return placeholderURL
+	}
+	return item.Image
+}
+
+// imageUploadEndpoint answers POST /api/admin/inventory/{id}/image with a multipart form whose "image"
+// field holds a JPEG, PNG, GIF or WebP photo. The file lands in the -image-dir directory as
+// inventory-<id>.<ext>, replacing any earlier photo of the batch, and the reply carries its URL.
+func (s *Server) imageUploadEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
+		if s.imageDir == "" {
+			s.respondError(w, "image uploads are disabled; start the server with -image-dir", http.StatusServiceUnavailable)
+			return
+		}
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			s.respondValidation(w, r, fieldError{Field: "id", Code: codeInvalid, Message: "invalid id"})
+			return
+		}
+		// The form framing adds a few hundred bytes around the file, so the body limit leaves room for it.
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxImageBytes+64<<10)
+		data, err := readUpload(r, s.maxImageBytes)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			switch {
+			case errors.As(err, &tooLarge), errors.Is(err, errImageTooLarge):
+				s.logger.Printf("image upload for inventory %d rejected: larger than %d bytes", id, s.maxImageBytes)
+				s.respondError(w, fmt.Sprintf("image is larger than %d KB", s.maxImageBytes>>10), http.StatusRequestEntityTooLarge)
+			case errors.Is(err, http.ErrMissingFile), errors.Is(err, http.ErrNotMultipart):
+				s.respondValidation(w, r, fieldError{Field: "image", Code: codeRequired, Message: "image is required"})
+			default:
+				s.logger.Printf("image upload for inventory %d failed: %v", id, err)
+				s.respondValidation(w, r, fieldError{Field: "image", Code: codeInvalid, Message: "unreadable upload"})
+			}
+			return
+		}
+		contentType := http.DetectContentType(data)
+		ext, ok := imageTypes[contentType]
+		if !ok {
+			s.logger.Printf("image upload for inventory %d rejected: %s", id, contentType)
+			s.respondError(w, "unsupported image type "+contentType+": use JPEG, PNG, GIF or WebP", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+		name := fmt.Sprintf("inventory-%d%s", id, ext)
+		if err := s.storeImage(ctx, id, name, data); err != nil {
+			if errors.Is(err, inventory.ErrNotFound) {
+				s.respondError(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			s.logger.Printf("image upload for inventory %d failed: %v", id, err)
+			s.respondServiceError(w, err)
+			return
+		}
+		// The version query changes on every upload, so browsers never keep showing the old photo.
+		url := "/images/" + name + "?v=" + strconv.FormatInt(s.clock.Now().Unix(), 10)
+		if err := s.inventory.SetImage(ctx, id, url); err != nil {
+			s.logger.Printf("image upload for inventory %d failed to record %s: %v", id, url, err)
+			s.respondServiceError(w, err)
+			return
+		}
+		s.logger.Printf("inventory item %d image set to %s (%s, %d bytes)", id, url, contentType, len(data))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"image":` + strconv.Quote(url) + "}\n"))
+	})
+}
+
+// errImageTooLarge reports a file part bigger than the limit even though the whole body fit.
+var errImageTooLarge = errors.New("image too large")
+
+// readUpload returns the bytes of the "image" form field, reading at most limit of them.
+func readUpload(r *http.Request, limit int64) ([]byte, error) {
+	if err := r.ParseMultipartForm(limit); err != nil {
+		return nil, err
+	}
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, errImageTooLarge
+	}
+	return data, nil
+}
+
+// storeImage writes the photo only after checking the batch exists, through a temporary file so a
+// reader never sees half an image, and removes the batch's photos saved under other extensions.
+func (s *Server) storeImage(ctx context.Context, id int64, name string, data []byte) error {
+	items, err := s.inventory.List(ctx)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, item := range items {
+		if item.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return inventory.ErrNotFound
+	}
+	tmp, err := os.CreateTemp(s.imageDir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	// CreateTemp makes the file private; photos are public, and a proxy may serve the directory directly.
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.imageDir, name)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	for _, ext := range imageTypes {
+		if other := fmt.Sprintf("inventory-%d%s", id, ext); other != name {
+			os.Remove(filepath.Join(s.imageDir, other))
+		}
+	}
+	return nil
+}
+
+// imagesEndpoint serves GET /images/{name}: the embedded placeholder, or an upload from -image-dir.
+// Upload URLs carry a version query, so the files can be cached for a long time.
+func (s *Server) imagesEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if name == placeholderImage {
+			w.Header().Set("Cache-Control", "public, max-age=86400")
+			http.ServeFileFS(w, r, uiFS, "public_html/images/"+placeholderImage)
+			return
+		}
+		// Only names this server wrote are served, which rules out dot files and path tricks.
+		if s.imageDir == "" || !strings.HasPrefix(name, "inventory-") || filepath.Base(name) != name {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		http.ServeFile(w, r, filepath.Join(s.imageDir, name))
+	})
+}
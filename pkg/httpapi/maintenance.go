@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maintenanceRetryAfter tells clients and crawlers how many seconds a deploy usually takes.
+const maintenanceRetryAfter = "120"
+
+// maintenancePath stays reachable during maintenance, otherwise nobody could switch it off again.
+const maintenancePath = "/api/admin/maintenance"
+
+// maintenanceState is swapped as a whole through an atomic pointer, so the middleware never sees
+// Enabled from one toggle and Reads from another.
+type maintenanceState struct {
+	Enabled bool `json:"enabled"`
+	// Reads keeps GET endpoints and the admin page up while writes and the storefront are down.
+	Reads bool `json:"reads"`
+}
+
+// Maintenance reports whether maintenance mode is on.
+func (s *Server) Maintenance() bool {
+	return s.maintenance.Load().Enabled
+}
+
+// SetMaintenance switches maintenance mode; reads decides whether GET endpoints stay available.
+func (s *Server) SetMaintenance(enabled, reads bool) {
+	s.maintenance.Store(&maintenanceState{Enabled: enabled, Reads: reads})
+	s.logger.Printf("maintenance mode enabled=%t reads=%t", enabled, reads)
+}
+
+// ToggleMaintenance flips maintenance mode for the signal handler, keeping the reads setting.
+func (s *Server) ToggleMaintenance() {
+	for {
+		current := s.maintenance.Load()
+		next := &maintenanceState{Enabled: !current.Enabled, Reads: current.Reads}
+		if s.maintenance.CompareAndSwap(current, next) {
+			s.logger.Printf("maintenance mode enabled=%t reads=%t", next.Enabled, next.Reads)
+			return
+		}
+	}
+}
+
+// maintenanceGate answers 503 while maintenance is on: a "back soon" page for the storefront and
+// JSON for the API. Probes and the maintenance switch itself always pass, and with Reads set so do
+// the admin page and every GET, HEAD and OPTIONS request to the API.
+func (s *Server) maintenanceGate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := s.maintenance.Load()
+		if !state.Enabled || probePaths[r.URL.Path] || r.URL.Path == maintenancePath {
+			next.ServeHTTP(w, r)
+			return
+		}
+		reading := r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions
+		// Photos are assets of the API listings, not pages, so they follow the reads setting.
+		storefront := !strings.HasPrefix(r.URL.Path, "/api/") && !strings.HasPrefix(r.URL.Path, "/images/") && r.URL.Path != "/admin"
+		if state.Reads && reading && !storefront {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", maintenanceRetryAfter)
+		if storefront && reading {
+			s.maintenancePage(w, r)
+			return
+		}
+		s.respondError(w, s.text(r, "down for maintenance"), http.StatusServiceUnavailable)
+	})
+}
+
+// maintenancePage renders the localized "back soon" page with a 503 so crawlers keep their index.
+func (s *Server) maintenancePage(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	err := s.maintenanceTmpl.Execute(&buf, struct{ Lang string }{Lang: s.locale(r)})
+	if err != nil {
+		s.logger.Printf("maintenance page failed to render: %v", err)
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	buf.WriteTo(w)
+}
+
+// maintenanceEndpoint reports the state on GET and changes it on POST with {"enabled":true}.
+// "reads" defaults to the current setting, so a deploy script only has to send "enabled".
+func (s *Server) maintenanceEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			current := s.maintenance.Load()
+			if s.requireJSON(w, r) {
+				return
+			}
+			payload := struct {
+				Enabled *bool `json:"enabled"`
+				Reads   *bool `json:"reads"`
+			}{}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+				s.logger.Printf("maintenance change failed: unable to decode payload: %v", err)
+				s.respondValidation(w, r, fieldError{Code: codeInvalidJSON, Message: "invalid JSON"})
+				return
+			}
+			if payload.Enabled == nil {
+				s.respondValidation(w, r, fieldError{Field: "enabled", Code: codeRequired, Message: "enabled is required"})
+				return
+			}
+			reads := current.Reads
+			if payload.Reads != nil {
+				reads = *payload.Reads
+			}
+			s.SetMaintenance(*payload.Enabled, reads)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.maintenance.Load())
+	})
+}
+
+// parseMaintenancePage loads the standalone page; it shares no layout with the themes on purpose,
+// so a broken theme cannot take the maintenance page down with it.
+func parseMaintenancePage(funcs template.FuncMap) (*template.Template, error) {
+	return template.New("maintenance.gohtml").Funcs(funcs).ParseFS(uiFS, "public_html/maintenance.gohtml")
+}
@@ -0,0 +1,15 @@
+package httpapi
+
+import "net/http"
+
+// metricsEndpoint serves the order latency histograms in the Prometheus text format. It reads
+// counters without going through the order service goroutine, so it still answers while that
+// goroutine is the thing being diagnosed.
+func (s *Server) metricsEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := s.orders.WriteMetrics(w); err != nil {
+			s.logger.Printf("metrics to %s cut short: %v", s.clientIP(r), err)
+		}
+	})
+}
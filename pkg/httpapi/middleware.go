@@ -0,0 +1,278 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// Access log formats accepted by Options.AccessLog.
+const (
+	AccessLogOff      = "off"
+	AccessLogCommon   = "common"
+	AccessLogCombined = "combined"
+	AccessLogJSON     = "json"
+)
+
+// probePaths are polled by health checks and scrapers every few seconds. They skip the access log,
+// where they would drown real traffic, and the in-flight limit, so an overloaded server still reports in.
+var probePaths = map[string]bool{
+	"/livez":   true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// statusRecorder remembers the status and body size a handler produced so the access log can report them.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the first status; later calls are ignored by net/http as well.
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the body bytes and implies a 200 when the handler never set a status.
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach flushing and deadlines on the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// accessEntry is one request as the JSON access log prints it.
+type accessEntry struct {
+	Time       string  `json:"time"`
+	Remote     string  `json:"remote"`
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Proto      string  `json:"proto"`
+	Status     int     `json:"status"`
+	Bytes      int64   `json:"bytes"`
+	DurationMS float64 `json:"duration_ms"`
+	Referer    string  `json:"referer,omitempty"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+}
+
+// validAccessLog rejects typos at startup instead of silently logging nothing.
+func validAccessLog(format string) error {
+	switch format {
+	case "", AccessLogOff, AccessLogCommon, AccessLogCombined, AccessLogJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown access log format %q: use common, combined, json, or off", format)
+}
+
+// accessLog wraps the mux so every request produces exactly one line with status, size, and duration.
+func (s *Server) accessLog(next http.Handler) http.Handler {
+	if s.accessFormat == "" || s.accessFormat == AccessLogOff {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if probePaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		started := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			// A handler that writes nothing still answers 200 once net/http finishes the response.
+			rec.status = http.StatusOK
+		}
+		s.logger.Print(s.formatAccess(r, rec, started, time.Since(started)))
+	})
+}
+
+// formatAccess renders the line in the configured format; durations trail the classic formats
+// so existing log parsers still read the standard fields.
+func (s *Server) formatAccess(r *http.Request, rec *statusRecorder, started time.Time, elapsed time.Duration) string {
+	host := s.clientIP(r)
+	uri := r.URL.RequestURI()
+	switch s.accessFormat {
+	case AccessLogJSON:
+		payload, err := json.Marshal(accessEntry{
+			Time:       started.UTC().Format(time.RFC3339Nano),
+			Remote:     host,
+			Method:     r.Method,
+			Path:       uri,
+			Proto:      r.Proto,
+			Status:     rec.status,
+			Bytes:      rec.bytes,
+			DurationMS: float64(elapsed.Microseconds()) / 1000,
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+		})
+		if err != nil {
+			return fmt.Sprintf("access log encoding failed: %v", err)
+		}
+		return string(payload)
+	case AccessLogCombined:
+		return fmt.Sprintf("%s - - [%s] %q %d %d %q %q %s",
+			host, started.Format("02/Jan/2006:15:04:05 -0700"), r.Method+" "+uri+" "+r.Proto,
+			rec.status, rec.bytes, r.Referer(), r.UserAgent(), elapsed.Round(time.Microsecond))
+	default:
+		return fmt.Sprintf("%s - - [%s] %q %d %d %s",
+			host, started.Format("02/Jan/2006:15:04:05 -0700"), r.Method+" "+uri+" "+r.Proto,
+			rec.status, rec.bytes, elapsed.Round(time.Microsecond))
+	}
+}
+
+// recoverPanics turns a handler panic into a logged stack trace and a JSON 500, so one bad request
+// gets a clean answer instead of a dropped connection.
+func (s *Server) recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// net/http uses this sentinel to abort a response on purpose; let it through untouched.
+				panic(recovered)
+			}
+			requestID := r.Header.Get("X-Request-ID")
+			if requestID == "" {
+				requestID = "-"
+			}
+			s.logger.Printf("panic serving %s %s (request %s): %v\n%s", r.Method, r.URL.Path, requestID, recovered, debug.Stack())
+			if rec.status != 0 {
+				// Part of the response is already on the wire; aborting is the only honest signal left.
+				panic(http.ErrAbortHandler)
+			}
+			s.respondError(rec, "internal server error", http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// longRunningRoutes stream their reply or import a whole backup, and bound their work with their own,
+// longer context.WithTimeout. The whole-request deadline would cut them off mid-stream, so
+// requestTimeout leaves them alone. Keys are "METHOD path"; HEAD counts as GET. Only the listings
+// stream, so an order submitted through POST /api/orders is still bounded.
+var longRunningRoutes = map[string]bool{
+	"GET /api/orders":              true,
+	"GET /api/admin/inventory":     true,
+	"GET /api/admin/orders/export": true,
+	"GET /api/admin/orders.ndjson": true,
+	"GET /api/admin/backup":        true,
+	"POST /api/admin/restore":      true,
+}
+
+// requestTimeout puts one deadline over the whole request, a safety net for handlers that forget
+// their own. The deadline goes on the request context, so a handler's shorter context.WithTimeout
+// still wins and the database and service calls below it give up once it passes. Nothing is
+// buffered: a reply started in time goes out as the handler writes and flushes it. A reply that only
+// starts after the deadline, usually the error from the abandoned call, is replaced by a JSON 503,
+// and so is silence from a handler that returns late without answering. Listings and exports that
+// stream are left to their own deadlines, see longRunningRoutes.
+func (s *Server) requestTimeout(next http.Handler) http.Handler {
+	if s.requestDeadline <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.Method
+		if method == http.MethodHead {
+			method = http.MethodGet
+		}
+		if longRunningRoutes[method+" "+r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), s.requestDeadline)
+		defer cancel()
+		dw := &deadlineWriter{ResponseWriter: w, ctx: ctx}
+		next.ServeHTTP(dw, r.WithContext(ctx))
+		if !dw.started && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			dw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// deadlineWriter passes a response through unless the request's deadline had already passed when the
+// handler started it; then the client gets the timeout reply and the handler's output is dropped.
+type deadlineWriter struct {
+	http.ResponseWriter
+	ctx     context.Context
+	started bool
+	expired bool
+}
+
+// WriteHeader decides on the first call whether the handler's reply or the timeout goes out.
+func (w *deadlineWriter) WriteHeader(status int) {
+	if w.started {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.started = true
+	if !errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.expired = true
+	header := w.Header()
+	for _, key := range []string{"Content-Length", "Content-Disposition", "ETag", "Last-Modified", "Retry-After"} {
+		header.Del(key)
+	}
+	header.Set("Content-Type", "application/json")
+	header.Set("Cache-Control", "no-store")
+	w.ResponseWriter.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w.ResponseWriter).Encode(map[string]string{"error": "request timed out", "code": errorCodeTimeout})
+}
+
+// Write implies a 200 like net/http does and discards the body of a reply replaced by the timeout.
+func (w *deadlineWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.expired {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach flushing on the underlying writer.
+func (w *deadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// limitInflight caps concurrent requests with a buffered channel used as a semaphore. When every slot
+// is taken the request is refused at once with 503 rather than queued, so a promotion rush sheds load
+// instead of piling up goroutines that all time out later.
+func (s *Server) limitInflight(next http.Handler) http.Handler {
+	if s.maxInflight <= 0 {
+		return next
+	}
+	slots := make(chan struct{}, s.maxInflight)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if probePaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		default:
+			w.Header().Set("Retry-After", "1")
+			s.respondError(w, "server is busy, please retry", http.StatusServiceUnavailable)
+		}
+	})
+}
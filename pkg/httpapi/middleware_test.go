@@ -0,0 +1,228 @@
+package httpapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// quietServer is a Server with just enough set up to run the middleware on its own.
+func quietServer(t *testing.T) (*Server, *strings.Builder) {
+	t.Helper()
+	var logs strings.Builder
+	return &Server{logger: log.New(&logs, "", 0)}, &logs
+}
+
+func TestRecoverPanicsAnswers500AndKeepsServing(t *testing.T) {
+	srv, logs := quietServer(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		var stock map[string]int
+		stock["bread"]++ // assignment to a nil map
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "still here")
+	})
+	ts := httptest.NewServer(srv.recoverPanics(mux))
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/panic", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("panicking request dropped the connection: %v", err)
+	}
+	var body map[string]string
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if body["code"] != errorCodeInternal {
+		t.Errorf("code = %q, want %q", body["code"], errorCodeInternal)
+	}
+	if !strings.Contains(logs.String(), "request req-42") || !strings.Contains(logs.String(), "goroutine") {
+		t.Errorf("log lacks the request id or the stack trace:\n%s", logs.String())
+	}
+
+	resp, err = http.Get(ts.URL + "/ok")
+	if err != nil {
+		t.Fatalf("server stopped answering after a panic: %v", err)
+	}
+	text, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(text) != "still here" {
+		t.Errorf("follow-up request = %d %q, want 200 \"still here\"", resp.StatusCode, text)
+	}
+}
+
+func TestLimitInflightRefusesWhenSaturated(t *testing.T) {
+	srv, _ := quietServer(t)
+	srv.maxInflight = 2
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {})
+	handler := srv.limitInflight(mux)
+
+	// Fill every slot with a request parked inside the handler.
+	done := make(chan int, srv.maxInflight)
+	for range srv.maxInflight {
+		go func() {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+			done <- rec.Code
+		}()
+		<-entered
+	}
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/slow", http.StatusServiceUnavailable},
+		{"/metrics", http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s while saturated: status = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+		}
+		if tt.wantStatus == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") == "" {
+			t.Errorf("%s while saturated: no Retry-After header", tt.path)
+		}
+	}
+
+	close(release)
+	for range srv.maxInflight {
+		if code := <-done; code != http.StatusOK {
+			t.Errorf("parked request finished with %d, want 200", code)
+		}
+	}
+
+	// Released slots are usable again.
+	go func() { <-entered }()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("after release: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	srv, _ := quietServer(t)
+	srv.requestDeadline = 20 * time.Millisecond
+	mux := http.NewServeMux()
+	// /late waits out the deadline and then reports the abandoned call, as a handler does when its
+	// service gives up; /silent does the same but never answers.
+	mux.HandleFunc("/late", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.Header().Set("Content-Disposition", `attachment; filename="x.csv"`)
+		srv.respondError(w, r.Context().Err().Error(), http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/silent", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("/quick", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("/quick ran without a deadline")
+		}
+		io.WriteString(w, "fresh bread")
+	})
+	// An exempt listing outlives the deadline and still answers in full. Only its GET streams, so
+	// any other method keeps the deadline.
+	mux.HandleFunc("/api/admin/orders.ndjson", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); ok == (r.Method == http.MethodGet) {
+			t.Errorf("%s of the ndjson export: deadline set = %v", r.Method, ok)
+		}
+		time.Sleep(2 * srv.requestDeadline)
+		io.WriteString(w, "{}\n")
+	})
+	handler := srv.requestTimeout(mux)
+
+	tests := []struct {
+		method, path string
+		wantStatus   int
+		wantBody     string
+	}{
+		{http.MethodGet, "/late", http.StatusServiceUnavailable, errorCodeTimeout},
+		{http.MethodGet, "/silent", http.StatusServiceUnavailable, errorCodeTimeout},
+		{http.MethodGet, "/quick", http.StatusOK, "fresh bread"},
+		{http.MethodGet, "/api/admin/orders.ndjson", http.StatusOK, "{}"},
+		{http.MethodPost, "/api/admin/orders.ndjson", http.StatusServiceUnavailable, errorCodeTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("got %d %q, want %d containing %q", rec.Code, rec.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+			if rec.Code == http.StatusServiceUnavailable {
+				if got := rec.Header().Get("Content-Type"); got != "application/json" {
+					t.Errorf("Content-Type = %q, want application/json", got)
+				}
+				if rec.Header().Get("Content-Disposition") != "" {
+					t.Error("the timeout reply kept the handler's Content-Disposition")
+				}
+			}
+		})
+	}
+}
+
+// The deadline must not buffer: a handler that flushes part of its reply has it on the wire at once.
+func TestRequestTimeoutDoesNotBuffer(t *testing.T) {
+	srv, _ := quietServer(t)
+	srv.requestDeadline = time.Minute
+	release := make(chan struct{})
+	ts := httptest.NewServer(srv.requestTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "first\n")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("flush: %v", err)
+		}
+		<-release
+		io.WriteString(w, "second\n")
+	})))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		close(release)
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	lines := bufio.NewReader(resp.Body)
+	got := make(chan string, 1)
+	go func() {
+		line, _ := lines.ReadString('\n')
+		got <- line
+	}()
+	select {
+	case line := <-got:
+		if line != "first\n" {
+			t.Errorf("first line = %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the flushed line never arrived while the handler was still running")
+	}
+	close(release)
+	rest, _ := io.ReadAll(lines)
+	if string(rest) != "second\n" {
+		t.Errorf("rest = %q, want %q", rest, "second\n")
+	}
+}
@@ -0,0 +1,41 @@
+package httpapi
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxQuantity bounds every count a client sends. Real trays and orders stay far below it, and the
+// cap keeps stock and total arithmetic well away from int overflow.
+const maxQuantity = 100000
+
+// parseQuantity reads a count that arrived as JSON number text, so "2.5" and "1e9" are seen as
+// written instead of failing the whole decode or being truncated. Whole numbers in exponent form
+// such as "1e2" are accepted. An empty value reads as zero and sign checks are left to the caller,
+// so a missing or negative count gets the usual "must be positive" message; very negative values
+// are clamped rather than wrapped.
+func parseQuantity(field, raw string) (int, *fieldError) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if n > maxQuantity {
+			return 0, &fieldError{Field: field, Code: codeInvalid, Message: "quantity is too large"}
+		}
+		return int(max(n, math.MinInt32)), nil
+	}
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, &fieldError{Field: field, Code: codeInvalid, Message: "quantity must be a whole number"}
+	}
+	if f != math.Trunc(f) {
+		return 0, &fieldError{Field: field, Code: codeInvalid, Message: "quantity must be a whole number"}
+	}
+	if f > maxQuantity {
+		return 0, &fieldError{Field: field, Code: codeInvalid, Message: "quantity is too large"}
+	}
+	return int(max(f, math.MinInt32)), nil
+}
@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		raw      string
+		want     int
+		wantCode string
+		wantMsg  string
+	}{
+		{"2", 2, "", ""},
+		{" 12 ", 12, "", ""},
+		{"1e2", 100, "", ""},
+		{"", 0, "", ""},
+		{"100000", maxQuantity, "", ""},
+		// Signs are left to the caller's "must be positive" check; huge negatives are clamped, not wrapped.
+		{"-3", -3, "", ""},
+		{"-2.0", -2, "", ""},
+		{"-99999999999999999999", math.MinInt32, "", ""},
+		{"-1e30", math.MinInt32, "", ""},
+		{"2.5", 0, codeInvalid, "quantity must be a whole number"},
+		{"-2.5", 0, codeInvalid, "quantity must be a whole number"},
+		{"1e-3", 0, codeInvalid, "quantity must be a whole number"},
+		{"1e9", 0, codeInvalid, "quantity is too large"},
+		{"100001", 0, codeInvalid, "quantity is too large"},
+		{"99999999999999999999", 0, codeInvalid, "quantity is too large"},
+		{"1e400", 0, codeInvalid, "quantity is too large"},
+		{"two", 0, codeInvalid, "quantity must be a whole number"},
+	}
+	for _, tt := range tests {
+		got, fe := parseQuantity("items[0].quantity", tt.raw)
+		if tt.wantCode == "" {
+			if fe != nil || got != tt.want {
+				t.Errorf("parseQuantity(%q) = %d, %v; want %d", tt.raw, got, fe, tt.want)
+			}
+			continue
+		}
+		if fe == nil || fe.Code != tt.wantCode || fe.Message != tt.wantMsg || fe.Field != "items[0].quantity" {
+			t.Errorf("parseQuantity(%q) error = %+v, want %s %q on items[0].quantity", tt.raw, fe, tt.wantCode, tt.wantMsg)
+		}
+	}
+}
+
+// The inventory form goes through the same parser and then refuses counts below one.
+func TestInventoryPayloadQuantity(t *testing.T) {
+	tests := []struct {
+		raw      string
+		want     int
+		wantCode string
+	}{
+		{"24", 24, ""},
+		{"2.5", 0, codeInvalid},
+		{"1e9", 0, codeInvalid},
+		{"-3", 0, codeNotPositive},
+		{"0", 0, codeNotPositive},
+	}
+	rules := newCategoryRules(DefaultCategories, false)
+	for _, tt := range tests {
+		payload := inventoryPayload{Name: "Багет", Category: DefaultCategories[0], BakedAtRaw: "2026-10-15 06:00", PriceRaw: "120", QuantityRaw: tt.raw}
+		err := payload.Validate(rules)
+		if tt.wantCode == "" {
+			if err != nil || payload.Quantity != tt.want {
+				t.Errorf("quantity %q: got %d, %v; want %d", tt.raw, payload.Quantity, err, tt.want)
+			}
+			continue
+		}
+		if fe := asFieldError(err); err == nil || fe.Code != tt.wantCode || fe.Field != "quantity" {
+			t.Errorf("quantity %q: error = %v, want %s on quantity", tt.raw, err, tt.wantCode)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// orderHours is the daily window in which POST /api/orders is accepted, in minutes after local midnight.
+// A window whose end is before its start runs overnight, e.g. 22:00-02:00. The zero value is always open.
+type orderHours struct {
+	set         bool
+	open, close int
+}
+
+// parseOrderHours reads the -order-hours flag, "HH:MM-HH:MM"; an empty value disables the gate.
+func parseOrderHours(raw string) (orderHours, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return orderHours{}, nil
+	}
+	from, to, ok := strings.Cut(raw, "-")
+	if !ok {
+		return orderHours{}, fmt.Errorf("invalid order hours %q: want HH:MM-HH:MM", raw)
+	}
+	open, err := parseClock(from)
+	if err != nil {
+		return orderHours{}, fmt.Errorf("invalid order hours %q: %w", raw, err)
+	}
+	closing, err := parseClock(to)
+	if err != nil {
+		return orderHours{}, fmt.Errorf("invalid order hours %q: %w", raw, err)
+	}
+	if open == closing {
+		return orderHours{}, fmt.Errorf("invalid order hours %q: the window is empty", raw)
+	}
+	return orderHours{set: true, open: open, close: closing}, nil
+}
+
+// parseClock turns "HH:MM" into minutes after midnight.
+func parseClock(raw string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(raw))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", raw)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// allows reports whether an order placed at now falls inside the window, judged in now's time zone.
+func (h orderHours) allows(now time.Time) bool {
+	if !h.set {
+		return true
+	}
+	minute := now.Hour()*60 + now.Minute()
+	if h.open < h.close {
+		return minute >= h.open && minute < h.close
+	}
+	return minute >= h.open || minute < h.close
+}
+
+// reopens returns the next opening time after now, today's when it is still ahead and tomorrow's otherwise.
+func (h orderHours) reopens(now time.Time) time.Time {
+	year, month, day := now.Date()
+	opening := time.Date(year, month, day, h.open/60, h.open%60, 0, 0, now.Location())
+	if !opening.After(now) {
+		opening = opening.AddDate(0, 0, 1)
+	}
+	return opening
+}
+
+// orderingClosed answers 403 to submissions outside the order hours and reports whether it did.
+// Browsing stays open; only placing orders is gated.
+func (s *Server) orderingClosed(w http.ResponseWriter, r *http.Request) bool {
+	now := s.clock.Now()
+	if s.orderHours.allows(now) {
+		return false
+	}
+	reopens := s.orderHours.reopens(now)
+	s.logger.Printf("order rejected outside order hours from %s; ordering reopens at %s", s.clientIP(r), reopens.Format(time.RFC3339))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error":      s.text(r, "ordering is closed now; it reopens at") + " " + reopens.Format("02.01.2006 15:04"),
+		"reopens_at": reopens.Format(time.RFC3339),
+		"code":       errorCodeForbidden,
+	})
+	return true
+}
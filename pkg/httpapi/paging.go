@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bakery/pkg/order"
+)
+
+// orderPage reads ?limit=, ?offset=, ?status= and ?sort= from an order listing. All are optional; a
+// missing limit keeps the old behaviour of returning every order so existing dashboards see no
+// change, and a missing sort keeps them newest first. Status and sort both go into the query, so a
+// status nothing has yet is an empty page.
+func (s *Server) orderPage(w http.ResponseWriter, r *http.Request) (order.Page, bool) {
+	var (
+		page     order.Page
+		problems []fieldError
+	)
+	values := r.URL.Query()
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{{"limit", &page.Limit}, {"offset", &page.Offset}} {
+		raw := strings.TrimSpace(values.Get(param.name))
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			problems = append(problems, fieldError{Field: param.name, Code: codeInvalid, Message: param.name + " must be a non-negative integer"})
+			continue
+		}
+		*param.dst = n
+	}
+	if raw := values.Get("status"); raw != "" {
+		status, ok := order.ParseStatus(raw)
+		if !ok {
+			problems = append(problems, fieldError{Field: "status", Code: codeInvalid, Message: "status must be new, pending, delivered or cancelled"})
+		}
+		page.Status = status
+	}
+	if raw := values.Get("sort"); raw != "" {
+		sort, ok := order.ParseSort(raw)
+		if !ok {
+			problems = append(problems, fieldError{Field: "sort", Code: codeInvalid, Message: "sort must be id, created_at or status, optionally followed by :asc or :desc"})
+		}
+		page.Sort = sort
+	}
+	if len(problems) > 0 {
+		s.logger.Printf("order listing rejected: invalid paging %q", r.URL.RawQuery)
+		s.respondValidation(w, r, problems...)
+		return order.Page{}, false
+	}
+	return page, true
+}
+
+// pageOrders applies a page to orders that had to be filtered in memory.
+func pageOrders(orders []order.Order, page order.Page) []order.Order {
+	if page.Offset >= len(orders) {
+		return []order.Order{}
+	}
+	orders = orders[page.Offset:]
+	if page.Limit > 0 && page.Limit < len(orders) {
+		orders = orders[:page.Limit]
+	}
+	return orders
+}
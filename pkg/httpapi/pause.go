@@ -0,0 +1,88 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bakery/pkg/order"
+)
+
+// pauseEndpoint pauses one order's deliveries, e.g. while the household is on vacation.
+// The body is optional: {"until":"2006-01-02"} resumes on that date, no body pauses until /resume.
+func (s *Server) pauseEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
+		id, ok := s.orderID(w, r)
+		if !ok {
+			return
+		}
+		if s.requireJSON(w, r) {
+			return
+		}
+		var payload struct {
+			Until string `json:"until"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			s.logger.Printf("order pause failed: unable to decode payload: %v", err)
+			s.respondValidation(w, r, fieldError{Code: codeInvalidJSON, Message: "invalid JSON"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		updated, err := s.orders.Pause(ctx, id, payload.Until)
+		s.respondOrderChange(w, r, "pause", id, updated, err)
+	})
+}
+
+// resumeEndpoint lifts a pause so the next generation run plans deliveries again.
+func (s *Server) resumeEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
+		id, ok := s.orderID(w, r)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		updated, err := s.orders.Resume(ctx, id)
+		s.respondOrderChange(w, r, "resume", id, updated, err)
+	})
+}
+
+// orderID reads the {id} path segment, answering 400 itself when it is not a positive number.
+func (s *Server) orderID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		s.respondValidation(w, r, fieldError{Field: "id", Code: codeInvalid, Message: "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondOrderChange maps the outcome of a change to one order, such as a pause, onto the HTTP response.
+func (s *Server) respondOrderChange(w http.ResponseWriter, r *http.Request, action string, id int64, updated order.Order, err error) {
+	switch {
+	case err == nil:
+		s.logger.Printf("order %d: %s done", id, action)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(updated)
+	case errors.Is(err, order.ErrNotFound):
+		s.logger.Printf("order %s failed: order %d not found", action, id)
+		s.respondError(w, err.Error(), http.StatusNotFound)
+	case order.IsValidation(err):
+		s.logger.Printf("order %s rejected for %d: %v", action, id, err)
+		s.respondValidation(w, r, orderFieldErrors(err)...)
+	default:
+		s.logger.Printf("order %s failed for %d: %v", action, id, err)
+		s.respondServiceError(w, err)
+	}
+}
@@ -0,0 +1,88 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bakery/pkg/inventory"
+)
+
+// priceAdjustEndpoint answers POST /api/admin/inventory/price-adjust so a promotion like
+// {"category":"croissant","percent":-10} or {"category":"bread","delta_rub":"-20"} reprices every
+// batch in the category at once. Exactly one of percent and delta_rub is required, and the reply
+// reports how many batches changed.
+func (s *Server) priceAdjustEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
+		if s.requireJSON(w, r) {
+			return
+		}
+		var payload priceAdjustPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			s.logger.Printf("price adjustment failed: unable to decode payload: %v", err)
+			s.respondValidation(w, r, fieldError{Code: codeInvalidJSON, Message: "invalid JSON"})
+			return
+		}
+		adj, err := payload.adjustment(s.categories)
+		if err != nil {
+			s.logger.Printf("price adjustment rejected: %v", err)
+			s.respondValidation(w, r, asFieldError(err))
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+
+		affected, err := s.inventory.AdjustPrices(ctx, adj)
+		if err != nil {
+			if errors.Is(err, inventory.ErrNegativePrice) {
+				s.logger.Printf("price adjustment rejected for %s: %v", adj.Category, err)
+				s.respondValidation(w, r, fieldError{Field: "category", Code: codeInvalid, Message: "price adjustment would make a price negative"})
+				return
+			}
+			s.logger.Printf("price adjustment failed for %s: %v", adj.Category, err)
+			s.respondServiceError(w, err)
+			return
+		}
+		s.logger.Printf("prices in %s adjusted by %+.2f%% and %+d cents on %d batches", adj.Category, adj.Percent, adj.DeltaCents, affected)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]int64{"affected": affected})
+	})
+}
+
+// priceAdjustPayload keeps percent as a pointer so an explicit 0 is told apart from a missing field.
+type priceAdjustPayload struct {
+	Category string   `json:"category"`
+	Percent  *float64 `json:"percent"`
+	DeltaRaw string   `json:"delta_rub"`
+}
+
+// adjustment validates the payload the way inventoryPayload.Validate does for single batches.
+func (p priceAdjustPayload) adjustment(categories categoryRules) (inventory.PriceAdjustment, error) {
+	category, err := categories.check(p.Category)
+	if err != nil {
+		return inventory.PriceAdjustment{}, err
+	}
+	delta := strings.TrimSpace(p.DeltaRaw)
+	switch {
+	case p.Percent == nil && delta == "":
+		return inventory.PriceAdjustment{}, fieldError{Field: "percent", Code: codeRequired, Message: "percent or delta_rub is required"}
+	case p.Percent != nil && delta != "":
+		return inventory.PriceAdjustment{}, fieldError{Field: "percent", Code: codeInvalid, Message: "send either percent or delta_rub, not both"}
+	case p.Percent != nil:
+		return inventory.PriceAdjustment{Category: category, Percent: *p.Percent}, nil
+	}
+	rub, err := strconv.ParseFloat(strings.ReplaceAll(delta, ",", "."), 64)
+	if err != nil {
+		return inventory.PriceAdjustment{}, fieldError{Field: "delta_rub", Code: codeInvalid, Message: fmt.Sprintf("invalid delta_rub: %v", err)}
+	}
+	return inventory.PriceAdjustment{Category: category, DeltaCents: int(math.Round(rub * 100))}, nil
+}
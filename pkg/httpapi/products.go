@@ -0,0 +1,197 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bakery/pkg/money"
+	"bakery/pkg/product"
+)
+
+// productsEndpoint lets the owner curate the catalog that the menu is built from.
+func (s *Server) productsEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			s.createProduct(w, r)
+		case http.MethodPut:
+			s.updateProduct(w, r)
+		case http.MethodDelete:
+			s.deleteProduct(w, r)
+		case http.MethodGet:
+			s.listProducts(w, r)
+		default:
+			s.methodNotAllowed(w, r, http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete)
+		}
+	})
+}
+
+// createProduct adds a catalog entry that stays on the menu regardless of stock.
+func (s *Server) createProduct(w http.ResponseWriter, r *http.Request) {
+	if s.requireJSON(w, r) {
+		return
+	}
+	var payload productPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		s.logger.Printf("product creation failed: unable to decode payload: %v", err)
+		s.respondValidation(w, r, fieldError{Code: codeInvalidJSON, Message: "invalid JSON"})
+		return
+	}
+	if err := payload.Validate(s.categories); err != nil {
+		s.logger.Printf("product creation rejected: %v", err)
+		s.respondValidation(w, r, asFieldError(err))
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	stored, err := s.products.Add(ctx, payload.product())
+	if err != nil {
+		s.logger.Printf("product creation failed for %s: %v", payload.Name, err)
+		s.respondServiceError(w, err)
+		return
+	}
+	s.logger.Printf("product %s added to the catalog as %d", stored.Name, stored.ID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stored)
+}
+
+// updateProduct replaces an existing catalog entry identified by id.
+func (s *Server) updateProduct(w http.ResponseWriter, r *http.Request) {
+	if s.requireJSON(w, r) {
+		return
+	}
+	var payload productPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		s.logger.Printf("product update failed: unable to decode payload: %v", err)
+		s.respondValidation(w, r, fieldError{Code: codeInvalidJSON, Message: "invalid JSON"})
+		return
+	}
+	if payload.ID == 0 {
+		s.logger.Printf("product update rejected: missing id")
+		s.respondValidation(w, r, fieldError{Field: "id", Code: codeRequired, Message: "id is required"})
+		return
+	}
+	if err := payload.Validate(s.categories); err != nil {
+		s.logger.Printf("product update rejected for id %d: %v", payload.ID, err)
+		s.respondValidation(w, r, asFieldError(err))
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := s.products.Update(ctx, payload.product()); err != nil {
+		if errors.Is(err, product.ErrNotFound) {
+			s.logger.Printf("product update failed: %d not found", payload.ID)
+			s.respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.logger.Printf("product update failed for %d: %v", payload.ID, err)
+		s.respondServiceError(w, err)
+		return
+	}
+	s.logger.Printf("product %d updated", payload.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteProduct removes a catalog entry using the query id.
+func (s *Server) deleteProduct(w http.ResponseWriter, r *http.Request) {
+	rawID := r.URL.Query().Get("id")
+	if rawID == "" {
+		s.logger.Printf("product delete rejected: missing id")
+		s.respondValidation(w, r, fieldError{Field: "id", Code: codeRequired, Message: "id is required"})
+		return
+	}
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		s.logger.Printf("product delete rejected: invalid id %s", rawID)
+		s.respondValidation(w, r, fieldError{Field: "id", Code: codeInvalid, Message: "invalid id"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := s.products.Delete(ctx, id); err != nil {
+		if errors.Is(err, product.ErrNotFound) {
+			s.logger.Printf("product delete failed: %d not found", id)
+			s.respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.logger.Printf("product delete failed for %d: %v", id, err)
+		s.respondServiceError(w, err)
+		return
+	}
+	s.logger.Printf("product %d deleted", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listProducts sends the full catalog for the admin console.
+func (s *Server) listProducts(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	products, err := s.products.List(ctx)
+	if err != nil {
+		s.logger.Printf("product listing failed: %v", err)
+		s.respondServiceError(w, err)
+		return
+	}
+	if products == nil {
+		products = []product.Product{}
+	}
+	s.logger.Printf("product listing served with %d records", len(products))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(products)
+}
+
+// productPayload mirrors inventoryPayload so the admin sends prices in roubles for both resources.
+type productPayload struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	PriceRaw    string `json:"price_rub"`
+	Image       string `json:"image"`
+	PriceCents  int    `json:"-"`
+}
+
+// Validate checks the required fields and parses the base price.
+func (p *productPayload) Validate(categories categoryRules) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fieldError{Field: "name", Code: codeRequired, Message: "name is required"}
+	}
+	category, err := categories.check(p.Category)
+	if err != nil {
+		return err
+	}
+	p.Category = category
+	if strings.TrimSpace(p.PriceRaw) == "" {
+		return fieldError{Field: "price_rub", Code: codeRequired, Message: "price_rub is required"}
+	}
+	price, err := money.Parse(p.PriceRaw)
+	if err != nil || price.Cents < 0 {
+		return fieldError{Field: "price_rub", Code: codeInvalid, Message: "invalid price_rub"}
+	}
+	p.PriceCents = int(price.Cents)
+	return nil
+}
+
+// product converts the validated payload into the domain type.
+func (p productPayload) product() product.Product {
+	return product.Product{
+		ID:             p.ID,
+		Name:           strings.TrimSpace(p.Name),
+		Description:    strings.TrimSpace(p.Description),
+		Category:       strings.TrimSpace(p.Category),
+		BasePriceCents: p.PriceCents,
+		Image:          strings.TrimSpace(p.Image),
+	}
+}
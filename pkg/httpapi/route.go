@@ -0,0 +1,88 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"bakery/pkg/order"
+)
+
+// routeStop is one doorstep on the morning run with everything the driver drops there.
+type routeStop struct {
+	OrderID    int64            `json:"order_id"`
+	Name       string           `json:"name"`
+	Address    string           `json:"address"`
+	Phone      string           `json:"phone"`
+	Location   *order.Point     `json:"location,omitempty"`
+	Deliveries []order.Delivery `json:"deliveries"`
+}
+
+// routeEndpoint answers GET /api/admin/route?date=YYYY-MM-DD with the day's deliveries grouped per order
+// and ordered by order.PlanRoute from the depot. Orders without coordinates come last in submission order.
+func (s *Server) routeEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			s.methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		date, ok := s.deliveryDate(w, r, "date", s.clock.Now().UTC().Format(deliveryDateLayout))
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		deliveries, err := s.orders.Deliveries(ctx, date, date)
+		if err != nil {
+			s.logger.Printf("route planning failed for %s: %v", date, err)
+			s.respondServiceError(w, err)
+			return
+		}
+		orders, err := s.orders.List(ctx)
+		if err != nil {
+			s.logger.Printf("route planning failed for %s: %v", date, err)
+			s.respondServiceError(w, err)
+			return
+		}
+		byID := make(map[int64]order.Order, len(orders))
+		for _, o := range orders {
+			byID[o.ID] = o
+		}
+
+		stopIndex := make(map[int64]int)
+		var stops []routeStop
+		for _, delivery := range deliveries {
+			i, seen := stopIndex[delivery.OrderID]
+			if !seen {
+				o, exists := byID[delivery.OrderID]
+				if !exists {
+					continue
+				}
+				stop := routeStop{OrderID: o.ID, Name: o.CustomerName, Address: o.Address, Phone: o.Phone}
+				if o.Lat != nil && o.Lng != nil {
+					stop.Location = &order.Point{Lat: *o.Lat, Lng: *o.Lng}
+				}
+				i = len(stops)
+				stopIndex[delivery.OrderID] = i
+				stops = append(stops, stop)
+			}
+			stops[i].Deliveries = append(stops[i].Deliveries, delivery)
+		}
+		// Order ids grow with submission, which makes them the fallback order PlanRoute preserves.
+		sort.Slice(stops, func(a, b int) bool { return stops[a].OrderID < stops[b].OrderID })
+
+		points := make([]*order.Point, len(stops))
+		for i := range stops {
+			points[i] = stops[i].Location
+		}
+		route := make([]routeStop, 0, len(stops))
+		for _, i := range order.PlanRoute(s.depot, points) {
+			route = append(route, stops[i])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(route)
+	})
+}
@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// route pairs a path with the methods its handler switches on. Handler registers every path from
+// this one table, so OPTIONS replies and Allow headers cannot drift from what the handler accepts.
+type route struct {
+	pattern string
+	methods []string
+	handler http.Handler
+}
+
+// routes lists every endpoint with the methods it serves. HEAD and OPTIONS are implied: HEAD wherever
+// GET is served, OPTIONS everywhere.
+func (s *Server) routes() []route {
+	return []route{
+		{"/{$}", []string{http.MethodGet}, s.pageHandler("customer")},
+		{"/admin", []string{http.MethodGet}, s.pageHandler("admin")},
+		{"/api/orders", []string{http.MethodGet, http.MethodPost}, s.ordersEndpoint()},
+		{"/api/orders/{id}/pause", []string{http.MethodPatch}, s.pauseEndpoint()},
+		{"/api/orders/{id}/resume", []string{http.MethodPatch}, s.resumeEndpoint()},
+		{"/api/orders/{id}/status", []string{http.MethodPatch}, s.statusEndpoint()},
+		{"/api/orders/{id}/events", []string{http.MethodGet}, s.orderEventsEndpoint()},
+		{"/api/menu", []string{http.MethodGet}, s.menuEndpoint()},
+		{"/api/menu/categories", []string{http.MethodGet}, s.categoriesEndpoint()},
+		{"/api/menu/summary", []string{http.MethodGet}, s.menuSummaryEndpoint()},
+		{"/api/admin/inventory", []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}, s.inventoryEndpoint()},
+		{"/api/admin/inventory/price-adjust", []string{http.MethodPost}, s.priceAdjustEndpoint()},
+		{"/api/admin/inventory/{id}/image", []string{http.MethodPost}, s.imageUploadEndpoint()},
+		{"/api/admin/inventory/{id}/history", []string{http.MethodGet}, s.inventoryHistoryEndpoint()},
+		{"/api/admin/inventory/{id}/feature", []string{http.MethodPatch}, s.featureEndpoint()},
+		{"/api/admin/products", []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}, s.productsEndpoint()},
+		{"/api/admin/config", []string{http.MethodGet}, s.adminConfigEndpoint()},
+		{"/api/admin/deliveries", []string{http.MethodGet}, s.deliveriesEndpoint()},
+		{"/api/admin/route", []string{http.MethodGet}, s.routeEndpoint()},
+		{"/api/admin/orders/status", []string{http.MethodPost}, s.bulkStatusEndpoint()},
+		{"/api/admin/orders/export", []string{http.MethodGet}, s.orderExportEndpoint()},
+		{"/api/admin/orders.ndjson", []string{http.MethodGet}, s.orderSyncEndpoint()},
+		{"/api/admin/customers", []string{http.MethodGet}, s.customerSearchEndpoint()},
+		{"/api/admin/customers/{phone}/orders", []string{http.MethodGet}, s.customerOrdersEndpoint()},
+		{"/api/admin/backup", []string{http.MethodGet}, s.backupEndpoint()},
+		{"/api/admin/restore", []string{http.MethodPost}, s.restoreEndpoint()},
+		{"/api/admin/debug", []string{http.MethodGet}, s.debugEndpoint()},
+		{maintenancePath, []string{http.MethodGet, http.MethodPost}, s.maintenanceEndpoint()},
+		{"/api/schema/{name}", []string{http.MethodGet}, s.schemaEndpoint()},
+		{"/metrics", []string{http.MethodGet}, s.metricsEndpoint()},
+		{"/livez", []string{http.MethodGet}, s.livezEndpoint()},
+		{"/readyz", []string{http.MethodGet}, s.readyzEndpoint()},
+		{"/images/{name}", []string{http.MethodGet}, s.imagesEndpoint()},
+	}
+}
+
+// allow renders the Allow header value for the route, implied methods included.
+func (rt route) allow() string {
+	methods := slices.Clone(rt.methods)
+	if slices.Contains(methods, http.MethodGet) {
+		methods = append(methods, http.MethodHead)
+	}
+	methods = append(methods, http.MethodOptions)
+	return strings.Join(methods, ", ")
+}
+
+// serveRoute gates the handler on the route's methods. OPTIONS is answered here with 204 and the
+// Allow header, which also satisfies CORS preflights asking which methods exist; HEAD runs the GET
+// branch, and any other method gets methodNotAllowed.
+func (s *Server) serveRoute(rt route) http.Handler {
+	allow := rt.allow()
+	get := headAsGet(rt.handler)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodOptions:
+			w.Header().Set("Allow", allow)
+			if r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", allow)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodHead && slices.Contains(rt.methods, http.MethodGet):
+			get.ServeHTTP(w, r)
+		case slices.Contains(rt.methods, r.Method):
+			rt.handler.ServeHTTP(w, r)
+		default:
+			s.methodNotAllowed(w, r, rt.methods...)
+		}
+	})
+}
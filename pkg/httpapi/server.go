@@ -1,112 +1,459 @@
 package httpapi
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
+	"iter"
 	"log"
 	"net/http"
+	"net/netip"
 	"os"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"bakery/pkg/clock"
+	"bakery/pkg/customer"
+	"bakery/pkg/i18n"
 	"bakery/pkg/inventory"
+	"bakery/pkg/money"
 	"bakery/pkg/order"
+	"bakery/pkg/product"
 )
 
 // uiFS packs the single page experience so deployments ship one binary.
 // The assets live under public_html to satisfy the deployment expectation while keeping everything embedded.
 //
-//go:embed public_html/app.gohtml
+//go:embed public_html
 var uiFS embed.FS
 
+// DefaultTheme names the storefront look used when operators do not pick one explicitly.
+const DefaultTheme = "default"
+
+// themesDir holds one directory per theme, each providing its own app.gohtml layout.
+const themesDir = "public_html/themes"
+
+// pageTemplate is the layout every theme must define; the shared script partial is parsed alongside it.
+const pageTemplate = "app.gohtml"
+
+// menuLoadTimeout caps how long a page render waits for the live menu before using the hero menu.
+const menuLoadTimeout = 500 * time.Millisecond
+
+// devTemplateRoot is read instead of the embedded copy in dev mode, relative to the repository root.
+const devTemplateRoot = "pkg/httpapi"
+
+// Options carries presentation settings so new knobs do not keep widening the New signature.
+type Options struct {
+	Theme string
+	// Dev re-parses templates from devTemplateRoot on every page render so front-end edits need no rebuild.
+	Dev bool
+	// ReadOnly turns the instance into a replica that serves pages and listings but refuses every write.
+	ReadOnly bool
+	// AccessLog picks the per-request log line: common, combined, json, or off. Empty means off.
+	AccessLog string
+	// MaxInflight caps concurrently served requests; zero leaves them unlimited.
+	MaxInflight int
+	// Maintenance starts the server in maintenance mode; MaintenanceReads keeps GET endpoints up meanwhile.
+	Maintenance      bool
+	MaintenanceReads bool
+	// RequestTimeout bounds every request end to end with a 503; zero leaves requests to their handlers.
+	RequestTimeout time.Duration
+	// TrustedProxies lists CIDR ranges or addresses whose X-Forwarded-For header names the real client.
+	TrustedProxies []string
+	// HeroMenuPath points at a JSON array of menu items replacing the built-in fallback menu.
+	HeroMenuPath string
+	// Categories limits batch and product categories; empty means DefaultCategories.
+	Categories []string
+	// AllowCustomCategories accepts any category, still trimmed and lowercased.
+	AllowCustomCategories bool
+	// Depot is where the delivery route starts; nil starts at the earliest order with coordinates.
+	Depot *order.Point
+	// OrderHours limits order submission to a daily "HH:MM-HH:MM" window in the server's time zone.
+	// Empty accepts orders around the clock.
+	OrderHours string
+	// ImageDir stores uploaded batch photos served under /images/; empty disables uploads.
+	ImageDir string
+	// MaxImageBytes caps one uploaded photo; zero means DefaultMaxImageBytes.
+	MaxImageBytes int64
+	// DuplicateBatches is the policy for a batch matching a stored one; empty means DuplicateBatchesWarn.
+	DuplicateBatches string
+	// AdminRefresh is how often the admin page reloads inventory; zero leaves reloading to the button.
+	AdminRefresh time.Duration
+	// ConfirmationTemplate replaces the localized thank-you message sent with every new order;
+	// {name}, {date} and {id} are filled in. Empty uses the locale's text.
+	ConfirmationTemplate string
+	// Timeline serves GET /api/orders/{id}/events; nil answers 404 there.
+	Timeline *order.Timeline
+	// Customers serves GET /api/admin/customers/{phone}/orders; nil answers 404 there.
+	Customers *customer.Service
+	// AdminToken, when set, is the bearer token every /api/admin/ request must carry. Empty leaves the
+	// admin API open but does not serve backup and restore at all.
+	AdminToken string
+	// HSTSMaxAge is the Strict-Transport-Security lifetime sent on requests that came in over TLS;
+	// zero never sends the header.
+	HSTSMaxAge time.Duration
+	// Currency is the ISO 4217 code every stored price is in and decides how prices are written,
+	// such as "220,50 ₽" or "$220.50"; empty means RUB.
+	Currency string
+	// DateLayout is the Go time layout dates are written with on pages and in the order
+	// confirmation; empty uses each locale's own, such as 02.01.2006 for Russian.
+	DateLayout string
+	// AnyContentType lets JSON endpoints decode bodies whatever their Content-Type says, for
+	// clients that predate the 415 check; false refuses anything but application/json.
+	AnyContentType bool
+	// ReadyCheck, when set, is run by /readyz to confirm the database is reachable and its schema
+	// in place; nil leaves readiness to the services answering.
+	ReadyCheck func(context.Context) error
+	// Clock decides what "now" is for order hours, default delivery dates and backup names;
+	// nil uses the system clock, tests pass a fixed one.
+	Clock clock.Clock
+}
+
 // Server wires HTTP endpoints to the asynchronous order and inventory services.
 type Server struct {
 	orders    *order.Service
 	inventory *inventory.Service
+	products  *product.Service
 	page      *template.Template
-	heroMenu  []order.MenuItem
-	logger    *log.Logger
+	theme     string
+	devFS     fs.FS
+	readOnly  bool
+	// heroMenu is the fallback menu, read by every page render and swapped whole by ReloadHeroMenu;
+	// the slice behind it is never modified once stored.
+	heroMenu     atomic.Pointer[[]order.MenuItem]
+	heroMenuPath string
+	catalog      *i18n.Catalog
+	logger       *log.Logger
+	// accessFormat selects the access log line written by the accessLog middleware.
+	accessFormat string
+	maxInflight  int
+	// requestDeadline is the whole-request limit applied by the requestTimeout middleware.
+	requestDeadline time.Duration
+	// trustedProxies gates X-Forwarded-For; an empty list means client addresses come from the socket only.
+	trustedProxies []netip.Prefix
+	categories     categoryRules
+	depot          *order.Point
+	orderHours     orderHours
+	clock          clock.Clock
+	// started is when the server was built, for the uptime the debug endpoint reports.
+	started time.Time
+	// inventorySchema checks inventory bodies before they are decoded into inventoryPayload.
+	inventorySchema *jsonSchema
+	imageDir        string
+	maxImageBytes   int64
+	// maintenance is read by every request and swapped by the admin endpoint or a signal.
+	maintenance     atomic.Pointer[maintenanceState]
+	maintenanceTmpl *template.Template
+	errorTmpl       *template.Template
+	adminRefresh    time.Duration
+	// duplicateBatches decides whether createInventory looks for an identical stored batch first.
+	duplicateBatches string
+	timeline         *order.Timeline
+	customers        *customer.Service
+	hstsMaxAge       time.Duration
+	// adminToken is the AdminToken option, checked by requireAdmin.
+	adminToken string
+	// confirmationTemplate is the -order-confirmation text; empty means the catalog's.
+	confirmationTemplate string
+	// currency tags every price the server hands out, so money.Money prints it the local way.
+	currency string
+	// funcs are the template functions every page is parsed with, kept for dev-mode reloads;
+	// dateLayout is the DateLayout option.
+	funcs      template.FuncMap
+	dateLayout string
+	// anyContentType is the AnyContentType option, checked by requireJSON.
+	anyContentType bool
+	// readyCheck is the ReadyCheck option; draining is set by Drain and fails /readyz from then on.
+	readyCheck func(context.Context) error
+	draining   atomic.Bool
 }
 
 // New prepares the template once to respect the proverb "A little copying is better than a little dependency."
-func New(orderService *order.Service, inventoryService *inventory.Service, logger *log.Logger) (*Server, error) {
-	tmpl, err := template.ParseFS(uiFS, "public_html/app.gohtml")
+func New(orderService *order.Service, inventoryService *inventory.Service, productService *product.Service, logger *log.Logger, opts Options) (*Server, error) {
+	if err := validAccessLog(opts.AccessLog); err != nil {
+		return nil, err
+	}
+	if err := validDuplicateBatches(opts.DuplicateBatches); err != nil {
+		return nil, err
+	}
+	trustedProxies, err := parseTrustedProxies(opts.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := parseOrderHours(opts.OrderHours)
+	if err != nil {
+		return nil, err
+	}
+	inventorySchema, err := loadSchema(inventorySchemaName)
 	if err != nil {
 		return nil, err
 	}
+	if opts.ImageDir != "" {
+		if err := os.MkdirAll(opts.ImageDir, 0o755); err != nil {
+			return nil, fmt.Errorf("create image directory: %w", err)
+		}
+	}
+	maxImageBytes := opts.MaxImageBytes
+	if maxImageBytes <= 0 {
+		maxImageBytes = DefaultMaxImageBytes
+	}
+	currency := opts.Currency
+	if currency == "" {
+		currency = money.RUB
+	}
+	if !money.Supported(currency) {
+		return nil, fmt.Errorf("unsupported currency %q: use one of %s", currency, strings.Join(money.Currencies(), ", "))
+	}
+	heroMenu, err := loadHeroMenu(opts.HeroMenuPath, currency)
+	if err != nil {
+		return nil, err
+	}
+	// The catalog comes first because the template functions localize with it.
+	catalog, err := i18n.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load message catalogs: %w", err)
+	}
+	funcs := templateFuncs(catalog, currency, opts.DateLayout)
+	var templates fs.FS = uiFS
+	if opts.Dev {
+		templates = os.DirFS(devTemplateRoot)
+	}
+	// Parsing once even in dev mode surfaces a bad theme name or broken template at startup.
+	tmpl, err := parseTheme(templates, opts.Theme, funcs)
+	if err != nil {
+		return nil, err
+	}
+	maintenancePage, err := parseMaintenancePage(funcs)
+	if err != nil {
+		return nil, fmt.Errorf("parse maintenance page: %w", err)
+	}
+	errorPage, err := parseErrorPage(funcs)
+	if err != nil {
+		return nil, fmt.Errorf("parse error page: %w", err)
+	}
 	if logger == nil {
 		// The API defaults to a standard logger so deployments always get feedback about requests.
 		logger = log.New(os.Stdout, "[bakery] ", log.LstdFlags)
 	}
-	return &Server{
-		orders:    orderService,
-		inventory: inventoryService,
-		page:      tmpl,
-		heroMenu:  defaultMenu(),
-		logger:    logger,
-	}, nil
+	srv := &Server{
+		orders:       orderService,
+		inventory:    inventoryService,
+		products:     productService,
+		page:         tmpl,
+		theme:        opts.Theme,
+		heroMenuPath: opts.HeroMenuPath,
+		catalog:      catalog,
+		readOnly:     opts.ReadOnly,
+		logger:       logger,
+
+		accessFormat: opts.AccessLog,
+		maxInflight:  opts.MaxInflight,
+
+		requestDeadline: opts.RequestTimeout,
+
+		trustedProxies: trustedProxies,
+		categories:     newCategoryRules(opts.Categories, opts.AllowCustomCategories),
+		depot:          opts.Depot,
+		orderHours:     hours,
+		clock:          clock.OrReal(opts.Clock),
+
+		inventorySchema: inventorySchema,
+		imageDir:        opts.ImageDir,
+		maxImageBytes:   maxImageBytes,
+		maintenanceTmpl: maintenancePage,
+		errorTmpl:       errorPage,
+		adminRefresh:    opts.AdminRefresh,
+
+		duplicateBatches: opts.DuplicateBatches,
+		timeline:         opts.Timeline,
+		customers:        opts.Customers,
+		hstsMaxAge:       opts.HSTSMaxAge,
+		adminToken:       opts.AdminToken,
+
+		confirmationTemplate: opts.ConfirmationTemplate,
+		currency:             currency,
+		funcs:                funcs,
+		dateLayout:           opts.DateLayout,
+		anyContentType:       opts.AnyContentType,
+		readyCheck:           opts.ReadyCheck,
+	}
+	srv.started = srv.clock.Now()
+	srv.heroMenu.Store(&heroMenu)
+	srv.maintenance.Store(&maintenanceState{Enabled: opts.Maintenance, Reads: opts.MaintenanceReads})
+	if opts.Dev {
+		srv.devFS = templates
+		logger.Printf("dev mode: templates are reloaded from %s on every request", devTemplateRoot)
+	}
+	if opts.AdminToken == "" {
+		logger.Printf("no -admin-token: the admin API is open, and backup and restore are not served")
+	}
+	return srv, nil
 }
 
 // Handler exposes the mux with HTML, JSON, and admin capabilities.
 func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
-	mux.Handle("/", s.pageHandler("customer"))
-	mux.Handle("/admin", s.pageHandler("admin"))
-	mux.Handle("/api/orders", s.ordersEndpoint())
-	mux.Handle("/api/menu", s.menuEndpoint())
-	mux.Handle("/api/admin/inventory", s.inventoryEndpoint())
-	return mux
+	for _, rt := range s.routes() {
+		if rt, ok := s.guardAdmin(rt); ok {
+			mux.Handle(rt.pattern, s.serveRoute(rt))
+		}
+	}
+	// "/" is the fallback for every path no route claims; the storefront itself is "/{$}".
+	mux.Handle("/", s.notFoundHandler())
+	return s.securityHeaders(s.accessLog(s.limitInflight(s.recoverPanics(s.maintenanceGate(s.requestTimeout(s.tagActor(mux)))))))
 }
 
-// pageHandler renders the single page template with appropriate bootstrapped JSON.
+// pageHandler renders the single page template with appropriate bootstrapped JSON. Texts are
+// looked up by the template itself through localize, so only the page's language is passed in.
 func (s *Server) pageHandler(page string) http.Handler {
 	type viewData struct {
 		Page           string
-		FreeDelivery   string
-		CroissantBlurb string
+		Lang           string
 		MenuJSON       template.JS
+		CategoriesJSON template.JS
+		ConfigJSON     template.JS
+		// Nonce marks the inline bootstrap script as the one the page's CSP lets run.
+		Nonce string
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			s.methodNotAllowed(w, r, http.MethodGet)
 			return
 		}
-		menu := s.resolveMenu(r.Context())
+		// The bootstrap JSON and the nonce only fail on a bug or a broken system, so the detail goes
+		// to the log and the client gets the generic page error with its code.
+		lang := s.locale(r)
+		menu := s.resolveMenu(r.Context(), lang)
 		payload, err := json.Marshal(menu)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			s.pageFailed(w, r, page, "failed to encode the menu", err)
+			return
+		}
+		categories, err := json.Marshal(s.categories.allowed)
+		if err != nil {
+			s.pageFailed(w, r, page, "failed to encode the categories", err)
+			return
+		}
+		config, err := json.Marshal(s.adminConfig())
+		if err != nil {
+			s.pageFailed(w, r, page, "failed to encode the config", err)
+			return
+		}
+		nonce, err := newNonce()
+		if err != nil {
+			s.pageFailed(w, r, page, "failed to draw a CSP nonce", err)
 			return
 		}
 		data := viewData{
 			Page:           page,
-			FreeDelivery:   "Бесплатная доставка по району Белая Ромашка каждое утро",
-			CroissantBlurb: "Запланируйте хлеб и круассаны, мы привезем к утреннему чаю",
+			Lang:           lang,
 			MenuJSON:       template.JS(string(payload)),
+			CategoriesJSON: template.JS(string(categories)),
+			ConfigJSON:     template.JS(string(config)),
+			Nonce:          nonce,
 		}
-		if err := s.page.Execute(w, data); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		// Rendering into a buffer first means a failing template never leaves a half-written page behind.
+		tmpl, err := s.currentTemplate()
+		if err != nil {
+			s.pageFailed(w, r, page, "failed to reload templates", err)
+			return
+		}
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, pageTemplate, data); err != nil {
+			s.pageFailed(w, r, page, "failed to render", err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy(nonce))
+		if _, err := buf.WriteTo(w); err != nil {
+			s.logger.Printf("page %s write to %s failed: %v", page, s.clientIP(r), err)
 			return
 		}
 		// Logging page visits keeps the operator aware of customer and admin traffic without extra middleware.
-		s.logger.Printf("page %s served to %s", page, r.RemoteAddr)
+		s.logger.Printf("page %s served to %s", page, s.clientIP(r))
 	})
 }
 
+// pageFailed logs why a page could not be built and answers with the 500 error page, JSON with the
+// internal code for clients that do not ask for HTML.
+func (s *Server) pageFailed(w http.ResponseWriter, r *http.Request, page, what string, err error) {
+	s.logger.Printf("page %s %s: %v", page, what, err)
+	s.respondPageError(w, r, s.text(r, "unable to render page"), http.StatusInternalServerError)
+}
+
+// currentTemplate returns the startup template, or a freshly parsed one when dev mode reads from disk.
+func (s *Server) currentTemplate() (*template.Template, error) {
+	if s.devFS == nil {
+		return s.page, nil
+	}
+	return parseTheme(s.devFS, s.theme, s.funcs)
+}
+
+// Themes lists the embedded theme directories so the CLI can report valid choices.
+func Themes() ([]string, error) {
+	return themesIn(uiFS)
+}
+
+// themesIn lists theme directories in either the embedded or the on-disk template tree.
+func themesIn(templates fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(templates, themesDir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// parseTheme validates the theme name against the available directories before parsing its layout
+// with the template functions.
+func parseTheme(templates fs.FS, theme string, funcs template.FuncMap) (*template.Template, error) {
+	if theme == "" {
+		theme = DefaultTheme
+	}
+	available, err := themesIn(templates)
+	if err != nil {
+		return nil, fmt.Errorf("list themes: %w", err)
+	}
+	if !slices.Contains(available, theme) {
+		return nil, fmt.Errorf("unknown theme %q (available: %s)", theme, strings.Join(available, ", "))
+	}
+	tmpl, err := template.New(pageTemplate).Funcs(funcs).ParseFS(templates, path.Join(themesDir, theme, pageTemplate), "public_html/partials/*.gohtml")
+	if err != nil {
+		return nil, fmt.Errorf("parse theme %q: %w", theme, err)
+	}
+	return tmpl, nil
+}
+
 // ordersEndpoint handles both creation and retrieval to keep JSON endpoints in one place.
 func (s *Server) ordersEndpoint() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
 		switch r.Method {
 		case http.MethodPost:
+			if s.orderingClosed(w, r) {
+				return
+			}
 			s.createOrder(w, r)
 		case http.MethodGet:
 			s.listOrders(w, r)
 		default:
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			s.methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
 		}
 	})
 }
@@ -117,32 +464,101 @@ func (s *Server) menuEndpoint() http.Handler {
 		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 		defer cancel()
 
+		products, err := s.products.List(ctx)
+		if err != nil {
+			s.logger.Printf("menu failed: unable to list products: %v", err)
+			s.respondServiceError(w, err)
+			return
+		}
 		items, err := s.inventory.List(ctx)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			s.logger.Printf("menu failed: unable to list inventory: %v", err)
+			s.respondServiceError(w, err)
 			return
 		}
 
-		menu := make([]order.MenuItem, 0, len(items))
-		for _, item := range items {
-			menu = append(menu, order.MenuItem{
-				Name:        item.Name,
-				Description: fmt.Sprintf("Свежая партия от %s", item.BakedAt.Format("02.01 15:04")),
-				Price:       formatPrice(item.PriceCents),
-				Image:       imageForCategory(item.Category),
-				Category:    item.Category,
-			})
-		}
+		menu := s.buildMenu(products, items, s.locale(r))
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(menu)
 		// Surface a log for the menu feed so bakers can see when the storefront fetches updated listings.
-		s.logger.Printf("menu served with %d items to %s", len(menu), r.RemoteAddr)
+		s.logger.Printf("menu served with %d items to %s", len(menu), s.clientIP(r))
 	})
 }
 
+// categoriesEndpoint lists the categories in stock with their batch counts so the storefront can
+// render tabs without downloading the whole menu.
+func (s *Server) categoriesEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			s.methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+
+		categories, err := s.inventory.Categories(ctx)
+		if err != nil {
+			s.respondServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(categories)
+	})
+}
+
+// menuSummary is the answer of GET /api/menu/summary. Source says whether the counts come from
+// inventory or from the hero menu shown while there is none.
+type menuSummary struct {
+	inventory.Summary
+	Source string `json:"source"`
+}
+
+// menuSummaryEndpoint serves the per-category totals behind lines like "3 kinds of bread, 5
+// pastries" next to the /api/menu array, which keeps its shape for existing clients. While the
+// storefront shows the hero menu the totals describe that menu instead, so the line and the cards
+// never disagree.
+func (s *Server) menuSummaryEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), menuLoadTimeout)
+		defer cancel()
+
+		summary, err := s.inventory.Summary(ctx)
+		source := "inventory"
+		switch {
+		case err != nil:
+			s.logger.Printf("menu summary falls back to the hero menu: %v", err)
+			summary, source = heroSummary(s.fallbackMenu(s.locale(r))), "hero"
+		case len(summary.Categories) == 0:
+			summary, source = heroSummary(s.fallbackMenu(s.locale(r))), "hero"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(menuSummary{Summary: summary, Source: source})
+	})
+}
+
+// heroSummary counts the hero menu like inventory.Summarize counts batches. Hero items have no
+// tracked stock, so each available item is one kind with no unit count.
+func heroSummary(menu []order.MenuItem) inventory.Summary {
+	items := make([]inventory.Item, 0, len(menu))
+	for _, item := range menu {
+		if item.Available {
+			items = append(items, inventory.Item{Name: item.Name, Category: item.Category, AvailableCount: 1})
+		}
+	}
+	summary := inventory.Summarize(items)
+	summary.Units = 0
+	for i := range summary.Categories {
+		summary.Categories[i].Units = 0
+	}
+	return summary
+}
+
 // inventoryEndpoint lets bakers manage their batches without exposing raw database handles.
 func (s *Server) inventoryEndpoint() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
 		switch r.Method {
 		case http.MethodPost:
 			s.createInventory(w, r)
@@ -153,31 +569,54 @@ func (s *Server) inventoryEndpoint() http.Handler {
 		case http.MethodGet:
 			s.listInventory(w, r)
 		default:
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			s.methodNotAllowed(w, r, http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete)
 		}
 	})
 }
 
+// writesDisabled answers 405 to mutating requests on read-only replicas and reports whether it did.
+func (s *Server) writesDisabled(w http.ResponseWriter, r *http.Request) bool {
+	if !s.readOnly {
+		return false
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	s.logger.Printf("read-only replica refused %s %s from %s", r.Method, r.URL.Path, s.clientIP(r))
+	w.Header().Set("Allow", "GET")
+	s.respondError(w, "this instance is read-only", http.StatusMethodNotAllowed)
+	return true
+}
+
 // createOrder decodes payloads, performs validation, and hands off work to the channel-powered service.
 func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
 	type schedulePayload struct {
-		Frequency string   `json:"frequency"`
-		Days      []string `json:"days"`
-		StartDate string   `json:"startDate"`
-		Notes     string   `json:"notes"`
+		Frequency   string   `json:"frequency"`
+		Days        []string `json:"days"`
+		StartDate   string   `json:"startDate"`
+		Notes       string   `json:"notes"`
+		Paused      bool     `json:"paused"`
+		PausedUntil string   `json:"pausedUntil"`
 	}
+	// Quantities stay number text until parseQuantity has checked them, so a fractional or huge count
+	// is reported against its own field instead of failing the whole decode.
 	type croissantPayload struct {
-		Day      string `json:"day"`
-		Quantity int    `json:"quantity"`
-		Item     string `json:"item"`
+		Day      string      `json:"day"`
+		Quantity json.Number `json:"quantity"`
+		Item     string      `json:"item"`
 	}
 	type itemPayload struct {
-		Name     string `json:"name"`
-		Quantity int    `json:"quantity"`
+		Name        string      `json:"name"`
+		Quantity    json.Number `json:"quantity"`
+		Options     []string    `json:"options"`
+		Custom      bool        `json:"custom"`
+		Description string      `json:"description"`
 	}
 	type orderPayload struct {
 		Name              string             `json:"name"`
 		Phone             string             `json:"phone"`
+		Email             string             `json:"email"`
 		Address           string             `json:"address"`
 		BreadSchedule     schedulePayload    `json:"breadSchedule"`
 		CroissantSchedule []croissantPayload `json:"croissantSchedule"`
@@ -185,60 +624,65 @@ func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
 		Comment           string             `json:"comment"`
 	}
 
+	if s.requireJSON(w, r) {
+		return
+	}
 	var payload orderPayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		s.logger.Printf("order creation failed: unable to decode payload: %v", err)
-		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		s.respondValidation(w, r, fieldError{Code: codeInvalidJSON, Message: "invalid JSON"})
 		return
 	}
 
+	// Payload rules live in the order service so every failure comes back in one validation error;
+	// only counts that cannot even be read as whole numbers are refused here.
+	var numberErrors []fieldError
 	schedule := make([]order.CroissantSchedule, 0, len(payload.CroissantSchedule))
-	for _, slot := range payload.CroissantSchedule {
-		if strings.TrimSpace(slot.Day) == "" {
-			s.logger.Printf("order creation rejected: missing day for croissant schedule")
-			http.Error(w, "day is required", http.StatusBadRequest)
-			return
-		}
-		if slot.Quantity <= 0 {
-			s.logger.Printf("order creation rejected: non-positive croissant quantity: %d", slot.Quantity)
-			http.Error(w, "quantity must be a positive number", http.StatusBadRequest)
-			return
+	for i, slot := range payload.CroissantSchedule {
+		qty, fe := parseQuantity(fmt.Sprintf("croissantSchedule[%d].quantity", i), string(slot.Quantity))
+		if fe != nil {
+			numberErrors = append(numberErrors, *fe)
 		}
 		schedule = append(schedule, order.CroissantSchedule{
 			Day:      slot.Day,
-			Quantity: slot.Quantity,
+			Quantity: qty,
 			Item:     slot.Item,
 		})
 	}
 
 	items := make([]order.OrderItem, 0, len(payload.Items))
-	for _, item := range payload.Items {
-		if strings.TrimSpace(item.Name) == "" {
-			s.logger.Printf("order creation rejected: item name missing")
-			http.Error(w, "item name is required", http.StatusBadRequest)
-			return
-		}
-		if item.Quantity <= 0 {
-			s.logger.Printf("order creation rejected: invalid quantity %d for %s", item.Quantity, item.Name)
-			http.Error(w, "item quantity must be positive", http.StatusBadRequest)
-			return
+	for i, item := range payload.Items {
+		qty, fe := parseQuantity(fmt.Sprintf("items[%d].quantity", i), string(item.Quantity))
+		if fe != nil {
+			numberErrors = append(numberErrors, *fe)
 		}
 		items = append(items, order.OrderItem{
-			Name:     item.Name,
-			Quantity: item.Quantity,
+			Name:        item.Name,
+			Quantity:    qty,
+			Options:     item.Options,
+			Custom:      item.Custom,
+			Description: item.Description,
 		})
 	}
+	if len(numberErrors) > 0 {
+		s.logger.Printf("order creation rejected: %d unreadable quantities", len(numberErrors))
+		s.respondValidation(w, r, numberErrors...)
+		return
+	}
 
 	request := order.Order{
 		CustomerName: payload.Name,
 		Phone:        payload.Phone,
+		Email:        payload.Email,
 		Address:      payload.Address,
 		Items:        items,
 		BreadSchedule: order.BreadSchedule{
-			Frequency: payload.BreadSchedule.Frequency,
-			Days:      payload.BreadSchedule.Days,
-			StartDate: payload.BreadSchedule.StartDate,
-			Notes:     payload.BreadSchedule.Notes,
+			Frequency:   payload.BreadSchedule.Frequency,
+			Days:        payload.BreadSchedule.Days,
+			StartDate:   payload.BreadSchedule.StartDate,
+			Notes:       payload.BreadSchedule.Notes,
+			Paused:      payload.BreadSchedule.Paused,
+			PausedUntil: payload.BreadSchedule.PausedUntil,
 		},
 		CroissantSchedule: schedule,
 		Comment:           payload.Comment,
@@ -248,65 +692,169 @@ func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	stored, err := s.orders.Submit(ctx, request)
+	if errors.Is(err, order.ErrDuplicate) {
+		// A double-tapped submit gets the order it already placed, flagged so clients can tell.
+		s.logger.Printf("order from %s matches order %d submitted moments ago; returning it", request.CustomerName, stored.ID)
+		w.Header().Set("X-Duplicate-Of", strconv.FormatInt(stored.ID, 10))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.confirmOrder(r, stored))
+		return
+	}
 	if err != nil {
 		if order.IsValidation(err) {
 			s.logger.Printf("order creation failed validation for %s at %s: %v", request.CustomerName, request.Address, err)
-			s.respondError(w, err.Error(), http.StatusBadRequest)
+			s.respondValidation(w, r, orderFieldErrors(err)...)
 			return
 		}
 		s.logger.Printf("order creation failed for %s at %s: %v", request.CustomerName, request.Address, err)
-		s.respondError(w, err.Error(), http.StatusInternalServerError)
+		s.respondServiceError(w, err)
 		return
 	}
 
 	s.logger.Printf("order stored for %s at %s with %d items", stored.CustomerName, stored.Address, len(stored.Items))
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stored)
+	json.NewEncoder(w).Encode(s.confirmOrder(r, stored))
 }
 
 // listOrders returns all collected orders for administrative oversight.
+// ?delivery_day=tuesday keeps only orders with bread or croissants on that weekday, and
+// ?custom=true only orders with a custom item still waiting for a quote. Both look inside the items
+// and schedules stored as JSON, so these filters run here after loading rather than in the query.
 func (s *Server) listOrders(w http.ResponseWriter, r *http.Request) {
+	var filters []func(order.Order) bool
+	if raw := r.URL.Query().Get("delivery_day"); raw != "" {
+		weekday, ok := order.ParseWeekday(raw)
+		if !ok {
+			s.logger.Printf("order listing rejected: unknown delivery day %q", raw)
+			s.respondValidation(w, r, fieldError{Field: "delivery_day", Code: codeInvalid, Message: "unknown delivery day"})
+			return
+		}
+		filters = append(filters, func(o order.Order) bool { return o.DeliversOn(weekday) })
+	}
+	if raw := r.URL.Query().Get("custom"); raw != "" {
+		custom, err := strconv.ParseBool(raw)
+		if err != nil {
+			s.logger.Printf("order listing rejected: unreadable custom filter %q", raw)
+			s.respondValidation(w, r, fieldError{Field: "custom", Code: codeInvalid, Message: "custom must be true or false"})
+			return
+		}
+		filters = append(filters, func(o order.Order) bool { return o.HasCustom() == custom })
+	}
+	page, ok := s.orderPage(w, r)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	orders, err := s.orders.List(ctx)
-	if err != nil {
+	// Delivery days and custom items live inside JSON columns, so those filters cannot run in the
+	// query; every order with the requested status is read in the requested order, and only the
+	// matching ones are kept and paged here instead.
+	var cursor iter.Seq2[order.Order, error]
+	if len(filters) > 0 {
+		all, err := s.orders.ListPageCursor(ctx, order.Page{Status: page.Status, Sort: page.Sort})
+		if err != nil {
+			s.logger.Printf("order listing failed: %v", err)
+			s.respondServiceError(w, err)
+			return
+		}
+		var matching []order.Order
+		for o, err := range all {
+			if err != nil {
+				s.logger.Printf("order listing failed: %v", err)
+				s.respondServiceError(w, err)
+				return
+			}
+			if keepOrder(o, filters) {
+				matching = append(matching, o)
+			}
+		}
+		cursor = sliceItems(pageOrders(matching, page))
+	} else {
+		var err error
+		if cursor, err = s.orders.ListPageCursor(ctx, page); err != nil {
+			s.logger.Printf("order listing failed: %v", err)
+			s.respondServiceError(w, err)
+			return
+		}
+	}
+	count, err := streamJSONArray(w, cursor)
+	switch {
+	case err != nil && count == 0:
 		s.logger.Printf("order listing failed: %v", err)
-		s.respondError(w, err.Error(), http.StatusInternalServerError)
-		return
+		s.respondServiceError(w, err)
+	case err != nil:
+		s.logger.Printf("order listing cut short after %d records: %v", count, err)
+	default:
+		s.logger.Printf("order listing served with %d records", count)
 	}
-	s.logger.Printf("order listing served with %d records", len(orders))
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(orders)
+}
+
+// keepOrder reports whether the order passes every listing filter.
+func keepOrder(o order.Order, filters []func(order.Order) bool) bool {
+	for _, keep := range filters {
+		if !keep(o) {
+			return false
+		}
+	}
+	return true
 }
 
 // createInventory adds a new baked batch so the front-end menu stays fresh.
 func (s *Server) createInventory(w http.ResponseWriter, r *http.Request) {
 	var payload inventoryPayload
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		s.logger.Printf("inventory creation failed: unable to decode payload: %v", err)
-		s.respondError(w, "invalid JSON", http.StatusBadRequest)
+	if !s.decodeChecked(w, r, s.inventorySchema, "inventory creation", &payload) {
 		return
 	}
-	if err := payload.Validate(); err != nil {
+	if err := payload.Validate(s.categories); err != nil {
 		s.logger.Printf("inventory creation rejected: %v", err)
-		s.respondError(w, err.Error(), http.StatusBadRequest)
+		s.respondValidation(w, r, asFieldError(err))
 		return
 	}
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
+	if payload.ProductID != 0 {
+		known, err := s.productExists(ctx, payload.ProductID)
+		if err != nil {
+			s.logger.Printf("inventory creation failed: unable to check product %d: %v", payload.ProductID, err)
+			s.respondServiceError(w, err)
+			return
+		}
+		if !known {
+			s.logger.Printf("inventory creation rejected: unknown product %d", payload.ProductID)
+			s.respondValidation(w, r, fieldError{Field: "product_id", Code: codeInvalid, Message: "unknown product"})
+			return
+		}
+	}
+
 	item := inventory.Item{
+		ProductID:      payload.ProductID,
 		Name:           payload.Name,
 		Category:       payload.Category,
 		BakedAt:        payload.BakedAt,
 		PriceCents:     payload.PriceCents,
 		AvailableCount: payload.Quantity,
 	}
-	stored, err := s.inventory.Add(ctx, item)
+	var (
+		stored inventory.Item
+		err    error
+	)
+	if s.checkDuplicates(r) {
+		stored, err = s.inventory.AddUnique(ctx, item)
+	} else {
+		stored, err = s.inventory.Add(ctx, item)
+	}
+	var dup *inventory.DuplicateError
+	if errors.As(err, &dup) {
+		s.logger.Printf("inventory creation of %s held back: duplicates batch %d", item.Name, dup.Existing.ID)
+		s.respondDuplicate(w, r, dup)
+		return
+	}
 	if err != nil {
 		s.logger.Printf("inventory creation failed for %s: %v", item.Name, err)
-		s.respondError(w, err.Error(), http.StatusInternalServerError)
+		s.respondServiceError(w, err)
 		return
 	}
 	s.logger.Printf("inventory item %s recorded with %d units", stored.Name, stored.AvailableCount)
@@ -317,24 +865,38 @@ func (s *Server) createInventory(w http.ResponseWriter, r *http.Request) {
 // updateInventory edits an existing batch identified by id.
 func (s *Server) updateInventory(w http.ResponseWriter, r *http.Request) {
 	var payload inventoryPayload
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		s.logger.Printf("inventory update failed: unable to decode payload: %v", err)
-		s.respondError(w, "invalid JSON", http.StatusBadRequest)
+	if !s.decodeChecked(w, r, s.inventorySchema, "inventory update", &payload) {
 		return
 	}
 	if payload.ID == 0 {
 		s.logger.Printf("inventory update rejected: missing id")
-		s.respondError(w, "id is required", http.StatusBadRequest)
+		s.respondValidation(w, r, fieldError{Field: "id", Code: codeRequired, Message: "id is required"})
 		return
 	}
-	if err := payload.Validate(); err != nil {
+	if err := payload.Validate(s.categories); err != nil {
 		s.logger.Printf("inventory update rejected for id %d: %v", payload.ID, err)
-		s.respondError(w, err.Error(), http.StatusBadRequest)
+		s.respondValidation(w, r, asFieldError(err))
 		return
 	}
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
+	// An update never moves a batch to another product, so a product_id that disagrees with the
+	// stored one is refused rather than silently dropped.
+	if payload.ProductID != 0 {
+		stored, found, err := s.storedBatch(ctx, int64(payload.ID))
+		if err != nil {
+			s.logger.Printf("inventory update failed: unable to load item %d: %v", payload.ID, err)
+			s.respondServiceError(w, err)
+			return
+		}
+		if found && stored.ProductID != payload.ProductID {
+			s.logger.Printf("inventory update rejected for id %d: product %d sent, batch belongs to %d", payload.ID, payload.ProductID, stored.ProductID)
+			s.respondFieldErrors(w, r, http.StatusUnprocessableEntity, fieldError{Field: "product_id", Code: codeMismatch, Message: "product_id does not match the batch"})
+			return
+		}
+	}
+
 	item := inventory.Item{
 		ID:             int64(payload.ID),
 		Name:           payload.Name,
@@ -350,7 +912,7 @@ func (s *Server) updateInventory(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		s.logger.Printf("inventory update failed for %d: %v", payload.ID, err)
-		s.respondError(w, err.Error(), http.StatusInternalServerError)
+		s.respondServiceError(w, err)
 		return
 	}
 	s.logger.Printf("inventory item %d updated with %d units", payload.ID, payload.Quantity)
@@ -362,13 +924,13 @@ func (s *Server) deleteInventory(w http.ResponseWriter, r *http.Request) {
 	rawID := r.URL.Query().Get("id")
 	if rawID == "" {
 		s.logger.Printf("inventory delete rejected: missing id")
-		s.respondError(w, "id is required", http.StatusBadRequest)
+		s.respondValidation(w, r, fieldError{Field: "id", Code: codeRequired, Message: "id is required"})
 		return
 	}
 	id, err := strconv.Atoi(rawID)
 	if err != nil {
 		s.logger.Printf("inventory delete rejected: invalid id %s", rawID)
-		s.respondError(w, "invalid id", http.StatusBadRequest)
+		s.respondValidation(w, r, fieldError{Field: "id", Code: codeInvalid, Message: "invalid id"})
 		return
 	}
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
@@ -381,7 +943,7 @@ func (s *Server) deleteInventory(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		s.logger.Printf("inventory delete failed for %d: %v", id, err)
-		s.respondError(w, err.Error(), http.StatusInternalServerError)
+		s.respondServiceError(w, err)
 		return
 	}
 	s.logger.Printf("inventory item %d deleted", id)
@@ -393,57 +955,194 @@ func (s *Server) listInventory(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	items, err := s.inventory.List(ctx)
+	items, err := s.inventory.ListCursor(ctx)
 	if err != nil {
 		s.logger.Printf("inventory listing failed: %v", err)
-		s.respondError(w, err.Error(), http.StatusInternalServerError)
+		s.respondServiceError(w, err)
 		return
 	}
-	s.logger.Printf("inventory listing served with %d records", len(items))
-	response := make([]inventoryResponse, 0, len(items))
-	for _, item := range items {
-		response = append(response, inventoryResponse{
-			ID:       int(item.ID),
-			Name:     item.Name,
-			Category: item.Category,
-			BakedAt:  item.BakedAt.Format("2006-01-02 15:04"),
-			Price:    formatPrice(item.PriceCents),
-			Quantity: item.AvailableCount,
-		})
+	count, err := streamJSONArray(w, mapItems(items, s.inventoryView))
+	switch {
+	case err != nil && count == 0:
+		s.logger.Printf("inventory listing failed: %v", err)
+		s.respondServiceError(w, err)
+	case err != nil:
+		s.logger.Printf("inventory listing cut short after %d records: %v", count, err)
+	default:
+		s.logger.Printf("inventory listing served with %d records", count)
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
 }
 
-// respondError keeps JSON formatting consistent across endpoints.
-func (s *Server) respondError(w http.ResponseWriter, message string, status int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+// inventoryView shapes a batch the way the inventory listing shows it.
+func (s *Server) inventoryView(item inventory.Item) inventoryResponse {
+	return inventoryResponse{
+		ID:        int(item.ID),
+		ProductID: item.ProductID,
+		Name:      item.Name,
+		Category:  item.Category,
+		BakedAt:   item.BakedAt.Format("2006-01-02 15:04"),
+		Price:     money.In(item.PriceCents, s.currency),
+		Quantity:  item.AvailableCount,
+		Reserved:  item.ReservedCount,
+		Image:     imageURL(item),
+		Featured:  item.Featured,
+	}
 }
 
-// resolveMenu either pulls inventory or falls back to static offerings.
-func (s *Server) resolveMenu(ctx context.Context) []order.MenuItem {
+// locale resolves the visitor's language from ?lang= first and Accept-Language second.
+func (s *Server) locale(r *http.Request) string {
+	return s.catalog.Negotiate(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+}
+
+// text translates a catalog key for the request's locale; unknown keys such as wrapped parse errors pass through.
+func (s *Server) text(r *http.Request, key string) string {
+	return s.catalog.Text(s.locale(r), key)
+}
+
+// resolveMenu either pulls the catalog and inventory or falls back to static offerings.
+// The lookups get their own short deadline: a busy service during a traffic spike should cost
+// the page its live stock numbers, not the whole render.
+func (s *Server) resolveMenu(ctx context.Context, lang string) []order.MenuItem {
+	ctx, cancel := context.WithTimeout(ctx, menuLoadTimeout)
+	defer cancel()
+
+	products, err := s.products.List(ctx)
+	if err != nil {
+		s.logger.Printf("page menu falls back to the hero menu: loading products failed: %v", err)
+		return s.fallbackMenu(lang)
+	}
 	items, err := s.inventory.List(ctx)
-	if err != nil || len(items) == 0 {
-		return s.heroMenu
+	if err != nil {
+		s.logger.Printf("page menu falls back to the hero menu: loading inventory failed: %v", err)
+		return s.fallbackMenu(lang)
+	}
+	if len(products)+len(items) == 0 {
+		return s.fallbackMenu(lang)
 	}
-	menu := make([]order.MenuItem, 0, len(items))
+	return s.buildMenu(products, items, lang)
+}
+
+// buildMenu lists every catalog product, overlaying its freshest linked batch when one is in stock,
+// followed by batches that are not linked to a product so pre-catalog inventory keeps showing up.
+// Remaining sums the linked batches' unreserved counts so sold-out entries stay listed but flagged unavailable.
+// Batch descriptions are written in lang.
+func (s *Server) buildMenu(products []product.Product, items []inventory.Item, lang string) []order.MenuItem {
+	currency := s.currency
+	known := make(map[int64]bool, len(products))
+	for _, p := range products {
+		known[p.ID] = true
+	}
+	// Inventory is listed newest first, so the first batch seen per product is the freshest one.
+	freshest := make(map[int64]inventory.Item, len(products))
+	remaining := make(map[int64]int, len(products))
+	featured := make(map[int64]bool, len(products))
+	var unlinked []inventory.Item
 	for _, item := range items {
+		if !known[item.ProductID] {
+			unlinked = append(unlinked, item)
+			continue
+		}
+		remaining[item.ProductID] += item.ForSale()
+		featured[item.ProductID] = featured[item.ProductID] || item.Featured
+		if _, seen := freshest[item.ProductID]; !seen && item.ForSale() > 0 {
+			freshest[item.ProductID] = item
+		}
+	}
+
+	menu := make([]order.MenuItem, 0, len(products)+len(unlinked))
+	for _, p := range products {
+		entry := order.MenuItem{
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       money.In(p.BasePriceCents, currency),
+			Image:       p.Image,
+			Category:    p.Category,
+			Available:   remaining[p.ID] > 0,
+			Remaining:   remaining[p.ID],
+			Featured:    featured[p.ID],
+		}
+		if entry.Image == "" {
+			entry.Image = imageForCategory(p.Category)
+		}
+		if batch, ok := freshest[p.ID]; ok {
+			entry.Price = money.In(batch.PriceCents, currency)
+			if p.Image == "" && batch.Image != "" {
+				entry.Image = batch.Image
+			}
+			if entry.Description == "" {
+				entry.Description = s.batchDescription(lang, batch)
+			}
+		}
+		menu = append(menu, entry)
+	}
+	for _, item := range unlinked {
+		image := item.Image
+		if image == "" {
+			image = imageForCategory(item.Category)
+		}
 		menu = append(menu, order.MenuItem{
 			Name:        item.Name,
-			Description: fmt.Sprintf("Свежая партия от %s", item.BakedAt.Format("02.01 15:04")),
-			Price:       formatPrice(item.PriceCents),
-			Image:       imageForCategory(item.Category),
+			Description: s.batchDescription(lang, item),
+			Price:       money.In(item.PriceCents, currency),
+			Image:       image,
 			Category:    item.Category,
+			Available:   item.ForSale() > 0,
+			Remaining:   item.ForSale(),
+			Featured:    item.Featured,
 		})
 	}
+	// Specials lead the feed; the stable sort keeps everything else in catalog order.
+	slices.SortStableFunc(menu, func(a, b order.MenuItem) int {
+		switch {
+		case a.Featured == b.Featured:
+			return 0
+		case a.Featured:
+			return -1
+		default:
+			return 1
+		}
+	})
 	return menu
 }
 
+// productExists lets batches reference only catalog entries that are still on offer.
+func (s *Server) productExists(ctx context.Context, id int64) (bool, error) {
+	products, err := s.products.List(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range products {
+		if p.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// storedBatch finds one batch by id; found is false when no batch has it.
+func (s *Server) storedBatch(ctx context.Context, id int64) (item inventory.Item, found bool, err error) {
+	items, err := s.inventory.List(ctx)
+	if err != nil {
+		return inventory.Item{}, false, err
+	}
+	for _, candidate := range items {
+		if candidate.ID == id {
+			return candidate, true, nil
+		}
+	}
+	return inventory.Item{}, false, nil
+}
+
+// batchDescription tells customers how fresh a batch is, in their language and date layout.
+func (s *Server) batchDescription(lang string, item inventory.Item) string {
+	bakedAt := item.BakedAt.Format(s.catalog.Text(lang, "menu.baked_at_layout"))
+	return strings.ReplaceAll(s.catalog.Text(lang, "menu.fresh_batch"), "{baked_at}", bakedAt)
+}
+
 // inventoryPayload keeps transport level parsing separate from core types.
 type inventoryPayload struct {
 	ID          int       `json:"id"`
+	ProductID   int64     `json:"product_id"`
 	Name        string    `json:"name"`
 	Category    string    `json:"category"`
 	BakedAtRaw  string    `json:"baked_at"`
@@ -455,53 +1154,64 @@ type inventoryPayload struct {
 }
 
 // Validate applies parsing to keep HTTP endpoints lean while reporting friendly errors.
-func (p *inventoryPayload) Validate() error {
+func (p *inventoryPayload) Validate(categories categoryRules) error {
 	if strings.TrimSpace(p.Name) == "" {
-		return errors.New("name is required")
+		return fieldError{Field: "name", Code: codeRequired, Message: "name is required"}
 	}
-	if strings.TrimSpace(p.Category) == "" {
-		return errors.New("category is required")
+	category, err := categories.check(p.Category)
+	if err != nil {
+		return err
 	}
+	p.Category = category
 	if strings.TrimSpace(p.BakedAtRaw) == "" {
-		return errors.New("baked_at is required")
+		return fieldError{Field: "baked_at", Code: codeRequired, Message: "baked_at is required"}
 	}
 	baked, err := time.Parse("2006-01-02 15:04", p.BakedAtRaw)
 	if err != nil {
-		return fmt.Errorf("invalid baked_at: %w", err)
+		return fieldError{Field: "baked_at", Code: codeInvalid, Message: fmt.Sprintf("invalid baked_at: %v", err)}
 	}
 	if strings.TrimSpace(p.PriceRaw) == "" {
-		return errors.New("price_rub is required")
+		return fieldError{Field: "price_rub", Code: codeRequired, Message: "price_rub is required"}
 	}
-	priceFloat, err := strconv.ParseFloat(strings.ReplaceAll(p.PriceRaw, ",", "."), 64)
+	price, err := money.Parse(p.PriceRaw)
 	if err != nil {
-		return fmt.Errorf("invalid price_rub: %w", err)
+		return fieldError{Field: "price_rub", Code: codeInvalid, Message: fmt.Sprintf("invalid price_rub: %v", err)}
 	}
-	qty, err := strconv.Atoi(strings.TrimSpace(p.QuantityRaw))
-	if err != nil || qty <= 0 {
-		return errors.New("quantity must be positive")
+	qty, fe := parseQuantity("quantity", p.QuantityRaw)
+	if fe != nil {
+		return *fe
+	}
+	if qty <= 0 {
+		return fieldError{Field: "quantity", Code: codeNotPositive, Message: "quantity must be positive"}
 	}
 	p.BakedAt = baked
-	p.PriceCents = int(priceFloat * 100)
+	p.PriceCents = int(price.Cents)
 	p.Quantity = qty
 	return nil
 }
 
 // inventoryResponse serializes items for the admin table.
 type inventoryResponse struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	Category string `json:"category"`
-	BakedAt  string `json:"baked_at"`
-	Price    string `json:"price"`
-	Quantity int    `json:"quantity"`
+	ID        int         `json:"id"`
+	ProductID int64       `json:"product_id"`
+	Name      string      `json:"name"`
+	Category  string      `json:"category"`
+	BakedAt   string      `json:"baked_at"`
+	Price     money.Money `json:"price"`
+	Quantity  int         `json:"quantity"`
+	Reserved  int         `json:"reserved"`
+	Image     string      `json:"image"`
+	Featured  bool        `json:"featured"`
 }
 
 // defaultMenu showcases signature goods when inventory has no entries.
-func defaultMenu() []order.MenuItem {
+// Hero items have no tracked stock, so they are always offered as available. Names and
+// descriptions are catalog keys that fallbackMenu translates for each visitor.
+func defaultMenu(currency string) []order.MenuItem {
 	return []order.MenuItem{
-		{Name: "Сливочный круассан", Description: "Слойки с фермерским маслом", Price: "220 ₽", Image: "classic", Category: "croissant"},
-		{Name: "Хрустящий багет", Description: "Пары хватает на утренний стол", Price: "160 ₽", Image: "baguette", Category: "bread"},
-		{Name: "Шоколадный десерт", Description: "Горький шоколад 70%", Price: "250 ₽", Image: "chocolate", Category: "pastry"},
+		{Name: "menu.hero.croissant", Description: "menu.hero.croissant_description", Price: money.In(22000, currency), Image: "classic", Category: "croissant", Available: true},
+		{Name: "menu.hero.baguette", Description: "menu.hero.baguette_description", Price: money.In(16000, currency), Image: "baguette", Category: "bread", Available: true},
+		{Name: "menu.hero.chocolate", Description: "menu.hero.chocolate_description", Price: money.In(25000, currency), Image: "chocolate", Category: "pastry", Available: true},
 	}
 }
 
@@ -518,10 +1228,3 @@ func imageForCategory(category string) string {
 		return "hero"
 	}
 }
-
-// formatPrice renders rubles with the ₽ sign.
-func formatPrice(cents int) string {
-	roubles := cents / 100
-	remainder := cents % 100
-	return fmt.Sprintf("%d,%02d ₽", roubles, remainder)
-}
@@ -0,0 +1,103 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bakery/pkg/i18n"
+	"bakery/pkg/inventory"
+)
+
+// pageServer is a quietServer that can also render the error page.
+func pageServer(t *testing.T) (*Server, *strings.Builder) {
+	t.Helper()
+	srv, logs := quietServer(t)
+	catalog, err := i18n.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.catalog = catalog
+	if srv.errorTmpl, err = parseErrorPage(templateFuncs(catalog, "RUB", "")); err != nil {
+		t.Fatal(err)
+	}
+	return srv, logs
+}
+
+func TestPageFailedLogsTheCauseOnly(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		wantType    string
+		wantInBody  string
+		wantJSONErr bool
+	}{
+		{"script", "*/*", "application/json", `"code":"internal"`, true},
+		{"browser", "text/html,application/xhtml+xml", "text/html; charset=utf-8", "Something went wrong in the kitchen", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, logs := pageServer(t)
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/admin?lang=en", nil)
+			req.Header.Set("Accept", tt.accept)
+			srv.pageFailed(rec, req, "admin", "failed to encode the menu", errors.New("json: unsupported value: NaN"))
+
+			body := rec.Body.String()
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want 500", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if !strings.Contains(body, tt.wantInBody) {
+				t.Errorf("body lacks %q:\n%s", tt.wantInBody, body)
+			}
+			if strings.Contains(body, "NaN") {
+				t.Errorf("body leaks the cause:\n%s", body)
+			}
+			if tt.wantJSONErr {
+				var reply map[string]string
+				if err := json.Unmarshal([]byte(body), &reply); err != nil || reply["error"] != "unable to render page" {
+					t.Errorf("reply = %v (%v), want the generic message", reply, err)
+				}
+			}
+			if !strings.Contains(logs.String(), "page admin failed to encode the menu: json: unsupported value: NaN") {
+				t.Errorf("log lacks the cause:\n%s", logs.String())
+			}
+		})
+	}
+}
+
+func TestMenuCopyFollowsTheLocale(t *testing.T) {
+	srv, _ := pageServer(t)
+	hero := defaultMenu("RUB")
+	srv.heroMenu.Store(&hero)
+	batch := inventory.Item{Name: "Багет", Category: "bread", BakedAt: time.Date(2026, time.October, 15, 6, 30, 0, 0, time.UTC)}
+
+	tests := []struct {
+		lang      string
+		wantHero  string
+		wantBatch string
+	}{
+		{"ru", "Сливочный круассан", "Свежая партия от 15.10 06:30"},
+		{"en", "Butter croissant", "Fresh batch from Oct 15 06:30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			if got := srv.fallbackMenu(tt.lang)[0].Name; got != tt.wantHero {
+				t.Errorf("hero item = %q, want %q", got, tt.wantHero)
+			}
+			if got := srv.batchDescription(tt.lang, batch); got != tt.wantBatch {
+				t.Errorf("batch description = %q, want %q", got, tt.wantBatch)
+			}
+		})
+	}
+	if got := hero[0].Name; got != "menu.hero.croissant" {
+		t.Errorf("translating changed the stored menu: %q", got)
+	}
+}
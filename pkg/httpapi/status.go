@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bakery/pkg/order"
+)
+
+// statusEndpoint answers PATCH /api/orders/{id}/status with {"status":"delivered"} or {"status":"cancelled"}.
+// Delivery turns the order's stock reservation into a deduction and cancellation releases it.
+func (s *Server) statusEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
+		id, ok := s.orderID(w, r)
+		if !ok {
+			return
+		}
+		if s.requireJSON(w, r) {
+			return
+		}
+		var payload struct {
+			Status string `json:"status"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			s.logger.Printf("order status change failed: unable to decode payload: %v", err)
+			s.respondValidation(w, r, fieldError{Code: codeInvalidJSON, Message: "invalid JSON"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		updated, err := s.orders.SetStatus(ctx, id, payload.Status)
+		s.respondOrderChange(w, r, "status change to "+payload.Status, id, updated, err)
+	})
+}
+
+// maxBulkStatusIDs caps one bulk request; a day's deliveries fit comfortably and the loop stays responsive.
+const maxBulkStatusIDs = 500
+
+// bulkStatusResult reports one order of a bulk change. Failures carry the same code and message the
+// single-order endpoint would have answered with, so the admin page can show them per row.
+type bulkStatusResult struct {
+	ID     int64  `json:"id"`
+	OK     bool   `json:"ok"`
+	Status string `json:"status,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// bulkStatusEndpoint answers POST /api/admin/orders/status with {"ids":[1,2,3],"status":"delivered"}.
+// Every order goes through the same checks as PATCH /api/orders/{id}/status; orders that are missing
+// or already finished are reported in "results" while the others still change, so the reply is 200
+// whenever the batch itself was well formed.
+func (s *Server) bulkStatusEndpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.writesDisabled(w, r) {
+			return
+		}
+		if s.requireJSON(w, r) {
+			return
+		}
+		var payload struct {
+			IDs    []int64 `json:"ids"`
+			Status string  `json:"status"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			s.logger.Printf("bulk order status change failed: unable to decode payload: %v", err)
+			s.respondValidation(w, r, fieldError{Code: codeInvalidJSON, Message: "invalid JSON"})
+			return
+		}
+		if len(payload.IDs) == 0 {
+			s.respondValidation(w, r, fieldError{Field: "ids", Code: codeRequired, Message: "ids are required"})
+			return
+		}
+		if len(payload.IDs) > maxBulkStatusIDs {
+			s.respondValidation(w, r, fieldError{Field: "ids", Code: codeInvalid, Message: "too many ids in one request"})
+			return
+		}
+		for i, id := range payload.IDs {
+			if id <= 0 {
+				s.respondValidation(w, r, fieldError{Field: fmt.Sprintf("ids[%d]", i), Code: codeNotPositive, Message: "invalid id"})
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+		results, err := s.orders.SetStatuses(ctx, payload.IDs, payload.Status)
+		if err != nil {
+			if order.IsValidation(err) {
+				s.respondValidation(w, r, orderFieldErrors(err)...)
+				return
+			}
+			s.logger.Printf("bulk order status change to %s failed: %v", payload.Status, err)
+			s.respondServiceError(w, err)
+			return
+		}
+
+		out := make([]bulkStatusResult, 0, len(results))
+		changed := 0
+		for _, res := range results {
+			entry := bulkStatusResult{ID: res.ID}
+			switch {
+			case res.Err == nil:
+				entry.OK, entry.Status = true, res.Order.Status
+				changed++
+			case errors.Is(res.Err, order.ErrNotFound):
+				entry.Code, entry.Error = errorCodeNotFound, s.text(r, res.Err.Error())
+			case order.IsValidation(res.Err):
+				entry.Code, entry.Error = order.ValidationCode(res.Err), s.text(r, res.Err.Error())
+			default:
+				s.logger.Printf("bulk order status change failed for %d: %v", res.ID, res.Err)
+				entry.Code, entry.Error = errorCodeInternal, res.Err.Error()
+			}
+			out = append(out, entry)
+		}
+		s.logger.Printf("bulk order status change to %s: %d of %d orders changed", strings.ToLower(strings.TrimSpace(payload.Status)), changed, len(results))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Changed int                `json:"changed"`
+			Failed  int                `json:"failed"`
+			Results []bulkStatusResult `json:"results"`
+		}{changed, len(results) - changed, out})
+	})
+}
@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"iter"
+	"net/http"
+)
+
+// streamJSONArray writes items as a JSON array one element at a time and reports how many it wrote.
+// json.Encoder.Encode on a slice marshals the whole array into one buffer before the first byte goes
+// out, so a long listing sat in memory twice; here only one element is encoded at a time, and with a
+// repository cursor as the source only one is decoded at a time too.
+//
+// Nothing is written until the first element has been fetched and encoded, so when the source fails
+// straight away count is zero and the caller can still answer with a proper error. Once the array is
+// open a failure can only cut it short, and the error is returned for the log.
+func streamJSONArray[T any](w http.ResponseWriter, items iter.Seq2[T, error]) (count int, err error) {
+	started := false
+	open := func() error {
+		w.Header().Set("Content-Type", "application/json")
+		started = true
+		_, err := w.Write([]byte{'['})
+		return err
+	}
+	for item, fetchErr := range items {
+		if fetchErr != nil {
+			return count, fetchErr
+		}
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return count, err
+		}
+		if !started {
+			err = open()
+		} else {
+			_, err = w.Write([]byte{','})
+		}
+		if err != nil {
+			return count, err
+		}
+		if _, err := w.Write(encoded); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if !started {
+		if err := open(); err != nil {
+			return count, err
+		}
+	}
+	_, err = w.Write([]byte("]\n"))
+	return count, err
+}
+
+// streamJSONLines writes items as newline-delimited JSON, one element per line, with the same
+// contract as streamJSONArray: nothing is written before the first element is ready, so a count of
+// zero with an error means the caller can still answer with an error. An empty source writes an
+// empty body.
+func streamJSONLines[T any](w http.ResponseWriter, items iter.Seq2[T, error]) (count int, err error) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	for item, fetchErr := range items {
+		if fetchErr != nil {
+			return count, fetchErr
+		}
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return count, err
+		}
+		if _, err := w.Write(append(encoded, '\n')); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// sliceItems adapts an already loaded slice for streamJSONArray.
+func sliceItems[T any](items []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// mapItems converts each element on its way to streamJSONArray, so response shapes never pile up
+// in a second slice.
+func mapItems[S any, T any](items iter.Seq2[S, error], convert func(S) T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for item, err := range items {
+			var out T
+			if err == nil {
+				out = convert(item)
+			}
+			if !yield(out, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// localesFS embeds every catalog so translations ship inside the single binary.
+//
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// DefaultLocale is used whenever a visitor asks for a language we do not ship.
+const DefaultLocale = "ru"
+
+// Catalog maps locale codes to their message tables; it is read-only after Load so handlers can share it freely.
+type Catalog struct {
+	messages map[string]map[string]string
+}
+
+// Load parses the embedded catalogs once at startup so malformed translations fail fast.
+func Load() (*Catalog, error) {
+	files, err := fs.Glob(localesFS, "locales/*.json")
+	if err != nil {
+		return nil, err
+	}
+	catalog := &Catalog{messages: make(map[string]map[string]string, len(files))}
+	for _, file := range files {
+		data, err := localesFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var table map[string]string
+		if err := json.Unmarshal(data, &table); err != nil {
+			return nil, fmt.Errorf("parse catalog %s: %w", file, err)
+		}
+		catalog.messages[strings.TrimSuffix(path.Base(file), ".json")] = table
+	}
+	if _, ok := catalog.messages[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("default locale %q catalog is missing", DefaultLocale)
+	}
+	return catalog, nil
+}
+
+// Text returns the translation for key, falling back to Russian and finally to the key itself.
+func (c *Catalog) Text(locale, key string) string {
+	if msg, ok := c.messages[locale][key]; ok {
+		return msg
+	}
+	if msg, ok := c.messages[DefaultLocale][key]; ok {
+		return msg
+	}
+	return key
+}
+
+// Negotiate picks a supported locale from an explicit choice (the ?lang= parameter) or the Accept-Language header.
+func (c *Catalog) Negotiate(explicit, acceptLanguage string) string {
+	if locale := c.supported(explicit); locale != "" {
+		return locale
+	}
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if locale := c.supported(tag); locale != "" {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// supported reduces tags like "en-US" to their primary language and reports whether a catalog exists for it.
+func (c *Catalog) supported(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	if _, ok := c.messages[primary]; ok {
+		return primary
+	}
+	return ""
+}
+
+// parseAcceptLanguage orders the header's language tags by their q weight, keeping header order for ties.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		out = append(out, t.tag)
+	}
+	return out
+}
@@ -0,0 +1,24 @@
+package inventory
+
+import "bakery/pkg/metrics"
+
+// recordOutcome feeds the breaker and, on a storage failure, the last error the debug endpoint shows.
+func (s *Service) recordOutcome(err error) {
+	failed := storageFailure(err)
+	s.breaker.Record(failed)
+	if failed {
+		s.activity.Fail(s.clock.Now(), err)
+	}
+}
+
+// busy notes a call that timed out queueing, then returns ErrQueueBusy.
+func (s *Service) busy() error {
+	s.activity.Fail(s.clock.Now(), ErrQueueBusy)
+	return ErrQueueBusy
+}
+
+// Activity reports inventory calls in flight and the last error; like order.Service.Activity it
+// never waits for the goroutine.
+func (s *Service) Activity() metrics.ActivityReport {
+	return s.activity.Report()
+}
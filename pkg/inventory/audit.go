@@ -0,0 +1,248 @@
+package inventory
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"bakery/pkg/events"
+)
+
+// defaultAuditSweep spaces out the pruning of old audit entries; they only need to go eventually.
+const defaultAuditSweep = time.Hour
+
+// maxAuditLine bounds one line of the audit file when reading it back; a batch is far smaller.
+const maxAuditLine = 1 << 20
+
+// auditActions names loop actions the way the audit log shows them; stock actions keep their own names.
+var auditActions = map[string]string{
+	"save":         "create",
+	"saveUnique":   "create",
+	"adjustPrices": "adjust_prices",
+}
+
+// ErrHistoryOff is returned by History when the service was started without an audit file.
+var ErrHistoryOff = errors.New("inventory history is not enabled")
+
+// AuditEntry is one line of the inventory audit log: what happened to which batch, when, and who
+// asked for it, as tagged by events.WithActor. Before is nil for a batch that was just created and After is nil for one that was
+// deleted, so every other change shows both sides.
+type AuditEntry struct {
+	At     time.Time `json:"at"`
+	Action string    `json:"action"`
+	ItemID int64     `json:"item_id"`
+	Actor  string    `json:"actor,omitempty"`
+	Before *Item     `json:"before,omitempty"`
+	After  *Item     `json:"after,omitempty"`
+}
+
+// historyQuery asks the goroutine for the audit entries of one batch.
+type historyQuery struct {
+	ctx   context.Context
+	id    int64
+	reply chan historyResult
+}
+
+// historyResult carries the entries back, oldest first.
+type historyResult struct {
+	entries []AuditEntry
+	err     error
+}
+
+// History returns every audit entry still kept for a batch, oldest first. It works for deleted
+// batches too, which is usually when someone wants to know what happened.
+func (s *Service) History(ctx context.Context, id int64) ([]AuditEntry, error) {
+	if s.auditPath == "" {
+		return nil, ErrHistoryOff
+	}
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan historyResult, 1)
+	q := historyQuery{ctx: ctx, id: id, reply: reply}
+
+	select {
+	case s.historyCalls <- q:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return nil, s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res.entries, res.err
+	case <-ctx.Done():
+		return nil, waitErr(ctx)
+	}
+}
+
+// auditSnapshot captures the batches before a mutation so audit can tell what it changed.
+// It returns nil when auditing is off or the read failed; the mutation still goes ahead.
+func (s *Service) auditSnapshot(ctx context.Context) map[int64]Item {
+	if s.auditPath == "" {
+		return nil
+	}
+	items, err := s.repo.List(context.WithoutCancel(ctx))
+	if err != nil {
+		s.logger.Printf("inventory audit: reading batches before a change failed: %v", err)
+		return nil
+	}
+	byID := make(map[int64]Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	return byID
+}
+
+// audit compares the batches after a mutation with the snapshot taken before it and appends one
+// entry per batch that differs. Diffing instead of trusting each action keeps bulk changes such as
+// price adjustments and stock reservations covered without every action describing itself. A failed
+// write is logged rather than returned, because the change it describes has already happened.
+func (s *Service) audit(ctx context.Context, action string, before map[int64]Item) {
+	if before == nil {
+		return
+	}
+	items, err := s.repo.List(context.WithoutCancel(ctx))
+	if err != nil {
+		s.logger.Printf("inventory audit: reading batches after %s failed: %v", action, err)
+		return
+	}
+	if name, ok := auditActions[action]; ok {
+		action = name
+	}
+	now := s.clock.Now().UTC()
+	actor := events.Actor(ctx)
+	var entries []AuditEntry
+	for _, item := range items {
+		old, existed := before[item.ID]
+		delete(before, item.ID)
+		if existed && sameItem(old, item) {
+			continue
+		}
+		entry := AuditEntry{At: now, Action: action, ItemID: item.ID, Actor: actor, After: &item}
+		if existed {
+			entry.Before = &old
+		}
+		entries = append(entries, entry)
+	}
+	for id, old := range before {
+		entries = append(entries, AuditEntry{At: now, Action: action, ItemID: id, Actor: actor, Before: &old})
+	}
+	if len(entries) == 0 {
+		return
+	}
+	slices.SortFunc(entries, func(a, b AuditEntry) int { return cmp.Compare(a.ItemID, b.ItemID) })
+	if err := appendAudit(s.auditPath, entries); err != nil {
+		s.logger.Printf("inventory audit: writing %d entries to %s failed: %v", len(entries), s.auditPath, err)
+	}
+}
+
+// sameItem compares two batches field by field; bake times are compared as instants.
+func sameItem(a, b Item) bool {
+	if !a.BakedAt.Equal(b.BakedAt) {
+		return false
+	}
+	a.BakedAt, b.BakedAt = time.Time{}, time.Time{}
+	return a == b
+}
+
+// appendAudit writes one JSON line per entry so the log grows without being rewritten on every change.
+func appendAudit(path string, entries []AuditEntry) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// readAudit returns the entries that keep reports true for, in file order. A missing file is an
+// empty log, since nothing has changed yet.
+func readAudit(path string, keep func(AuditEntry) bool) ([]AuditEntry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxAuditLine)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		if keep(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// readHistory runs on the goroutine so a history read never sees half of a prune.
+func (s *Service) readHistory(id int64) ([]AuditEntry, error) {
+	return readAudit(s.auditPath, func(entry AuditEntry) bool { return entry.ItemID == id })
+}
+
+// pruneAudit drops entries older than the retention window. The survivors are written to a
+// temporary file that replaces the log, so a crash mid-prune leaves the old log intact.
+func (s *Service) pruneAudit() {
+	cutoff := s.clock.Now().UTC().Add(-s.auditRetention)
+	dropped := 0
+	kept, err := readAudit(s.auditPath, func(entry AuditEntry) bool {
+		if entry.At.Before(cutoff) {
+			dropped++
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		s.logger.Printf("inventory audit: reading %s for pruning failed: %v", s.auditPath, err)
+		return
+	}
+	if dropped == 0 {
+		return
+	}
+	temp, err := os.CreateTemp(filepath.Dir(s.auditPath), filepath.Base(s.auditPath)+".*")
+	if err != nil {
+		s.logger.Printf("inventory audit: pruning %s failed: %v", s.auditPath, err)
+		return
+	}
+	encoder := json.NewEncoder(temp)
+	for _, entry := range kept {
+		if err = encoder.Encode(entry); err != nil {
+			break
+		}
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), s.auditPath)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		s.logger.Printf("inventory audit: pruning %s failed: %v", s.auditPath, err)
+		return
+	}
+	s.logger.Printf("inventory audit: removed %d entries older than %s", dropped, cutoff.Format(time.RFC3339))
+}
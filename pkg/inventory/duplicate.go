@@ -0,0 +1,71 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DuplicateError names the stored batch a new one would duplicate, so the admin can be pointed at it.
+type DuplicateError struct {
+	Existing Item
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%v: batch %d", ErrDuplicate, e.Existing.ID)
+}
+
+// Unwrap lets callers match the error with errors.Is(err, ErrDuplicate).
+func (e *DuplicateError) Unwrap() error { return ErrDuplicate }
+
+// FindSimilar looks up a batch with the same name, category and baking time, which is how a batch
+// entered twice by accident looks. found is false when there is none.
+func (r *Repository) FindSimilar(ctx context.Context, item Item) (Item, bool, error) {
+	query := "SELECT id, name, category, available_count, price_cents, baked_at, product_id, reserved_count, image, featured FROM inventory WHERE name = ? AND category = ? AND baked_at = ?"
+	rows, err := r.db.QueryContext(ctx, query, item.Name, item.Category, item.BakedAt.UTC())
+	if err != nil {
+		return Item{}, false, err
+	}
+	items, err := scanItems(rows)
+	if err != nil || len(items) == 0 {
+		return Item{}, false, err
+	}
+	return items[0], true, nil
+}
+
+// AddUnique stores the batch unless a similar one exists, in which case it returns a *DuplicateError.
+// The lookup and the insert run as one step of the loop, so two identical submissions racing each
+// other cannot both get in.
+func (s *Service) AddUnique(ctx context.Context, item Item) (Item, error) {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, action: "saveUnique", item: item, reply: reply}
+
+	select {
+	case s.commands <- cmd:
+	case <-ctx.Done():
+		return Item{}, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return Item{}, s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res.item, res.err
+	case <-ctx.Done():
+		return Item{}, waitErr(ctx)
+	}
+}
+
+// saveUnique runs inside the loop for AddUnique.
+func (s *Service) saveUnique(ctx context.Context, item Item) (Item, error) {
+	existing, found, err := s.repo.FindSimilar(ctx, item)
+	if err != nil {
+		return Item{}, err
+	}
+	if found {
+		return Item{}, &DuplicateError{Existing: existing}
+	}
+	return s.repo.Save(ctx, item)
+}
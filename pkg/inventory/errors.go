@@ -1,6 +1,55 @@
 package inventory
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"bakery/pkg/breaker"
+)
 
 // ErrNotFound is returned when an item is missing so HTTP handlers can respond with 404.
 var ErrNotFound = errors.New("inventory item not found")
+
+// ErrFeaturedLimit refuses to feature another batch once Options.MaxFeatured are featured.
+var ErrFeaturedLimit = errors.New("too many featured items")
+
+// ErrDuplicate refuses a batch whose name, category and baking time match one already stored.
+var ErrDuplicate = errors.New("a batch with the same name, category and baking time already exists")
+
+// ErrNegativePrice rejects a price adjustment that would push any batch below zero.
+var ErrNegativePrice = errors.New("price adjustment would make a price negative")
+
+// ErrQueueBusy is returned when the service goroutine did not take a call within two seconds; like
+// order.ErrQueueBusy it is backpressure worth retrying.
+var ErrQueueBusy = errors.New("inventory queue is busy")
+
+// ErrProcessingTimeout is returned when the goroutine took a call but the caller's deadline passed
+// before it answered. It wraps context.DeadlineExceeded, like order.ErrProcessingTimeout.
+var ErrProcessingTimeout = errors.New("inventory processing took too long")
+
+// waitErr reports a passed deadline while waiting for the goroutine as a processing timeout and a
+// cancelled request as is.
+func waitErr(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrProcessingTimeout, ctx.Err())
+	}
+	return ctx.Err()
+}
+
+// storageFailure tells the breaker whether a request failed because storage did. Missing batches,
+// duplicates and refused changes are answers from a healthy backend, and a cancelled request says
+// nothing either way.
+func storageFailure(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrDuplicate):
+		return false
+	case errors.Is(err, ErrFeaturedLimit), errors.Is(err, ErrNegativePrice):
+		return false
+	case errors.Is(err, context.Canceled), errors.Is(err, breaker.ErrUnavailable):
+		return false
+	}
+	return true
+}
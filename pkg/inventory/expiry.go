@@ -0,0 +1,125 @@
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bakery/pkg/events"
+)
+
+// EventTopic is the events.Event topic the inventory service publishes under.
+const EventTopic = "inventory"
+
+// EventExpired is published once per batch that outlived its shelf life with units still on hand.
+const EventExpired = "expired"
+
+// DefaultShelfLife is how long a batch counts as fresh after baking when nothing else is configured.
+const DefaultShelfLife = 24 * time.Hour
+
+// expiryTimeout bounds one expiry check, and webhookTimeout one webhook delivery, so neither a slow
+// database nor a slow receiver can hold the inventory goroutine or pile up requests.
+const (
+	expiryTimeout  = 30 * time.Second
+	webhookTimeout = 10 * time.Second
+)
+
+// ExpiredBatch is what staff are told about a batch to pull from the shelf.
+type ExpiredBatch struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Category  string    `json:"category"`
+	Quantity  int       `json:"quantity"`
+	BakedAt   time.Time `json:"baked_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+// Expired reports whether the batch is past its shelf life at now. A zero shelf life never expires.
+func (i Item) Expired(now time.Time, shelfLife time.Duration) bool {
+	return shelfLife > 0 && !i.BakedAt.Add(shelfLife).After(now)
+}
+
+// checkExpiry reports batches that went stale since the last check. It runs on the service
+// goroutine, so the notified set needs no locking and a check never interleaves with a write.
+// Each batch is reported once: the set remembers it until the batch is sold out, deleted or
+// rebaked, which is also what keeps the set from growing for ever.
+func (s *Service) checkExpiry() {
+	ctx, cancel := context.WithTimeout(context.Background(), expiryTimeout)
+	defer cancel()
+
+	items, err := s.repo.List(ctx)
+	if err != nil {
+		s.logger.Printf("inventory expiry: listing batches failed: %v", err)
+		return
+	}
+	now := s.clock.Now().UTC()
+	stale := make(map[int64]bool, len(s.notified))
+	var fresh []ExpiredBatch
+	for _, item := range items {
+		if item.AvailableCount <= 0 || !item.Expired(now, s.shelfLife) {
+			continue
+		}
+		stale[item.ID] = true
+		if s.notified[item.ID] {
+			continue
+		}
+		fresh = append(fresh, ExpiredBatch{
+			ID:        item.ID,
+			Name:      item.Name,
+			Category:  item.Category,
+			Quantity:  item.AvailableCount,
+			BakedAt:   item.BakedAt,
+			ExpiredAt: item.BakedAt.Add(s.shelfLife),
+		})
+	}
+	s.notified = stale
+	if len(fresh) == 0 {
+		return
+	}
+
+	for _, batch := range fresh {
+		detail := fmt.Sprintf("%s baked %s, %d left", batch.Name, batch.BakedAt.Format("2006-01-02 15:04"), batch.Quantity)
+		s.logger.Printf("inventory expiry: pull batch %d from the shelf: %s", batch.ID, detail)
+		s.events.Publish(events.Event{
+			At:        now,
+			Topic:     EventTopic,
+			SubjectID: batch.ID,
+			Type:      EventExpired,
+			Detail:    detail,
+		})
+	}
+	if s.expiryWebhook != "" {
+		// The receiver is someone else's server, so it gets its own goroutine rather than the
+		// inventory queue's time.
+		go s.postExpired(s.expiryWebhook, fresh)
+	}
+}
+
+// postExpired sends one expiry check's batches to the webhook as a JSON array.
+func (s *Service) postExpired(url string, batches []ExpiredBatch) {
+	body, err := json.Marshal(batches)
+	if err != nil {
+		s.logger.Printf("inventory expiry: encoding webhook body failed: %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		s.logger.Printf("inventory expiry: webhook %s: %v", url, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.logger.Printf("inventory expiry: webhook %s failed: %v", url, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		s.logger.Printf("inventory expiry: webhook %s answered %s", url, resp.Status)
+	}
+}
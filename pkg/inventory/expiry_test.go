@@ -0,0 +1,85 @@
+package inventory
+
+import (
+	"context"
+	"io"
+	"log"
+	"slices"
+	"testing"
+	"time"
+
+	"bakery/pkg/clock"
+	"bakery/pkg/events"
+	"bakery/pkg/storage/memorydriver"
+)
+
+// expiredSince runs one expiry check and returns the ids of the batches it published. A marker
+// published after the check tells when the bus has delivered everything the check sent.
+func expiredSince(t *testing.T, svc *Service, feed <-chan events.Event) []int64 {
+	t.Helper()
+	svc.checkExpiry()
+	svc.events.Publish(events.Event{Topic: EventTopic, Type: "marker"})
+	var ids []int64
+	for {
+		select {
+		case event := <-feed:
+			if event.Type == "marker" {
+				return ids
+			}
+			if event.Type != EventExpired {
+				t.Fatalf("unexpected event %+v", event)
+			}
+			ids = append(ids, event.SubjectID)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the marker event never arrived")
+		}
+	}
+}
+
+func TestCheckExpiryAsTheClockMovesForward(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 6, 0, 0, 0, time.UTC)
+	clk := clock.NewManual(now)
+	repo := NewRepository(memorydriver.OpenTest(t, clk), clk)
+	save := func(name string, count int, bakedAgo time.Duration) int64 {
+		t.Helper()
+		item, err := repo.Save(context.Background(), Item{Name: name, Category: "bread", AvailableCount: count, PriceCents: 9000, BakedAt: now.Add(-bakedAgo)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return item.ID
+	}
+	old := save("Бородинский", 6, 20*time.Hour)
+	fresh := save("Багет", 10, 2*time.Hour)
+	save("Чиабатта", 0, 30*time.Hour) // stale but sold out, so there is nothing to pull
+
+	bus := events.NewBus()
+	defer bus.Close()
+	feed := bus.Subscribe(EventTopic)
+	// The service is built without its loop so the test drives every check itself.
+	svc := &Service{
+		repo:      repo,
+		clock:     clk,
+		logger:    log.New(io.Discard, "", 0),
+		shelfLife: DefaultShelfLife,
+		events:    bus,
+		notified:  make(map[int64]bool),
+	}
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		want    []int64
+	}{
+		{"everything on the shelf is fresh", 0, nil},
+		{"the old batch reaches its shelf life", 4 * time.Hour, []int64{old}},
+		{"a batch already reported stays quiet", time.Hour, nil},
+		{"the fresh batch goes stale a day after baking", 17 * time.Hour, []int64{fresh}},
+		{"nothing new to report", 24 * time.Hour, nil},
+	}
+	for _, step := range steps {
+		clk.Advance(step.advance)
+		if got := expiredSince(t, svc, feed); !slices.Equal(got, step.want) {
+			t.Errorf("%s (at %s): expired %v, want %v", step.name, clk.Now().Format(time.DateTime), got, step.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package inventory
+
+import (
+	"context"
+	"time"
+)
+
+// Feature marks a batch as a daily special, or clears the mark when featured is false.
+// Featuring one more batch than Options.MaxFeatured allows fails with ErrFeaturedLimit.
+func (s *Service) Feature(ctx context.Context, id int64, featured bool) error {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, action: "feature", item: Item{ID: id, Featured: featured}, reply: reply}
+
+	select {
+	case s.commands <- cmd:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(2 * time.Second):
+		return s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res.err
+	case <-ctx.Done():
+		return waitErr(ctx)
+	}
+}
+
+// applyFeature runs inside the loop, so counting the featured batches and adding one cannot race
+// with another admin doing the same. Re-featuring an already featured batch always succeeds.
+func (s *Service) applyFeature(ctx context.Context, id int64, featured bool) error {
+	if featured && s.maxFeatured > 0 {
+		items, err := s.repo.List(ctx)
+		if err != nil {
+			return err
+		}
+		others, found := 0, false
+		for _, item := range items {
+			if item.ID == id {
+				found = true
+			} else if item.Featured {
+				others++
+			}
+		}
+		if !found {
+			return ErrNotFound
+		}
+		if others >= s.maxFeatured {
+			return ErrFeaturedLimit
+		}
+	}
+	return s.repo.SetFeatured(ctx, id, featured)
+}
@@ -3,12 +3,31 @@ package inventory
 import "time"
 
 // Item captures a single batch baked by the team so the admin interface can track freshness.
+// ProductID links the batch to a catalog entry; zero means the batch is listed on its own.
+// ReservedCount is the part of AvailableCount promised to pending orders and no longer for sale.
+// Image is the URL of an uploaded photo; empty means the storefront shows a placeholder.
+// Featured batches are the daily specials the storefront lists first and draws as the hero card.
 type Item struct {
 	ID             int64     `json:"id"`
+	ProductID      int64     `json:"product_id"`
 	Name           string    `json:"name"`
 	Category       string    `json:"category"`
 	AvailableCount int       `json:"available_count"`
+	ReservedCount  int       `json:"reserved_count"`
 	PriceCents     int       `json:"price_cents"`
+	Image          string    `json:"image"`
+	Featured       bool      `json:"featured"`
 	BakedAt        time.Time `json:"baked_at"`
 	CreatedAt      time.Time `json:"created_at"`
 }
+
+// ForSale is what the storefront may still sell from the batch.
+func (i Item) ForSale() int {
+	return max(i.AvailableCount-i.ReservedCount, 0)
+}
+
+// CategoryCount reports how many batches share a category so the storefront can draw its tabs.
+type CategoryCount struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
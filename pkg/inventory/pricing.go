@@ -0,0 +1,63 @@
+package inventory
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// PriceAdjustment reprices every batch in a category at once, e.g. "10% off all croissants".
+// Percent is applied first and rounded to whole cents, then DeltaCents is added.
+type PriceAdjustment struct {
+	Category   string
+	Percent    float64
+	DeltaCents int
+}
+
+// Multiplier is the factor the percentage scales prices by.
+func (a PriceAdjustment) Multiplier() float64 {
+	return 1 + a.Percent/100
+}
+
+// Apply returns the adjusted price; the repository's UPDATE computes the same value in SQL.
+func (a PriceAdjustment) Apply(cents int) int {
+	return int(math.Round(float64(cents)*a.Multiplier())) + a.DeltaCents
+}
+
+// AdjustPrices reprices a whole category and reports how many batches changed. Nothing is written
+// when any batch would end up below zero, so a promotion never half-applies.
+func (s *Service) AdjustPrices(ctx context.Context, adj PriceAdjustment) (int64, error) {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, action: "adjustPrices", adjust: adj, reply: reply}
+
+	select {
+	case s.commands <- cmd:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return 0, s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res.affected, res.err
+	case <-ctx.Done():
+		return 0, waitErr(ctx)
+	}
+}
+
+// applyAdjustment runs inside the loop, so the negative-price check and the bulk write see the same rows.
+func (s *Service) applyAdjustment(ctx context.Context, adj PriceAdjustment) (int64, error) {
+	items, err := s.repo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range items {
+		if item.Category == adj.Category && adj.Apply(item.PriceCents) < 0 {
+			return 0, ErrNegativePrice
+		}
+	}
+	return s.repo.AdjustPrices(ctx, adj)
+}
@@ -3,23 +3,29 @@ package inventory
 import (
 	"context"
 	"database/sql"
-	"time"
+	"iter"
+
+	"bakery/pkg/clock"
 )
 
 // Repository persists items through database/sql so storage backends stay swappable.
 type Repository struct {
-	db *sql.DB
+	db    *sql.DB
+	clock clock.Clock
 }
 
 // NewRepository wires the handle so goroutines can work without sharing mutable state.
-func NewRepository(db *sql.DB) *Repository {
-	return &Repository{db: db}
+// clk stamps CreatedAt on saved records; nil uses the system clock.
+func NewRepository(db *sql.DB, clk clock.Clock) *Repository {
+	return &Repository{db: db, clock: clock.OrReal(clk)}
 }
 
-// Save inserts a freshly baked batch so the storefront can expose it immediately.
+// Save inserts a freshly baked batch so the storefront can expose it immediately. Reserved units,
+// the photo and the featured flag are stored as given: a new batch has none of them, while a
+// restored one brings them from the backup.
 func (r *Repository) Save(ctx context.Context, item Item) (Item, error) {
-	query := "INSERT INTO inventory (name, category, available_count, price_cents, baked_at) VALUES (?, ?, ?, ?, ?)"
-	result, err := r.db.ExecContext(ctx, query, item.Name, item.Category, item.AvailableCount, item.PriceCents, item.BakedAt.UTC())
+	query := "INSERT INTO inventory (name, category, available_count, price_cents, baked_at, product_id, reserved_count, image, featured) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	result, err := r.db.ExecContext(ctx, query, item.Name, item.Category, item.AvailableCount, item.PriceCents, item.BakedAt.UTC(), item.ProductID, item.ReservedCount, item.Image, item.Featured)
 	if err != nil {
 		return Item{}, err
 	}
@@ -28,35 +34,95 @@ func (r *Repository) Save(ctx context.Context, item Item) (Item, error) {
 		return Item{}, err
 	}
 	item.ID = id
-	item.CreatedAt = time.Now().UTC()
+	item.CreatedAt = r.clock.Now().UTC()
 	return item, nil
 }
 
 // List fetches every batch so both the admin and the landing page stay in sync.
 func (r *Repository) List(ctx context.Context) ([]Item, error) {
-	query := "SELECT id, name, category, available_count, price_cents, baked_at FROM inventory ORDER BY baked_at DESC"
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, listStatement)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return scanItems(rows)
+}
 
+// ListCursor runs the List query but decodes each batch only when the cursor reaches it. The query
+// runs before it returns, so a failing database is reported here rather than mid-stream; the cursor
+// holds the rows open until it is ranged to the end or broken out of, so range over it exactly once.
+func (r *Repository) ListCursor(ctx context.Context) (iter.Seq2[Item, error], error) {
+	rows, err := r.db.QueryContext(ctx, listStatement)
+	if err != nil {
+		return nil, err
+	}
+	return itemCursor(rows), nil
+}
+
+// listStatement is the projection List and ListCursor read, newest bake first.
+const listStatement = "SELECT id, name, category, available_count, price_cents, baked_at, product_id, reserved_count, image, featured FROM inventory ORDER BY baked_at DESC"
+
+// scanItems decodes the shared inventory projection so every listing query reads rows the same way.
+func scanItems(rows *sql.Rows) ([]Item, error) {
 	var items []Item
-	for rows.Next() {
-		var item Item
-		var bakedAt time.Time
-		if err := rows.Scan(&item.ID, &item.Name, &item.Category, &item.AvailableCount, &item.PriceCents, &bakedAt); err != nil {
+	for item, err := range itemCursor(rows) {
+		if err != nil {
 			return nil, err
 		}
-		item.BakedAt = bakedAt.UTC()
 		items = append(items, item)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
 	return items, nil
 }
 
+// itemCursor decodes the rows one batch per step and closes them once the loop ends, including when
+// the caller breaks out early. A scan error is yielded once and ends the cursor.
+func itemCursor(rows *sql.Rows) iter.Seq2[Item, error] {
+	return func(yield func(Item, error) bool) {
+		defer rows.Close()
+		for rows.Next() {
+			item, err := scanItem(rows)
+			if err != nil {
+				yield(Item{}, err)
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(Item{}, err)
+		}
+	}
+}
+
+// scanItem decodes the row rows is positioned on. Only the id is guaranteed; batches written before
+// a column existed hold NULL there, which reads as the zero value: no category, no bake time, not
+// linked to a product.
+func scanItem(rows *sql.Rows) (Item, error) {
+	var (
+		item                  Item
+		name, category, image sql.NullString
+		available, priceCents sql.NullInt64
+		bakedAt               sql.NullTime
+		productID, reserved   sql.NullInt64
+		featured              sql.NullBool
+	)
+	if err := rows.Scan(&item.ID, &name, &category, &available, &priceCents, &bakedAt, &productID, &reserved, &image, &featured); err != nil {
+		return Item{}, err
+	}
+	item.Name = name.String
+	item.Category = category.String
+	item.AvailableCount = int(available.Int64)
+	item.PriceCents = int(priceCents.Int64)
+	if bakedAt.Valid {
+		item.BakedAt = bakedAt.Time.UTC()
+	}
+	item.ProductID = productID.Int64
+	item.ReservedCount = int(reserved.Int64)
+	item.Image = image.String
+	item.Featured = featured.Bool
+	return item, nil
+}
+
 // Update refreshes count and pricing so the admin can reflect sold or discounted goods quickly.
 func (r *Repository) Update(ctx context.Context, item Item) error {
 	query := "UPDATE inventory SET available_count = ?, price_cents = ?, baked_at = ? WHERE id = ?"
@@ -74,6 +140,64 @@ func (r *Repository) Update(ctx context.Context, item Item) error {
 	return nil
 }
 
+// AdjustPrices applies a price adjustment to every batch in its category and returns how many changed.
+func (r *Repository) AdjustPrices(ctx context.Context, adj PriceAdjustment) (int64, error) {
+	query := "UPDATE inventory SET price_cents = ROUND(price_cents * ?) + ? WHERE category = ?"
+	result, err := r.db.ExecContext(ctx, query, adj.Multiplier(), adj.DeltaCents, adj.Category)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SetStock writes both counters of a batch at once, which is all reservations ever change.
+func (r *Repository) SetStock(ctx context.Context, id int64, available, reserved int) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE inventory SET available_count = ?, reserved_count = ? WHERE id = ?", available, reserved, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetImage points a batch at its uploaded photo; an empty url goes back to the placeholder.
+func (r *Repository) SetImage(ctx context.Context, id int64, url string) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE inventory SET image = ? WHERE id = ?", url, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetFeatured marks or unmarks a batch as a featured special.
+func (r *Repository) SetFeatured(ctx context.Context, id int64, featured bool) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE inventory SET featured = ? WHERE id = ?", featured, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 // Delete removes a batch entirely which is handy once everything is sold out.
 func (r *Repository) Delete(ctx context.Context, id int64) error {
 	result, err := r.db.ExecContext(ctx, "DELETE FROM inventory WHERE id = ?", id)
@@ -89,3 +213,48 @@ func (r *Repository) Delete(ctx context.Context, id int64) error {
 	}
 	return nil
 }
+
+// Count reports how many batches are stored without loading them, for pagination totals and dashboards.
+func (r *Repository) Count(ctx context.Context) (int64, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM inventory").Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// TotalValue sums price times available count over every batch, in cents, for the stock value stat.
+func (r *Repository) TotalValue(ctx context.Context) (int64, error) {
+	var total sql.NullInt64
+	if err := r.db.QueryRowContext(ctx, "SELECT SUM(price_cents * available_count) FROM inventory").Scan(&total); err != nil {
+		return 0, err
+	}
+	// SQL engines return NULL for SUM over no rows; an empty bakery simply holds nothing of value.
+	return total.Int64, nil
+}
+
+// Categories groups the batches by category, in alphabetical order, without loading the rows themselves.
+func (r *Repository) Categories(ctx context.Context) ([]CategoryCount, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT category, COUNT(*) FROM inventory GROUP BY category ORDER BY category")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []CategoryCount{}
+	for rows.Next() {
+		var (
+			category sql.NullString
+			entry    CategoryCount
+		)
+		if err := rows.Scan(&category, &entry.Count); err != nil {
+			return nil, err
+		}
+		entry.Category = category.String
+		counts = append(counts, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
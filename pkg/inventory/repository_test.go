@@ -0,0 +1,50 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bakery/pkg/clock"
+	"bakery/pkg/storage/memorydriver"
+)
+
+func TestListCursorClosesRowsOnEarlyBreak(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 6, 0, 0, 0, time.UTC)
+	clk := clock.NewManual(now)
+	db := memorydriver.OpenTest(t, clk)
+	// With one connection, rows left open would hold it and the next query would wait for it.
+	db.SetMaxOpenConns(1)
+	repo := NewRepository(db, clk)
+	for i := range 4 {
+		item := Item{Name: "Багет", Category: "bread", AvailableCount: 10, PriceCents: 12000, BakedAt: now.Add(-time.Duration(i) * time.Hour)}
+		if _, err := repo.Save(context.Background(), item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, breakAfter := range []int{1, 3} {
+		// Every query gets a deadline, so rows left open fail the test instead of hanging it.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		cursor, err := repo.ListCursor(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := 0
+		for _, err := range cursor {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seen++; seen == breakAfter {
+				break
+			}
+		}
+		if inUse := db.Stats().InUse; inUse != 0 {
+			t.Errorf("break after %d: %d connections still in use", breakAfter, inUse)
+		}
+		if _, err := repo.Count(ctx); err != nil {
+			t.Errorf("break after %d: next query failed, the rows were left open: %v", breakAfter, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Reservation actions handled by the service loop.
+const (
+	reserveStock = "reserve"
+	releaseStock = "release"
+	deductStock  = "deduct"
+)
+
+// stockCommand carries a reservation change; wanted is keyed by item name, held by batch id.
+type stockCommand struct {
+	ctx    context.Context
+	action string
+	wanted map[string]int
+	held   map[int64]int
+	reply  chan stockResult
+}
+
+// stockResult reports the batches that were held, or the item name that ran short.
+type stockResult struct {
+	held  map[int64]int
+	short string
+	err   error
+}
+
+// Reserve holds units for every wanted item name so pending orders cannot be oversold.
+// Units come from the freshest batches first. Names without any batch are left alone because
+// freeform items are not tracked; when a tracked name lacks free units nothing is held and
+// short names it. The returned map is what Release or Deduct later need.
+func (s *Service) Reserve(ctx context.Context, wanted map[string]int) (map[int64]int, string, error) {
+	res, err := s.sendStock(ctx, stockCommand{ctx: ctx, action: reserveStock, wanted: wanted})
+	return res.held, res.short, err
+}
+
+// Release hands held units back to the storefront, e.g. when an order is cancelled.
+func (s *Service) Release(ctx context.Context, held map[int64]int) error {
+	_, err := s.sendStock(ctx, stockCommand{ctx: ctx, action: releaseStock, held: held})
+	return err
+}
+
+// Deduct turns held units into sold ones once an order is delivered.
+func (s *Service) Deduct(ctx context.Context, held map[int64]int) error {
+	_, err := s.sendStock(ctx, stockCommand{ctx: ctx, action: deductStock, held: held})
+	return err
+}
+
+func (s *Service) sendStock(ctx context.Context, cmd stockCommand) (stockResult, error) {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan stockResult, 1)
+	cmd.reply = reply
+
+	select {
+	case s.stockCalls <- cmd:
+	case <-ctx.Done():
+		return stockResult{}, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return stockResult{}, s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res, res.err
+	case <-ctx.Done():
+		return stockResult{}, waitErr(ctx)
+	}
+}
+
+// applyStock runs inside the loop, so checking free units and writing holds cannot interleave
+// with another order's reservation.
+func (s *Service) applyStock(cmd stockCommand) stockResult {
+	items, err := s.repo.List(cmd.ctx)
+	if err != nil {
+		return stockResult{err: err}
+	}
+	switch cmd.action {
+	case reserveStock:
+		held := make(map[int64]int)
+		for name, quantity := range cmd.wanted {
+			name = strings.TrimSpace(name)
+			tracked := false
+			// Items are listed newest first, so the freshest batches are promised first.
+			for i := range items {
+				if quantity <= 0 {
+					break
+				}
+				if !strings.EqualFold(strings.TrimSpace(items[i].Name), name) {
+					continue
+				}
+				tracked = true
+				take := min(items[i].ForSale(), quantity)
+				if take <= 0 {
+					continue
+				}
+				items[i].ReservedCount += take
+				held[items[i].ID] += take
+				quantity -= take
+			}
+			if tracked && quantity > 0 {
+				return stockResult{short: name}
+			}
+		}
+		for i := range items {
+			if held[items[i].ID] == 0 {
+				continue
+			}
+			if err := s.repo.SetStock(cmd.ctx, items[i].ID, items[i].AvailableCount, items[i].ReservedCount); err != nil {
+				return stockResult{err: err}
+			}
+		}
+		return stockResult{held: held}
+	case releaseStock, deductStock:
+		for _, item := range items {
+			quantity := cmd.held[item.ID]
+			if quantity <= 0 {
+				continue
+			}
+			// Admins may have edited the batch since, so counters are clamped instead of trusted.
+			released := min(quantity, item.ReservedCount)
+			available := item.AvailableCount
+			if cmd.action == deductStock {
+				available = max(available-quantity, 0)
+			}
+			if err := s.repo.SetStock(cmd.ctx, item.ID, available, item.ReservedCount-released); err != nil {
+				return stockResult{err: err}
+			}
+		}
+		return stockResult{}
+	}
+	return stockResult{err: errors.New("unknown inventory action")}
+}
@@ -3,171 +3,429 @@ package inventory
 import (
 	"context"
 	"errors"
+	"io"
+	"iter"
+	"log"
+	"strings"
 	"time"
+
+	"bakery/pkg/breaker"
+	"bakery/pkg/clock"
+	"bakery/pkg/events"
+	"bakery/pkg/metrics"
 )
 
 // command defines a mutation so the goroutine can serialize writes through a channel.
+// ctx is the caller's context so the repository call is cancelled along with the request;
+// reply is buffered so a caller that already gave up never blocks the loop.
 type command struct {
+	ctx    context.Context
 	action string
 	item   Item
 	id     int64
+	adjust PriceAdjustment
 	reply  chan commandResult
 }
 
 // listQuery enables consumers to fetch the latest state without touching shared memory.
 type listQuery struct {
-	reply chan queryResult
+	ctx context.Context
+	// cursor asks for the batches as a cursor the caller ranges over instead of a loaded slice.
+	cursor bool
+	reply  chan queryResult
+}
+
+// categoryQuery asks the goroutine for the per-category batch counts.
+type categoryQuery struct {
+	ctx   context.Context
+	reply chan categoryResult
+}
+
+// categoryResult carries the grouped counts back to the caller.
+type categoryResult struct {
+	categories []CategoryCount
+	err        error
 }
 
 // commandResult forwards either the persisted item or an error back to the caller.
+// affected counts the batches a bulk change touched.
 type commandResult struct {
-	item Item
-	err  error
+	item     Item
+	affected int64
+	err      error
 }
 
 // queryResult returns a full list of inventory items for rendering.
 type queryResult struct {
-	items []Item
-	err   error
+	items  []Item
+	cursor iter.Seq2[Item, error]
+	err    error
+}
+
+// Options tunes the service; the zero value imposes no limits.
+type Options struct {
+	// MaxFeatured caps how many batches may be featured at once; zero leaves it unlimited.
+	MaxFeatured int
+	// AuditPath, when set, receives a JSON line for every change to a batch, with its values
+	// before and after, and History reads it back. Empty turns the audit log off.
+	AuditPath string
+	// AuditRetention drops audit entries older than this in an hourly sweep; zero keeps them forever.
+	AuditRetention time.Duration
+	// Clock stamps audit entries; nil uses the system clock.
+	Clock clock.Clock
+	// Logger reports audit write failures; nil discards them.
+	Logger *log.Logger
+	// Breaker fails requests fast once storage has failed Breaker.Threshold times in a row; the zero
+	// value never trips.
+	Breaker breaker.Options
+	// ShelfLife is how long a batch stays fresh after baking. When ExpiryInterval is positive too,
+	// the service looks for stale batches with units left that often, logs each once and publishes
+	// it on Events as EventExpired. Zero turns the check off.
+	ShelfLife      time.Duration
+	ExpiryInterval time.Duration
+	// ExpiryWebhook, when set, also receives each check's stale batches as a JSON array POST.
+	ExpiryWebhook string
+	// Events carries expiry notices; nil drops them.
+	Events *events.Bus
 }
 
 // Service owns a goroutine to uphold Go's "share memory by communicating" approach.
 type Service struct {
-	repo      *Repository
-	commands  chan command
-	listCalls chan listQuery
-	quit      chan struct{}
+	repo           *Repository
+	maxFeatured    int
+	auditPath      string
+	auditRetention time.Duration
+	clock          clock.Clock
+	logger         *log.Logger
+	breaker        *breaker.Breaker
+	activity       metrics.Activity
+	shelfLife      time.Duration
+	expiryEvery    time.Duration
+	expiryWebhook  string
+	events         *events.Bus
+	// notified holds the stale batches already reported; only the loop touches it.
+	notified     map[int64]bool
+	commands     chan command
+	listCalls    chan listQuery
+	catCalls     chan categoryQuery
+	historyCalls chan historyQuery
+	// stockCalls serializes reservations with every other write so two orders never claim the same units.
+	stockCalls chan stockCommand
+	quit       chan struct{}
 }
 
 // NewService starts the background goroutine immediately so HTTP handlers only see non-blocking calls.
-func NewService(repo *Repository) *Service {
+func NewService(repo *Repository, opts Options) *Service {
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
 	svc := &Service{
-		repo:      repo,
-		commands:  make(chan command),
-		listCalls: make(chan listQuery),
-		quit:      make(chan struct{}),
+		repo:           repo,
+		maxFeatured:    opts.MaxFeatured,
+		auditPath:      opts.AuditPath,
+		auditRetention: opts.AuditRetention,
+		clock:          clock.OrReal(opts.Clock),
+		logger:         logger,
+		breaker:        breaker.New("inventory storage", opts.Breaker, opts.Clock, logger),
+		shelfLife:      opts.ShelfLife,
+		expiryEvery:    opts.ExpiryInterval,
+		expiryWebhook:  opts.ExpiryWebhook,
+		events:         opts.Events,
+		notified:       make(map[int64]bool),
+		commands:       make(chan command),
+		listCalls:      make(chan listQuery),
+		catCalls:       make(chan categoryQuery),
+		historyCalls:   make(chan historyQuery),
+		stockCalls:     make(chan stockCommand),
+		quit:           make(chan struct{}),
 	}
 	go svc.loop()
 	return svc
 }
 
-// loop processes commands and queries sequentially so no mutexes are needed.
+// loop processes commands and queries sequentially so no mutexes are needed. Every command and
+// stock change is bracketed by audit snapshots, so the audit log sees exactly what each one did.
 func (s *Service) loop() {
+	// A nil channel never fires, so without an audit retention window the prune case stays idle.
+	var prune <-chan time.Time
+	if s.auditPath != "" && s.auditRetention > 0 {
+		ticker := time.NewTicker(defaultAuditSweep)
+		defer ticker.Stop()
+		prune = ticker.C
+		s.pruneAudit()
+	}
+	var expiry <-chan time.Time
+	if s.shelfLife > 0 && s.expiryEvery > 0 {
+		ticker := time.NewTicker(s.expiryEvery)
+		defer ticker.Stop()
+		expiry = ticker.C
+		s.checkExpiry()
+	}
 	for {
 		select {
 		case cmd := <-s.commands:
-			switch cmd.action {
-			case "save":
-				stored, err := s.repo.Save(context.Background(), cmd.item)
-				cmd.reply <- commandResult{item: stored, err: err}
-			case "update":
-				err := s.repo.Update(context.Background(), cmd.item)
+			if err := s.breaker.Allow(); err != nil {
 				cmd.reply <- commandResult{err: err}
-			case "delete":
-				err := s.repo.Delete(context.Background(), cmd.id)
-				cmd.reply <- commandResult{err: err}
-			default:
-				cmd.reply <- commandResult{err: errors.New("unknown inventory action")}
+				continue
 			}
+			before := s.auditSnapshot(cmd.ctx)
+			res := s.apply(cmd)
+			s.audit(cmd.ctx, cmd.action, before)
+			s.recordOutcome(res.err)
+			cmd.reply <- res
 		case q := <-s.listCalls:
-			items, err := s.repo.List(context.Background())
-			q.reply <- queryResult{items: items, err: err}
+			if err := s.breaker.Allow(); err != nil {
+				q.reply <- queryResult{err: err}
+				continue
+			}
+			var res queryResult
+			if q.cursor {
+				res.cursor, res.err = s.repo.ListCursor(q.ctx)
+			} else {
+				res.items, res.err = s.repo.List(q.ctx)
+			}
+			s.recordOutcome(res.err)
+			q.reply <- res
+		case q := <-s.catCalls:
+			if err := s.breaker.Allow(); err != nil {
+				q.reply <- categoryResult{err: err}
+				continue
+			}
+			categories, err := s.repo.Categories(q.ctx)
+			s.recordOutcome(err)
+			q.reply <- categoryResult{categories: categories, err: err}
+		case q := <-s.historyCalls:
+			entries, err := s.readHistory(q.id)
+			q.reply <- historyResult{entries: entries, err: err}
+		case cmd := <-s.stockCalls:
+			if err := s.breaker.Allow(); err != nil {
+				cmd.reply <- stockResult{err: err}
+				continue
+			}
+			before := s.auditSnapshot(cmd.ctx)
+			res := s.applyStock(cmd)
+			s.audit(cmd.ctx, cmd.action, before)
+			s.recordOutcome(res.err)
+			cmd.reply <- res
+		case <-prune:
+			s.pruneAudit()
+		case <-expiry:
+			s.checkExpiry()
 		case <-s.quit:
 			return
 		}
 	}
 }
 
+// apply runs one command against the repository.
+func (s *Service) apply(cmd command) commandResult {
+	switch cmd.action {
+	case "save":
+		stored, err := s.repo.Save(cmd.ctx, cmd.item)
+		return commandResult{item: stored, err: err}
+	case "saveUnique":
+		stored, err := s.saveUnique(cmd.ctx, cmd.item)
+		return commandResult{item: stored, err: err}
+	case "update":
+		return commandResult{err: s.repo.Update(cmd.ctx, cmd.item)}
+	case "delete":
+		return commandResult{err: s.repo.Delete(cmd.ctx, cmd.id)}
+	case "image":
+		return commandResult{err: s.repo.SetImage(cmd.ctx, cmd.item.ID, cmd.item.Image)}
+	case "feature":
+		return commandResult{err: s.applyFeature(cmd.ctx, cmd.item.ID, cmd.item.Featured)}
+	case "adjustPrices":
+		affected, err := s.applyAdjustment(cmd.ctx, cmd.adjust)
+		return commandResult{affected: affected, err: err}
+	}
+	return commandResult{err: errors.New("unknown inventory action")}
+}
+
 // Add registers a fresh batch and returns the stored record with its generated identifier.
 func (s *Service) Add(ctx context.Context, item Item) (Item, error) {
-	reply := make(chan commandResult)
-	cmd := command{action: "save", item: item, reply: reply}
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, action: "save", item: item, reply: reply}
 
 	select {
 	case s.commands <- cmd:
 	case <-ctx.Done():
 		return Item{}, ctx.Err()
 	case <-time.After(2 * time.Second):
-		return Item{}, errors.New("inventory queue is busy")
+		return Item{}, s.busy()
 	}
 
 	select {
 	case res := <-reply:
 		return res.item, res.err
 	case <-ctx.Done():
-		return Item{}, ctx.Err()
-	case <-time.After(2 * time.Second):
-		return Item{}, errors.New("inventory save timed out")
+		return Item{}, waitErr(ctx)
 	}
 }
 
 // Update mutates the available count or price when the admin edits a row.
 func (s *Service) Update(ctx context.Context, item Item) error {
-	reply := make(chan commandResult)
-	cmd := command{action: "update", item: item, reply: reply}
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, action: "update", item: item, reply: reply}
 
 	select {
 	case s.commands <- cmd:
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-time.After(2 * time.Second):
-		return errors.New("inventory queue is busy")
+		return s.busy()
 	}
 
 	select {
 	case res := <-reply:
 		return res.err
 	case <-ctx.Done():
-		return ctx.Err()
-	case <-time.After(2 * time.Second):
-		return errors.New("inventory update timed out")
+		return waitErr(ctx)
 	}
 }
 
 // Delete removes the batch entirely when the admin clears it.
 func (s *Service) Delete(ctx context.Context, id int64) error {
-	reply := make(chan commandResult)
-	cmd := command{action: "delete", id: id, reply: reply}
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, action: "delete", id: id, reply: reply}
 
 	select {
 	case s.commands <- cmd:
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-time.After(2 * time.Second):
-		return errors.New("inventory queue is busy")
+		return s.busy()
 	}
 
 	select {
 	case res := <-reply:
 		return res.err
+	case <-ctx.Done():
+		return waitErr(ctx)
+	}
+}
+
+// SetImage records the URL of a batch's uploaded photo.
+func (s *Service) SetImage(ctx context.Context, id int64, url string) error {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, action: "image", item: Item{ID: id, Image: url}, reply: reply}
+
+	select {
+	case s.commands <- cmd:
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-time.After(2 * time.Second):
-		return errors.New("inventory delete timed out")
+		return s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res.err
+	case <-ctx.Done():
+		return waitErr(ctx)
 	}
 }
 
 // List returns all batches to render the admin table and the public menu.
 func (s *Service) List(ctx context.Context) ([]Item, error) {
-	reply := make(chan queryResult)
-	q := listQuery{reply: reply}
+	res, err := s.askList(ctx, false)
+	return res.items, err
+}
+
+// ListCursor is List one batch at a time, for streaming long listings. The query is opened on the
+// service goroutine like every other read, but the rows are decoded on the caller's as it ranges
+// over the cursor. Range over it exactly once; a cursor that is never ranged keeps its rows open
+// until ctx is done.
+func (s *Service) ListCursor(ctx context.Context) (iter.Seq2[Item, error], error) {
+	res, err := s.askList(ctx, true)
+	return res.cursor, err
+}
+
+// askList hands a listing to the loop and waits for its answer.
+func (s *Service) askList(ctx context.Context, cursor bool) (queryResult, error) {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan queryResult, 1)
+	q := listQuery{ctx: ctx, cursor: cursor, reply: reply}
 
 	select {
 	case s.listCalls <- q:
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return queryResult{}, ctx.Err()
 	case <-time.After(2 * time.Second):
-		return nil, errors.New("inventory queue is busy")
+		return queryResult{}, s.busy()
 	}
 
 	select {
 	case res := <-reply:
-		return res.items, res.err
+		return res, res.err
+	case <-ctx.Done():
+		return queryResult{}, waitErr(ctx)
+	}
+}
+
+// Categories lists the categories present in inventory with their batch counts.
+func (s *Service) Categories(ctx context.Context) ([]CategoryCount, error) {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan categoryResult, 1)
+	q := categoryQuery{ctx: ctx, reply: reply}
+
+	select {
+	case s.catCalls <- q:
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-time.After(2 * time.Second):
-		return nil, errors.New("inventory list timed out")
+		return nil, s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res.categories, res.err
+	case <-ctx.Done():
+		return nil, waitErr(ctx)
+	}
+}
+
+// HasItem reports whether any batch carries the given name, ignoring case and surrounding spaces.
+// It lets the order service reject items the bakery never baked when strict item checks are on.
+func (s *Service) HasItem(ctx context.Context, name string) (bool, error) {
+	items, err := s.List(ctx)
+	if err != nil {
+		return false, err
+	}
+	name = strings.TrimSpace(name)
+	for _, item := range items {
+		if strings.EqualFold(strings.TrimSpace(item.Name), name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PriceCents returns the unit price of the freshest batch with the given name, matched like HasItem,
+// so order totals use what the storefront currently charges.
+func (s *Service) PriceCents(ctx context.Context, name string) (int, bool, error) {
+	items, err := s.List(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	name = strings.TrimSpace(name)
+	// List returns the newest batches first.
+	for _, item := range items {
+		if strings.EqualFold(strings.TrimSpace(item.Name), name) {
+			return item.PriceCents, true, nil
+		}
 	}
+	return 0, false, nil
 }
 
 // Close stops the background goroutine when the application shuts down.
@@ -0,0 +1,67 @@
+package inventory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+)
+
+// CategorySummary is what the storefront says about one category, such as "3 kinds of bread":
+// Kinds counts the distinct names still for sale and Units the units left across their batches.
+type CategorySummary struct {
+	Category string `json:"category"`
+	Kinds    int    `json:"kinds"`
+	Units    int    `json:"units"`
+}
+
+// Summary totals the categories, listed alphabetically with only those that have something for sale.
+type Summary struct {
+	Categories []CategorySummary `json:"categories"`
+	Kinds      int               `json:"kinds"`
+	Units      int               `json:"units"`
+}
+
+// Summary totals what is for sale per category. Reserved units are left out, as on the menu, and
+// two batches of the same name count as one kind.
+func (s *Service) Summary(ctx context.Context) (Summary, error) {
+	items, err := s.List(ctx)
+	if err != nil {
+		return Summary{}, err
+	}
+	return Summarize(items), nil
+}
+
+// Summarize does the counting for Summary. Names are compared like HasItem compares them, ignoring
+// case and surrounding spaces.
+func Summarize(items []Item) Summary {
+	byCategory := make(map[string]*CategorySummary)
+	seen := make(map[string]bool)
+	for _, item := range items {
+		units := item.ForSale()
+		if units == 0 {
+			continue
+		}
+		entry, ok := byCategory[item.Category]
+		if !ok {
+			entry = &CategorySummary{Category: item.Category}
+			byCategory[item.Category] = entry
+		}
+		entry.Units += units
+		key := item.Category + "\x00" + strings.ToLower(strings.TrimSpace(item.Name))
+		if !seen[key] {
+			seen[key] = true
+			entry.Kinds++
+		}
+	}
+	summary := Summary{Categories: make([]CategorySummary, 0, len(byCategory))}
+	for _, entry := range byCategory {
+		summary.Categories = append(summary.Categories, *entry)
+		summary.Kinds += entry.Kinds
+		summary.Units += entry.Units
+	}
+	slices.SortFunc(summary.Categories, func(a, b CategorySummary) int {
+		return cmp.Compare(a.Category, b.Category)
+	})
+	return summary
+}
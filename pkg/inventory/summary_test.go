@@ -0,0 +1,85 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []Item
+		want  Summary
+	}{
+		{
+			name:  "empty shelf",
+			items: nil,
+			want:  Summary{Categories: []CategorySummary{}},
+		},
+		{
+			name: "categories sorted with their kinds and units",
+			items: []Item{
+				{Name: "Круассан", Category: "pastry", AvailableCount: 4},
+				{Name: "Багет", Category: "bread", AvailableCount: 10},
+				{Name: "Бородинский", Category: "bread", AvailableCount: 3},
+				{Name: "Эклер", Category: "pastry", AvailableCount: 1},
+			},
+			want: Summary{
+				Categories: []CategorySummary{
+					{Category: "bread", Kinds: 2, Units: 13},
+					{Category: "pastry", Kinds: 2, Units: 5},
+				},
+				Kinds: 4,
+				Units: 18,
+			},
+		},
+		{
+			name: "batches of one name are one kind, whatever the case and spacing",
+			items: []Item{
+				{Name: "Багет", Category: "bread", AvailableCount: 5},
+				{Name: " багет ", Category: "bread", AvailableCount: 7},
+			},
+			want: Summary{
+				Categories: []CategorySummary{{Category: "bread", Kinds: 1, Units: 12}},
+				Kinds:      1,
+				Units:      12,
+			},
+		},
+		{
+			name: "one name in two categories counts in each",
+			items: []Item{
+				{Name: "Маковый", Category: "bread", AvailableCount: 2},
+				{Name: "Маковый", Category: "pastry", AvailableCount: 2},
+			},
+			want: Summary{
+				Categories: []CategorySummary{
+					{Category: "bread", Kinds: 1, Units: 2},
+					{Category: "pastry", Kinds: 1, Units: 2},
+				},
+				Kinds: 2,
+				Units: 4,
+			},
+		},
+		{
+			name: "reserved units are not for sale and sold-out batches drop out",
+			items: []Item{
+				{Name: "Багет", Category: "bread", AvailableCount: 10, ReservedCount: 4},
+				{Name: "Бородинский", Category: "bread", AvailableCount: 3, ReservedCount: 3},
+				{Name: "Эклер", Category: "pastry", AvailableCount: 0},
+				{Name: "Круассан", Category: "pastry", AvailableCount: 2, ReservedCount: 5},
+			},
+			want: Summary{
+				Categories: []CategorySummary{{Category: "bread", Kinds: 1, Units: 6}},
+				Kinds:      1,
+				Units:      6,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.items); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Summarize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
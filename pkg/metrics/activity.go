@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Activity follows a channel-based service from outside its goroutine: how many calls are queued
+// or being answered, and the last thing that went wrong. The services' channels are unbuffered, so
+// their length says nothing; counting callers between Enter and Leave shows the queue instead.
+// Every field is atomic, so the debug endpoint can read it while the goroutine is stuck.
+type Activity struct {
+	inFlight atomic.Int64
+	last     atomic.Pointer[Failure]
+}
+
+// Failure is the last error a service recorded and when.
+type Failure struct {
+	At    time.Time `json:"at"`
+	Error string    `json:"error"`
+}
+
+// ActivityReport is what the debug endpoint shows for one service.
+type ActivityReport struct {
+	// InFlight counts calls waiting for the goroutine or being answered by it; more than one means a queue.
+	InFlight  int64    `json:"in_flight"`
+	LastError *Failure `json:"last_error"`
+}
+
+// Enter counts a call that is about to queue for the service goroutine; pair it with a deferred Leave.
+func (a *Activity) Enter() {
+	a.inFlight.Add(1)
+}
+
+// Leave counts the call as answered or abandoned.
+func (a *Activity) Leave() {
+	a.inFlight.Add(-1)
+}
+
+// Fail records err as the service's last error.
+func (a *Activity) Fail(at time.Time, err error) {
+	a.last.Store(&Failure{At: at.UTC(), Error: err.Error()})
+}
+
+// Report reads the counters for the debug endpoint.
+func (a *Activity) Report() ActivityReport {
+	return ActivityReport{InFlight: a.inFlight.Load(), LastError: a.last.Load()}
+}
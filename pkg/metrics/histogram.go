@@ -0,0 +1,87 @@
+// Package metrics keeps the few measurements the bakery exports and writes them in the Prometheus
+// text format, so any scraper can read them without the server pulling in a metrics library.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets spans a quick in-memory write to a database that is clearly struggling.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Histogram counts durations into fixed buckets. Observations and reads are atomic, so a service
+// goroutine can record while the metrics endpoint reads, and a stuck service can still be scraped.
+// A read taken during an observation may be off by that one observation, which a scraper never
+// notices.
+type Histogram struct {
+	name   string
+	help   string
+	bounds []time.Duration
+	// counts holds one counter per bound plus the overflow past the last one; they are not cumulative.
+	counts []atomic.Uint64
+	sum    atomic.Int64
+}
+
+// NewHistogram returns a histogram exported as name, in seconds, with the given help text and
+// ascending bucket bounds.
+func NewHistogram(name, help string, bounds []time.Duration) *Histogram {
+	return &Histogram{
+		name:   name,
+		help:   help,
+		bounds: bounds,
+		counts: make([]atomic.Uint64, len(bounds)+1),
+	}
+}
+
+// Observe records one duration. A nil histogram ignores it, so optional instrumentation needs no checks.
+func (h *Histogram) Observe(d time.Duration) {
+	if h == nil {
+		return
+	}
+	i := 0
+	for i < len(h.bounds) && d > h.bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.sum.Add(int64(d))
+}
+
+// WriteTo writes the histogram in the Prometheus text format: cumulative buckets up to +Inf, then the
+// sum in seconds and the count.
+func (h *Histogram) WriteTo(w io.Writer) (int64, error) {
+	var out []byte
+	out = fmt.Appendf(out, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i].Load()
+		out = fmt.Appendf(out, "%s_bucket{le=%q} %d\n", h.name, seconds(bound), cumulative)
+	}
+	cumulative += h.counts[len(h.bounds)].Load()
+	out = fmt.Appendf(out, "%s_bucket{le=\"+Inf\"} %d\n", h.name, cumulative)
+	out = fmt.Appendf(out, "%s_sum %s\n", h.name, seconds(time.Duration(h.sum.Load())))
+	out = fmt.Appendf(out, "%s_count %d\n", h.name, cumulative)
+	n, err := w.Write(out)
+	return int64(n), err
+}
+
+// seconds renders a duration the way Prometheus expects, as a plain number of seconds.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
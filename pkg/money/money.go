@@ -0,0 +1,194 @@
+// Package money formats prices in one place, so the menu, the admin table and error messages
+// cannot drift apart the way separate fmt.Sprintf calls did.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currencies the bakery can price in. Prices are stored as bare minor units, so the configured
+// currency only decides how they are printed and parsed.
+const (
+	RUB = "RUB"
+	USD = "USD"
+	EUR = "EUR"
+)
+
+// Format is how one currency is written: the sign, whether it goes before the amount, and the
+// decimal separator. Russian shops write "220,50 ₽", American ones "$220.50", and a euro price
+// keeps the comma and the trailing sign.
+type Format struct {
+	Symbol    string
+	Prefix    bool
+	Separator string
+}
+
+// formats is the table String and Parse read; a code missing here prints like RUB with the code
+// itself as the sign.
+var formats = map[string]Format{
+	RUB: {Symbol: "₽", Separator: ","},
+	USD: {Symbol: "$", Prefix: true, Separator: "."},
+	EUR: {Symbol: "€", Separator: ","},
+}
+
+// Supported reports whether the currency has a row in the formatting table, so a -currency flag
+// can be checked at startup instead of printing odd prices later.
+func Supported(currency string) bool {
+	_, ok := formats[currency]
+	return ok
+}
+
+// Currencies lists the supported codes for help texts and error messages.
+func Currencies() []string {
+	return []string{RUB, USD, EUR}
+}
+
+// FormatOf returns the formatting row for a currency; an empty code means RUB.
+func FormatOf(currency string) Format {
+	if currency == "" {
+		currency = RUB
+	}
+	if format, ok := formats[currency]; ok {
+		return format
+	}
+	return Format{Symbol: currency, Separator: ","}
+}
+
+// Money is an amount in minor units (kopecks for RUB, cents for USD and EUR) together with its
+// currency.
+type Money struct {
+	Cents    int64
+	Currency string
+}
+
+// Rub wraps a kopeck amount, which is how prices are stored.
+func Rub(cents int) Money {
+	return Money{Cents: int64(cents), Currency: RUB}
+}
+
+// In wraps a stored amount in the configured currency; an empty code means RUB.
+func In(cents int, currency string) Money {
+	if currency == "" {
+		currency = RUB
+	}
+	return Money{Cents: int64(cents), Currency: currency}
+}
+
+// Symbol returns the sign printed for a currency code.
+func Symbol(currency string) string {
+	return FormatOf(currency).Symbol
+}
+
+// String renders the amount with two decimals the way its currency is written: "220,00 ₽",
+// "-5,50 ₽", "$0.00", "-$5.50", "12,30 €". The minus sign always leads, ahead of a prefix symbol.
+func (m Money) String() string {
+	sign := ""
+	cents := m.Cents
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	format := FormatOf(m.Currency)
+	amount := fmt.Sprintf("%d%s%02d", cents/100, format.Separator, cents%100)
+	if format.Prefix {
+		return sign + format.Symbol + amount
+	}
+	return sign + amount + " " + format.Symbol
+}
+
+// jsonMoney is the wire form: the display string for people and the raw cents for code.
+type jsonMoney struct {
+	Formatted string `json:"formatted"`
+	Cents     int64  `json:"cents"`
+	Currency  string `json:"currency"`
+}
+
+// MarshalJSON writes {"formatted":"220,00 ₽","cents":22000,"currency":"RUB"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	currency := m.Currency
+	if currency == "" {
+		currency = RUB
+	}
+	return json.Marshal(jsonMoney{Formatted: m.String(), Cents: m.Cents, Currency: currency})
+}
+
+// UnmarshalJSON accepts the object MarshalJSON writes, a number of roubles, or a string such as
+// "220 ₽" or "220,50", which is how hand-written files like the hero menu give prices.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	trimmed := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(trimmed, "{"):
+		var wire jsonMoney
+		if err := json.Unmarshal(data, &wire); err != nil {
+			return err
+		}
+		currency := wire.Currency
+		if currency == "" {
+			currency = RUB
+		}
+		*m = Money{Cents: wire.Cents, Currency: currency}
+		return nil
+	case strings.HasPrefix(trimmed, `"`):
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		parsed, err := Parse(text)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+	parsed, err := Parse(trimmed)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// ErrInvalid reports a price that is not an amount of money.
+var ErrInvalid = errors.New("price must be an amount such as 220 or 220,50 ₽")
+
+// Parse reads an amount in whichever supported currency its sign or code names, such as "$220.50"
+// or "12,30 €", and roubles when it names none.
+func Parse(text string) (Money, error) {
+	for _, currency := range Currencies() {
+		format := formats[currency]
+		if strings.Contains(text, format.Symbol) || strings.Contains(text, currency) {
+			return ParseIn(text, currency)
+		}
+	}
+	return ParseIn(text, RUB)
+}
+
+// ParseIn reads an amount of the currency written with a comma or a dot as the decimal separator,
+// whichever the locale prefers, and with an optional sign or code on either side, so "$220.50",
+// "220,50 €" and a bare "220" are all accepted.
+func ParseIn(text, currency string) (Money, error) {
+	if currency == "" {
+		currency = RUB
+	}
+	symbol := Symbol(currency)
+	text = strings.TrimSpace(text)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+	for _, mark := range []string{symbol, currency} {
+		text = strings.TrimSpace(strings.TrimPrefix(strings.TrimSuffix(text, mark), mark))
+	}
+	text = strings.ReplaceAll(text, ",", ".")
+	amount, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) || strings.HasPrefix(text, "-") {
+		return Money{}, ErrInvalid
+	}
+	cents := int64(math.Round(amount * 100))
+	if negative {
+		cents = -cents
+	}
+	return Money{Cents: cents, Currency: currency}, nil
+}
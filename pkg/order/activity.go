@@ -0,0 +1,25 @@
+package order
+
+import "bakery/pkg/metrics"
+
+// recordOutcome tells the breaker how a request went and keeps a storage failure as the service's
+// last error for the debug endpoint.
+func (s *Service) recordOutcome(err error) {
+	failed := storageFailure(err)
+	s.breaker.Record(failed)
+	if failed {
+		s.activity.Fail(s.clock.Now(), err)
+	}
+}
+
+// busy records a call that gave up queueing and returns ErrQueueBusy for it.
+func (s *Service) busy() error {
+	s.activity.Fail(s.clock.Now(), ErrQueueBusy)
+	return ErrQueueBusy
+}
+
+// Activity reports the calls in flight and the last error, for the debug endpoint. It reads
+// counters only, so it answers even while the service goroutine is stuck.
+func (s *Service) Activity() metrics.ActivityReport {
+	return s.activity.Report()
+}
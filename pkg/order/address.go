@@ -0,0 +1,82 @@
+package order
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NormalizedAddress is what an AddressNormalizer makes of a customer's free-text address.
+// HasCoordinates is false when the address could not be located; Lat and Lng are then meaningless.
+type NormalizedAddress struct {
+	Address        string
+	Lat, Lng       float64
+	HasCoordinates bool
+}
+
+// AddressNormalizer cleans up addresses during Submit so route planning works from consistent text
+// and, when a geocoder is plugged in, from coordinates. Normalization is best effort: when it fails
+// the order is stored with the address the customer typed.
+type AddressNormalizer interface {
+	Normalize(ctx context.Context, address string) (NormalizedAddress, error)
+}
+
+// Geocoder turns an address into coordinates; ok is false when the address is unknown to it.
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (lat, lng float64, ok bool, err error)
+}
+
+// NoopNormalizer keeps addresses exactly as typed; it is the default so no geocoder is ever required.
+type NoopNormalizer struct{}
+
+// Normalize returns the address unchanged and without coordinates.
+func (NoopNormalizer) Normalize(_ context.Context, address string) (NormalizedAddress, error) {
+	return NormalizedAddress{Address: address}, nil
+}
+
+// TidyNormalizer trims the address, collapses runs of whitespace and capitalizes every word,
+// then asks the optional Geocoder for coordinates. Only first letters are touched, so
+// abbreviations such as "СПб" or "12Б" survive.
+type TidyNormalizer struct {
+	Geocoder Geocoder
+}
+
+// Normalize tidies the text and geocodes it when a Geocoder is configured.
+func (n TidyNormalizer) Normalize(ctx context.Context, address string) (NormalizedAddress, error) {
+	words := strings.Fields(address)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(first)) + word[size:]
+	}
+	normalized := NormalizedAddress{Address: strings.Join(words, " ")}
+	if n.Geocoder == nil || normalized.Address == "" {
+		return normalized, nil
+	}
+	lat, lng, ok, err := n.Geocoder.Geocode(ctx, normalized.Address)
+	if err != nil {
+		return normalized, err
+	}
+	if ok {
+		normalized.Lat, normalized.Lng, normalized.HasCoordinates = lat, lng, true
+	}
+	return normalized, nil
+}
+
+// normalizeAddress applies the configured normalizer, keeping whatever it managed to produce even when it
+// reports an error, and leaves coordinates already on the order (e.g. from a restored backup) in place
+// when the normalizer finds none.
+func (s *Service) normalizeAddress(ctx context.Context, order Order) Order {
+	normalized, err := s.normalizer.Normalize(ctx, order.Address)
+	if err != nil {
+		s.logger.Printf("address normalization failed for %q: %v", order.Address, err)
+	}
+	if strings.TrimSpace(normalized.Address) != "" {
+		order.Address = normalized.Address
+	}
+	if normalized.HasCoordinates {
+		lat, lng := normalized.Lat, normalized.Lng
+		order.Lat, order.Lng = &lat, &lng
+	}
+	return order
+}
@@ -0,0 +1,40 @@
+package order
+
+import (
+	"context"
+	"strings"
+)
+
+// maxEmailLength is the longest address SMTP delivers, so anything longer is a typo or junk.
+const maxEmailLength = 254
+
+// Customers remembers who placed an order so their history can be found by phone later; the
+// customer service satisfies it.
+type Customers interface {
+	Remember(ctx context.Context, name, phone, address, email string) (int64, error)
+}
+
+// linkCustomer upserts the customer behind an order and stamps the order with their id. A failure is
+// logged and the order goes in unlinked, because a missed history entry should never lose an order.
+func (s *Service) linkCustomer(ctx context.Context, order Order) Order {
+	if s.customers == nil {
+		return order
+	}
+	id, err := s.customers.Remember(ctx, order.CustomerName, order.Phone, order.Address, order.Email)
+	if err != nil {
+		s.logger.Printf("remembering the customer for phone %q failed: %v", order.Phone, err)
+		return order
+	}
+	order.CustomerID = id
+	return order
+}
+
+// validEmail is deliberately loose: the address is only kept for the bakery to reach out by hand,
+// so it only has to look like one rather than satisfy RFC 5322.
+func validEmail(email string) bool {
+	if len(email) > maxEmailLength || strings.ContainsAny(email, " \t\r\n") {
+		return false
+	}
+	at := strings.LastIndex(email, "@")
+	return at > 0 && at < len(email)-1
+}
@@ -0,0 +1,74 @@
+package order
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"bakery/pkg/customer"
+)
+
+// ErrDuplicate comes back from Submit together with the earlier order when an identical one was
+// submitted within the dedup window. Callers should treat it as success and show the earlier order,
+// because the customer most likely double-tapped the submit button.
+var ErrDuplicate = errors.New("identical order was just submitted")
+
+// recentOrder remembers a stored order long enough to recognize its duplicates.
+type recentOrder struct {
+	order Order
+	at    time.Time
+}
+
+// orderFingerprint identifies orders that are the same request: same customer, items and schedules.
+// The address is left out because a retyped address is still the same household ordering twice, and
+// the phone is compared normalized for the same reason.
+func orderFingerprint(order Order) string {
+	items := make([]OrderItem, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = OrderItem{
+			Name:        strings.ToLower(strings.TrimSpace(item.Name)),
+			Quantity:    item.Quantity,
+			Options:     item.Options,
+			Custom:      item.Custom,
+			Description: strings.TrimSpace(item.Description),
+		}
+	}
+	key, _ := json.Marshal(struct {
+		Name, Phone string
+		Items       []OrderItem
+		Bread       BreadSchedule
+		Croissants  []CroissantSchedule
+	}{
+		Name:       strings.ToLower(strings.TrimSpace(order.CustomerName)),
+		Phone:      customer.NormalizePhone(order.Phone),
+		Items:      items,
+		Bread:      order.BreadSchedule,
+		Croissants: order.CroissantSchedule,
+	})
+	return string(key)
+}
+
+// findDuplicate returns the order an identical submission produced within the window. It runs inside
+// the service loop, which is what keeps the recent map consistent without a mutex.
+func (s *Service) findDuplicate(order Order) (Order, bool) {
+	if s.dedupWindow <= 0 {
+		return Order{}, false
+	}
+	now := s.clock.Now()
+	for key, recent := range s.recent {
+		if now.Sub(recent.at) >= s.dedupWindow {
+			delete(s.recent, key)
+		}
+	}
+	recent, ok := s.recent[orderFingerprint(order)]
+	return recent.order, ok
+}
+
+// rememberOrder records a stored order for findDuplicate.
+func (s *Service) rememberOrder(order Order) {
+	if s.dedupWindow <= 0 {
+		return
+	}
+	s.recent[orderFingerprint(order)] = recentOrder{order: order, at: s.clock.Now()}
+}
@@ -0,0 +1,45 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"bakery/pkg/breaker"
+)
+
+// ErrNotFound is returned when an order id does not exist so HTTP handlers can respond with 404.
+var ErrNotFound = errors.New("order not found")
+
+// ErrQueueBusy is returned when the service goroutine did not take a call within two seconds. It
+// is backpressure from other orders ahead in line, so the same call is worth retrying shortly.
+var ErrQueueBusy = errors.New("queue is busy processing other orders")
+
+// ErrProcessingTimeout is returned when the goroutine took a call but the caller's deadline passed
+// before it answered, which points at a slow database or a stuck worker rather than a busy queue.
+// It wraps context.DeadlineExceeded, so callers checking for that keep working.
+var ErrProcessingTimeout = errors.New("order processing took too long")
+
+// waitErr explains why a caller stopped waiting for the goroutine's answer: a passed deadline is a
+// processing timeout, while a cancelled request is reported as is.
+func waitErr(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrProcessingTimeout, ctx.Err())
+	}
+	return ctx.Err()
+}
+
+// storageFailure tells the breaker whether a request failed because storage did. Validation errors,
+// missing orders and duplicates are answers, so storage is fine; a cancelled request says nothing
+// either way, and another service's open breaker is that service's failure, not this one's.
+func storageFailure(err error) bool {
+	switch {
+	case err == nil, IsValidation(err):
+		return false
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrDuplicate):
+		return false
+	case errors.Is(err, context.Canceled), errors.Is(err, breaker.ErrUnavailable):
+		return false
+	}
+	return true
+}
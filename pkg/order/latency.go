@@ -0,0 +1,58 @@
+package order
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"bakery/pkg/metrics"
+)
+
+// latency times every Submit in three parts, so a slow peak shows whether orders wait for the single
+// service goroutine or for the database once they have it.
+type latency struct {
+	// wait is from Submit until the goroutine takes the order; it grows when the goroutine is the bottleneck.
+	wait *metrics.Histogram
+	// save is the repository insert alone.
+	save *metrics.Histogram
+	// total is from Submit until the answer is ready, checks and stock reservation included.
+	total *metrics.Histogram
+	// slow logs any order whose total passes it; zero logs none.
+	slow time.Duration
+}
+
+// newLatency prepares the histograms the metrics endpoint exports.
+func newLatency(slow time.Duration) latency {
+	return latency{
+		wait:  metrics.NewHistogram("bakery_order_queue_wait_seconds", "Time an order waited for the order service goroutine.", metrics.LatencyBuckets),
+		save:  metrics.NewHistogram("bakery_order_save_seconds", "Time the repository took to store an order.", metrics.LatencyBuckets),
+		total: metrics.NewHistogram("bakery_order_submit_seconds", "Time from submitting an order to its answer, waiting included.", metrics.LatencyBuckets),
+		slow:  slow,
+	}
+}
+
+// observeSubmit records one finished Submit and logs it when it was slow. Orders refused before
+// reaching the repository have no save time and only count towards the wait and the total.
+func (s *Service) observeSubmit(cmd command, waited, saved time.Duration, res commandResult) {
+	total := time.Since(cmd.queued)
+	s.latency.total.Observe(total)
+	if s.latency.slow <= 0 || total < s.latency.slow {
+		return
+	}
+	outcome := fmt.Sprintf("stored as order %d", res.order.ID)
+	if res.err != nil {
+		outcome = fmt.Sprintf("failed: %v", res.err)
+	}
+	s.logger.Printf("slow order from %s took %s (%s waiting for the service, %s storing): %s", cmd.order.CustomerName, total.Round(time.Millisecond), waited.Round(time.Millisecond), saved.Round(time.Millisecond), outcome)
+}
+
+// WriteMetrics writes the Submit latency histograms in the Prometheus text format. It reads
+// counters only, so it answers even while the service goroutine is stuck.
+func (s *Service) WriteMetrics(w io.Writer) error {
+	for _, h := range []*metrics.Histogram{s.latency.wait, s.latency.save, s.latency.total} {
+		if _, err := h.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
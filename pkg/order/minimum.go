@@ -0,0 +1,58 @@
+package order
+
+import (
+	"context"
+	"fmt"
+
+	"bakery/pkg/money"
+)
+
+// Pricer prices one unit of an item in cents; ok is false when no price is known.
+// The inventory service satisfies it with the freshest batch's price.
+type Pricer interface {
+	PriceCents(ctx context.Context, name string) (cents int, ok bool, err error)
+}
+
+// Total sums the order's items at the pricer's unit prices. ok is false as soon as one item has no
+// price, because a partial sum would understate the order. Custom items are left out: an admin
+// prices them later, and until then the menu items alone decide the minimum.
+func Total(ctx context.Context, pricer Pricer, order Order) (cents int, ok bool, err error) {
+	for _, item := range order.Items {
+		if item.Custom {
+			continue
+		}
+		price, known, err := pricer.PriceCents(ctx, item.Name)
+		if err != nil {
+			return 0, false, fmt.Errorf("price %q: %w", item.Name, err)
+		}
+		if !known {
+			return 0, false, nil
+		}
+		cents += price * item.Quantity
+	}
+	return cents, true, nil
+}
+
+// checkMinimum rejects orders whose total is below the configured minimum. It runs after the item
+// rules, so it only ever prices well-formed items. An order that cannot be priced passes, unless
+// strict item checks are on, in which case every item was meant to be a known, priced product.
+func (s *Service) checkMinimum(ctx context.Context, order Order) error {
+	if s.minOrderCents <= 0 || s.pricer == nil {
+		return nil
+	}
+	total, ok, err := Total(ctx, s.pricer, order)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if s.catalog != nil {
+			return newValidationError("items", CodeNoPrice, "order total cannot be computed")
+		}
+		return nil
+	}
+	if total >= s.minOrderCents {
+		return nil
+	}
+	short := s.minOrderCents - total
+	return newValidationError("items", CodeBelowMinimum, fmt.Sprintf("order total is %s short of the minimum order", money.In(short, s.currency)))
+}
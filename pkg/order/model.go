@@ -1,19 +1,40 @@
 package order
 
-import "time"
+import (
+	"time"
+
+	"bakery/pkg/money"
+)
 
 // OrderItem describes a single product and the quantity requested.
+// Options carries free-form preferences such as "sliced" or "light bake"; orders stored before
+// options existed simply decode without them.
+// Custom marks a bespoke item that is not on the menu, such as a three-tier cake, spelled out in
+// Description. Custom items skip the menu check, hold no stock and have no price until an admin
+// quotes one, so they are left out of everything computed from batches.
 type OrderItem struct {
-	Name     string `json:"name"`
-	Quantity int    `json:"quantity"`
+	Name        string   `json:"name"`
+	Quantity    int      `json:"quantity"`
+	Options     []string `json:"options,omitempty"`
+	Custom      bool     `json:"custom,omitempty"`
+	Description string   `json:"description,omitempty"`
 }
 
 // BreadSchedule expresses how often the household expects loaves in the morning delivery.
+// Paused stops deliveries while the household is away; PausedUntil, when set, is the YYYY-MM-DD date
+// deliveries resume on, and an empty value pauses until the customer resumes by hand.
 type BreadSchedule struct {
-	Days      []string `json:"days"`
-	Frequency string   `json:"frequency"`
-	StartDate string   `json:"start_date"`
-	Notes     string   `json:"notes"`
+	Days        []string `json:"days"`
+	Frequency   string   `json:"frequency"`
+	StartDate   string   `json:"start_date"`
+	Notes       string   `json:"notes"`
+	Paused      bool     `json:"paused,omitempty"`
+	PausedUntil string   `json:"paused_until,omitempty"`
+}
+
+// PausedOn reports whether the schedule skips the given YYYY-MM-DD date.
+func (b BreadSchedule) PausedOn(date string) bool {
+	return b.Paused && (b.PausedUntil == "" || date < b.PausedUntil)
 }
 
 // CroissantSchedule lists the weekday and amount per drop so batching stays predictable.
@@ -24,23 +45,64 @@ type CroissantSchedule struct {
 }
 
 // Order aggregates all information required to deliver bakery goods around the district.
+// Lat and Lng are nil until an AddressNormalizer with a geocoder has located the address.
+// Reservations maps inventory batch ids to the units held for the order while it is pending.
+// CustomerID links the order to the customer record for its phone; zero means it was never linked.
 type Order struct {
 	ID                int64
 	CustomerName      string
 	Address           string
 	Phone             string
+	Email             string
 	Items             []OrderItem
 	BreadSchedule     BreadSchedule
 	CroissantSchedule []CroissantSchedule
 	Comment           string
+	Lat               *float64
+	Lng               *float64
+	Status            string
+	Reservations      map[int64]int
 	CreatedAt         time.Time
+	CustomerID        int64
+}
+
+// HasCustom reports whether any item is a custom one, which an admin still has to look at and price.
+func (o Order) HasCustom() bool {
+	for _, item := range o.Items {
+		if item.Custom {
+			return true
+		}
+	}
+	return false
 }
 
 // MenuItem is used to render the catalog on the landing page.
+// Available and Remaining let the storefront gray out sold-out entries instead of hiding them.
+// Featured marks the daily special the storefront lists first as its hero card.
 type MenuItem struct {
-	Name        string
-	Description string
-	Price       string
-	Image       string
-	Category    string
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	Image       string      `json:"image"`
+	Category    string      `json:"category"`
+	Available   bool        `json:"available"`
+	Remaining   int         `json:"remaining"`
+	Featured    bool        `json:"featured,omitempty"`
 }
+
+// Delivery is one concrete drop materialized from an order's recurring schedule,
+// so the bakers can plan production for an actual date instead of reading weekday lists.
+type Delivery struct {
+	ID       int64  `json:"id"`
+	OrderID  int64  `json:"order_id"`
+	Date     string `json:"date"`
+	Kind     string `json:"kind"`
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+}
+
+// Delivery kinds separate the bread round from croissant drops.
+const (
+	DeliveryBread     = "bread"
+	DeliveryCroissant = "croissant"
+)
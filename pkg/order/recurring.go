@@ -0,0 +1,238 @@
+package order
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is how delivery dates and schedule start dates are written.
+const dateLayout = "2006-01-02"
+
+// defaultDeliveryHorizon is how far ahead deliveries are generated when no horizon is configured.
+const defaultDeliveryHorizon = 7
+
+// deliveryGenerationInterval re-runs generation often enough that a new order or a new day shows up
+// within the hour; already generated days are skipped, so extra runs cost only a listing.
+const deliveryGenerationInterval = time.Hour
+
+// PlanDeliveries expands an order's schedules into the concrete deliveries between from and to,
+// both inclusive dates. Nothing is planned before the bread start date, which also anchors croissants.
+//
+// Bread goes out on the selected weekdays; "alternate" keeps only every other day counted from the
+// start date and "weekend" keeps only Saturdays and Sundays. Croissant slots repeat weekly on their day.
+// A paused bread schedule suspends the whole order, croissants included, for the paused days.
+func PlanDeliveries(order Order, from, to time.Time) []Delivery {
+	start, err := time.Parse(dateLayout, strings.TrimSpace(order.BreadSchedule.StartDate))
+	if err != nil {
+		return nil
+	}
+	if from.Before(start) {
+		from = start
+	}
+
+	breadDays := make(map[time.Weekday]bool, len(order.BreadSchedule.Days))
+	for _, day := range order.BreadSchedule.Days {
+		if weekday, ok := ParseWeekday(day); ok {
+			breadDays[weekday] = true
+		}
+	}
+
+	var deliveries []Delivery
+	for day := dateOnly(from); !day.After(dateOnly(to)); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		if order.BreadSchedule.PausedOn(date) {
+			continue
+		}
+		if breadDays[day.Weekday()] && breadFrequencyAllows(order.BreadSchedule.Frequency, start, day) {
+			deliveries = append(deliveries, Delivery{OrderID: order.ID, Date: date, Kind: DeliveryBread, Item: DeliveryBread, Quantity: 1})
+		}
+		for _, slot := range order.CroissantSchedule {
+			weekday, ok := ParseWeekday(slot.Day)
+			if !ok || weekday != day.Weekday() || slot.Quantity <= 0 {
+				continue
+			}
+			item := strings.TrimSpace(slot.Item)
+			if item == "" {
+				item = DeliveryCroissant
+			}
+			deliveries = append(deliveries, Delivery{OrderID: order.ID, Date: date, Kind: DeliveryCroissant, Item: item, Quantity: slot.Quantity})
+		}
+	}
+	return deliveries
+}
+
+// breadFrequencyAllows applies the storefront's frequency choice on top of the selected weekdays.
+func breadFrequencyAllows(frequency string, start, day time.Time) bool {
+	switch frequency {
+	case "alternate":
+		return int(day.Sub(start).Hours()/24)%2 == 0
+	case "weekend":
+		return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+	default:
+		return true
+	}
+}
+
+// ParseWeekday accepts the English weekday keys the storefront sends, in any case or spacing.
+func ParseWeekday(day string) (time.Weekday, bool) {
+	day = NormalizeDay(day)
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.ToLower(weekday.String()) == day {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// DeliversOn reports whether either schedule of the order names the weekday, so admins can list
+// the orders on one day's route. Pauses are ignored because the weekday is not a concrete date.
+func (o Order) DeliversOn(weekday time.Weekday) bool {
+	for _, day := range o.BreadSchedule.Days {
+		if parsed, ok := ParseWeekday(day); ok && parsed == weekday {
+			return true
+		}
+	}
+	for _, slot := range o.CroissantSchedule {
+		if parsed, ok := ParseWeekday(slot.Day); ok && parsed == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// nextDeliverySearchDays bounds NextDelivery's look-ahead; it covers every weekly schedule twice over
+// plus a pause of a month or so.
+const nextDeliverySearchDays = 62
+
+// NextDelivery returns the first date on or after from that the order's schedules deliver, so a
+// customer can be told when to expect the first loaf. ok is false when nothing falls within two months,
+// for example an order without schedule days or one paused for longer.
+func NextDelivery(order Order, from time.Time) (date string, ok bool) {
+	from = dateOnly(from)
+	planned := PlanDeliveries(order, from, from.AddDate(0, 0, nextDeliverySearchDays-1))
+	if len(planned) == 0 {
+		return "", false
+	}
+	// PlanDeliveries walks the days in order, so the first delivery is the earliest.
+	return planned[0].Date, true
+}
+
+// dateOnly drops the clock so day arithmetic never drifts across midnight.
+func dateOnly(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// deliveryKey identifies a generated delivery so reruns never insert the same drop twice.
+func deliveryKey(d Delivery) string {
+	return strings.Join([]string{strconv.FormatInt(d.OrderID, 10), d.Date, d.Kind, d.Item}, "|")
+}
+
+// generateDeliveries materializes every pending order's deliveries from today through the horizon,
+// skipping the ones already stored. Delivered and cancelled orders get no new drops. It runs on the
+// service goroutine like the retention sweep.
+func (s *Service) generateDeliveries() {
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+	defer cancel()
+
+	from := dateOnly(s.clock.Now().UTC())
+	to := from.AddDate(0, 0, s.horizon-1)
+	orders, err := s.repo.List(ctx)
+	if err != nil {
+		s.logger.Printf("delivery generation: listing orders failed: %v", err)
+		return
+	}
+	existing, err := s.repo.ListDeliveries(ctx, from.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		s.logger.Printf("delivery generation: listing deliveries failed: %v", err)
+		return
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, delivery := range existing {
+		seen[deliveryKey(delivery)] = true
+	}
+
+	created := 0
+	for _, order := range orders {
+		if order.Status != StatusPending {
+			continue
+		}
+		for _, delivery := range PlanDeliveries(order, from, to) {
+			key := deliveryKey(delivery)
+			if seen[key] {
+				continue
+			}
+			if _, err := s.repo.SaveDelivery(ctx, delivery); err != nil {
+				s.logger.Printf("delivery generation: saving order %d on %s failed: %v", delivery.OrderID, delivery.Date, err)
+				return
+			}
+			seen[key] = true
+			created++
+		}
+	}
+	if created > 0 {
+		s.logger.Printf("delivery generation: created %d deliveries through %s", created, to.Format(dateLayout))
+	}
+}
+
+// activeDeliveries lists stored deliveries but leaves out days on which their order is paused, and
+// every day of an order that is no longer pending or no longer stored, because deliveries generated
+// before the pause, the cancellation or the retention sweep stay in storage.
+func (s *Service) activeDeliveries(ctx context.Context, from, to string) ([]Delivery, error) {
+	deliveries, err := s.repo.ListDeliveries(ctx, from, to)
+	if err != nil || len(deliveries) == 0 {
+		return deliveries, err
+	}
+	orders, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]Order, len(orders))
+	for _, order := range orders {
+		byID[order.ID] = order
+	}
+	active := deliveries[:0]
+	for _, delivery := range deliveries {
+		order, ok := byID[delivery.OrderID]
+		if !ok || order.Status != StatusPending || order.BreadSchedule.PausedOn(delivery.Date) {
+			continue
+		}
+		active = append(active, delivery)
+	}
+	return active, nil
+}
+
+// applyPause validates and stores a pause change; a pause date must lie in the future so a typo
+// cannot silently produce a pause that already ended.
+func (s *Service) applyPause(cmd pauseCommand) (Order, error) {
+	if cmd.paused && cmd.until != "" {
+		until, err := time.Parse(dateLayout, cmd.until)
+		if err != nil {
+			return Order{}, newValidationError("paused_until", CodeBadDate, "dates must look like 2006-01-02")
+		}
+		if !until.After(dateOnly(s.clock.Now().UTC())) {
+			return Order{}, newValidationError("paused_until", CodeNotFuture, "pause end date must be in the future")
+		}
+	}
+	order, err := s.repo.Get(cmd.ctx, cmd.id)
+	if err != nil {
+		return Order{}, err
+	}
+	order.BreadSchedule.Paused = cmd.paused
+	order.BreadSchedule.PausedUntil = cmd.until
+	if err := s.repo.UpdateBreadSchedule(cmd.ctx, order.ID, order.BreadSchedule); err != nil {
+		return Order{}, err
+	}
+	if cmd.paused {
+		detail := ""
+		if cmd.until != "" {
+			detail = "until " + cmd.until
+		}
+		s.emit(cmd.ctx, order.ID, EventPaused, detail)
+	} else {
+		s.emit(cmd.ctx, order.ID, EventResumed, "")
+	}
+	return order, nil
+}
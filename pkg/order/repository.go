@@ -1,24 +1,41 @@
 package order
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"iter"
+	"strings"
 	"time"
+
+	"bakery/pkg/clock"
 )
 
+// orderColumns is the projection every order listing selects, in the order scanOrders reads it.
+const orderColumns = "id, name, address, phone, items, bread_schedule, croissant_schedule, comment, lat, lng, status, reservations, created_at, email, customer_id"
+
 // Repository coordinates the persistence of orders through database/sql so the service stays storage-agnostic.
 type Repository struct {
-	db *sql.DB
+	db    *sql.DB
+	clock clock.Clock
 }
 
 // NewRepository wires the database handle so calls can be fanned out from background goroutines.
-func NewRepository(db *sql.DB) *Repository {
-	return &Repository{db: db}
+// clk stamps CreatedAt on saved records; nil uses the system clock.
+func NewRepository(db *sql.DB, clk clock.Clock) *Repository {
+	return &Repository{db: db, clock: clock.OrReal(clk)}
 }
 
 // Save inserts a new order into the database while delegating serialization details to this layer.
+// created_at is stamped here and stored with the row, so the time a listing reads back is the one
+// Save returned; an order that already carries a time, such as one restored from a backup, keeps it.
 func (r *Repository) Save(ctx context.Context, order Order) (Order, error) {
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = r.clock.Now().UTC()
+	}
+	order.CreatedAt = order.CreatedAt.UTC()
+
 	items, err := json.Marshal(order.Items)
 	if err != nil {
 		return Order{}, err
@@ -32,8 +49,15 @@ func (r *Repository) Save(ctx context.Context, order Order) (Order, error) {
 		return Order{}, err
 	}
 
-	query := "INSERT INTO orders (name, address, phone, items, bread_schedule, croissant_schedule, comment) VALUES (?, ?, ?, ?, ?, ?, ?)"
-	result, err := r.db.ExecContext(ctx, query, order.CustomerName, order.Address, order.Phone, string(items), string(breadPlan), string(croissantPlan), order.Comment)
+	var reservations []byte
+	if len(order.Reservations) > 0 {
+		if reservations, err = json.Marshal(order.Reservations); err != nil {
+			return Order{}, err
+		}
+	}
+
+	query := "INSERT INTO orders (name, address, phone, items, bread_schedule, croissant_schedule, comment, lat, lng, status, reservations, created_at, email, customer_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	result, err := r.db.ExecContext(ctx, query, order.CustomerName, order.Address, order.Phone, string(items), string(breadPlan), string(croissantPlan), order.Comment, nullableFloat(order.Lat), nullableFloat(order.Lng), order.Status, string(reservations), order.CreatedAt, order.Email, nullableID(order.CustomerID))
 	if err != nil {
 		return Order{}, err
 	}
@@ -44,47 +68,343 @@ func (r *Repository) Save(ctx context.Context, order Order) (Order, error) {
 	}
 
 	order.ID = id
-	order.CreatedAt = time.Now().UTC()
 	return order, nil
 }
 
 // List fetches all orders to support administrative views or dashboards if needed.
 func (r *Repository) List(ctx context.Context) ([]Order, error) {
-	query := "SELECT id, name, address, phone, items, bread_schedule, croissant_schedule, comment FROM orders ORDER BY id DESC"
-	rows, err := r.db.QueryContext(ctx, query)
+	return r.ListPage(ctx, Page{})
+}
+
+// Get loads one order by id, or ErrNotFound, so changing a single order does not read them all.
+func (r *Repository) Get(ctx context.Context, id int64) (Order, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+orderColumns+" FROM orders WHERE id = ?", id)
+	if err != nil {
+		return Order{}, err
+	}
+	for order, err := range orderCursor(rows) {
+		return order, err
+	}
+	return Order{}, ErrNotFound
+}
+
+// ListCursor is List one order at a time; see ListPageCursor.
+func (r *Repository) ListCursor(ctx context.Context) (iter.Seq2[Order, error], error) {
+	return r.ListPageCursor(ctx, Page{})
+}
+
+// Page narrows an order listing to one window, newest first unless Sort says otherwise. A zero
+// Limit returns every order from Offset on; an empty Status matches all statuses and a zero
+// CustomerID all customers.
+type Page struct {
+	Limit      int
+	Offset     int
+	Status     string
+	CustomerID int64
+	Sort       Sort
+}
+
+// ListPage returns one window of orders, newest first. Paging and filtering happen in the query, so
+// the database hands over only the rows on the page.
+func (r *Repository) ListPage(ctx context.Context, page Page) ([]Order, error) {
+	query, args := pageQuery(page)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return scanOrders(rows)
+}
+
+// ListPageCursor runs the ListPage query but decodes each order only when the cursor reaches it, so
+// streaming a long listing never holds more than one decoded order. The query runs before it
+// returns, so a failing database is reported here rather than mid-stream.
+//
+// The cursor holds the rows open until it is ranged to the end or the loop breaks out early, so
+// the caller must range over it exactly once.
+func (r *Repository) ListPageCursor(ctx context.Context, page Page) (iter.Seq2[Order, error], error) {
+	query, args := pageQuery(page)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return orderCursor(rows), nil
+}
+
+// pageQuery builds the ListPage statement and its arguments.
+func pageQuery(page Page) (string, []any) {
+	query := "SELECT " + orderColumns + " FROM orders"
+	var (
+		where []string
+		args  []any
+	)
+	if page.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, page.Status)
+	}
+	if page.CustomerID != 0 {
+		where = append(where, "customer_id = ?")
+		args = append(args, page.CustomerID)
+	}
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += page.Sort.orderClause()
+	if page.Limit > 0 || page.Offset > 0 {
+		// SQL has no OFFSET without LIMIT; -1 is the "no limit" SQLite and the memory driver accept.
+		limit := page.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, page.Offset)
+	}
+	return query, args
+}
+
+// ListBefore returns the orders created before the cutoff so retention can archive them ahead of deletion.
+func (r *Repository) ListBefore(ctx context.Context, cutoff time.Time) ([]Order, error) {
+	query := "SELECT " + orderColumns + " FROM orders WHERE created_at < ? ORDER BY id"
+	rows, err := r.db.QueryContext(ctx, query, cutoff.UTC())
+	if err != nil {
+		return nil, err
+	}
+	return scanOrders(rows)
+}
+
+// ListBetween returns the orders created within [from, to], oldest first, for period reports such as
+// the monthly accounting export.
+func (r *Repository) ListBetween(ctx context.Context, from, to time.Time) ([]Order, error) {
+	rows, err := r.db.QueryContext(ctx, betweenQuery, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	return scanOrders(rows)
+}
+
+// ListBetweenCursor is ListBetween one order at a time, with the same rules as ListPageCursor.
+func (r *Repository) ListBetweenCursor(ctx context.Context, from, to time.Time) (iter.Seq2[Order, error], error) {
+	rows, err := r.db.QueryContext(ctx, betweenQuery, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	return orderCursor(rows), nil
+}
 
+// betweenQuery selects orders by creation time for ListBetween and ListBetweenCursor.
+const betweenQuery = "SELECT " + orderColumns + " FROM orders WHERE created_at BETWEEN ? AND ? ORDER BY id"
+
+// DeleteBefore removes every order created before the cutoff in one statement and reports how many went.
+func (r *Repository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM orders WHERE created_at < ?", cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scanOrders decodes every row of the shared order projection so each listing query parses rows the
+// same way.
+func scanOrders(rows *sql.Rows) ([]Order, error) {
 	var orders []Order
-	for rows.Next() {
-		var (
-			order         Order
-			itemsData     string
-			breadData     string
-			croissantData string
-		)
-
-		if err := rows.Scan(&order.ID, &order.CustomerName, &order.Address, &order.Phone, &itemsData, &breadData, &croissantData, &order.Comment); err != nil {
+	for order, err := range orderCursor(rows) {
+		if err != nil {
 			return nil, err
 		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
 
-		if err := json.Unmarshal([]byte(itemsData), &order.Items); err != nil {
-			return nil, err
+// Count reports how many orders are stored without loading them, for pagination totals and dashboards.
+func (r *Repository) Count(ctx context.Context) (int64, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM orders").Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// orderCursor decodes the rows one order per step and closes them once the loop ends, including
+// when the caller breaks out early. A decode error is yielded once and ends the cursor.
+func orderCursor(rows *sql.Rows) iter.Seq2[Order, error] {
+	return func(yield func(Order, error) bool) {
+		defer rows.Close()
+		var scanner orderScanner
+		for rows.Next() {
+			order, err := scanner.scan(rows)
+			if err != nil {
+				yield(Order{}, err)
+				return
+			}
+			if !yield(order, nil) {
+				return
+			}
 		}
-		if err := json.Unmarshal([]byte(breadData), &order.BreadSchedule); err != nil {
-			return nil, err
+		if err := rows.Err(); err != nil {
+			yield(Order{}, err)
 		}
-		if err := json.Unmarshal([]byte(croissantData), &order.CroissantSchedule); err != nil {
+	}
+}
+
+// orderScanner holds the scan targets for the order projection. Text columns are scanned as
+// nullable because SQL backends may hold NULL where the memory driver always has a string; a NULL
+// reads as an empty value rather than failing the whole listing.
+//
+// The targets are shared by every row, so a long listing does not allocate a fresh set per row. The
+// JSON columns are scanned as raw bytes into buffers reused across rows and decoded straight from
+// them, instead of being copied into a string and back into bytes for every row.
+type orderScanner struct {
+	id                                                    int64
+	name, address, phone, comment, status, email          sql.NullString
+	itemsData, breadData, croissantData, reservationsData sql.RawBytes
+	lat, lng                                              sql.NullFloat64
+	createdAt                                             sql.NullTime
+	customerID                                            sql.NullInt64
+}
+
+// scan decodes the row rows is positioned on.
+func (s *orderScanner) scan(rows *sql.Rows) (Order, error) {
+	if err := rows.Scan(&s.id, &s.name, &s.address, &s.phone, &s.itemsData, &s.breadData, &s.croissantData, &s.comment, &s.lat, &s.lng, &s.status, &s.reservationsData, &s.createdAt, &s.email, &s.customerID); err != nil {
+		return Order{}, err
+	}
+	order := Order{
+		ID:           s.id,
+		CustomerName: s.name.String,
+		Address:      s.address.String,
+		Phone:        s.phone.String,
+		Comment:      s.comment.String,
+		Email:        s.email.String,
+		CustomerID:   s.customerID.Int64,
+		Status:       s.status.String,
+	}
+	if s.createdAt.Valid {
+		order.CreatedAt = s.createdAt.Time.UTC()
+	}
+	if s.lat.Valid && s.lng.Valid {
+		latitude, longitude := s.lat.Float64, s.lng.Float64
+		order.Lat, order.Lng = &latitude, &longitude
+	}
+	if order.Status == "" {
+		order.Status = StatusPending
+	}
+
+	if err := decodeColumn(s.itemsData, &order.Items); err != nil {
+		return Order{}, err
+	}
+	if err := decodeColumn(s.breadData, &order.BreadSchedule); err != nil {
+		return Order{}, err
+	}
+	if err := decodeColumn(s.croissantData, &order.CroissantSchedule); err != nil {
+		return Order{}, err
+	}
+	if err := decodeColumn(s.reservationsData, &order.Reservations); err != nil {
+		return Order{}, err
+	}
+	// Empty slices keep JSON responses as [] instead of null for rows that had nothing stored.
+	if order.Items == nil {
+		order.Items = []OrderItem{}
+	}
+	if order.CroissantSchedule == nil {
+		order.CroissantSchedule = []CroissantSchedule{}
+	}
+	return order, nil
+}
+
+// UpdateStatus records a status change; the reservation settlement happens in the service beforehand.
+func (r *Repository) UpdateStatus(ctx context.Context, id int64, status string) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE orders SET status = ? WHERE id = ?", status, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// nullableID stores an unset reference as NULL so it never points at a row that does not exist.
+func nullableID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// nullableFloat stores missing coordinates as NULL rather than as a point in the ocean off Africa.
+func nullableFloat(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// decodeColumn unmarshals a JSON column, leaving dest at its zero value when the column is NULL or blank.
+// Blank values turn up in legacy and hand-inserted rows; one of them must not take down the admin board.
+func decodeColumn(column sql.RawBytes, dest any) error {
+	if len(bytes.TrimSpace(column)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(column, dest)
+}
+
+// SaveDelivery stores one generated delivery.
+func (r *Repository) SaveDelivery(ctx context.Context, delivery Delivery) (Delivery, error) {
+	query := "INSERT INTO deliveries (order_id, delivery_date, kind, item, quantity) VALUES (?, ?, ?, ?, ?)"
+	result, err := r.db.ExecContext(ctx, query, delivery.OrderID, delivery.Date, delivery.Kind, delivery.Item, delivery.Quantity)
+	if err != nil {
+		return Delivery{}, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return Delivery{}, err
+	}
+	delivery.ID = id
+	return delivery, nil
+}
+
+// ListDeliveries returns the deliveries dated between from and to inclusive, as YYYY-MM-DD strings.
+func (r *Repository) ListDeliveries(ctx context.Context, from, to string) ([]Delivery, error) {
+	query := "SELECT id, order_id, delivery_date, kind, item, quantity FROM deliveries WHERE delivery_date >= ? AND delivery_date <= ? ORDER BY delivery_date, id"
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		var delivery Delivery
+		if err := rows.Scan(&delivery.ID, &delivery.OrderID, &delivery.Date, &delivery.Kind, &delivery.Item, &delivery.Quantity); err != nil {
 			return nil, err
 		}
-		orders = append(orders, order)
+		deliveries = append(deliveries, delivery)
 	}
-
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	return deliveries, nil
+}
 
-	return orders, nil
+// UpdateBreadSchedule rewrites the bread schedule of one order, which is how pauses are stored.
+func (r *Repository) UpdateBreadSchedule(ctx context.Context, id int64, schedule BreadSchedule) error {
+	breadPlan, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.ExecContext(ctx, "UPDATE orders SET bread_schedule = ? WHERE id = ?", string(breadPlan), id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
@@ -0,0 +1,381 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+
+	"bakery/pkg/clock"
+	"bakery/pkg/storage/memorydriver"
+)
+
+// testNow is the fixed "now" of the order tests, a Thursday morning.
+var testNow = time.Date(2026, time.October, 15, 6, 0, 0, 0, time.UTC)
+
+// insertRawOrder stores a row the way a legacy build or a hand-written INSERT might have left it,
+// with the JSON columns exactly as given.
+func insertRawOrder(t *testing.T, db *sql.DB, items, bread, croissant any) {
+	t.Helper()
+	_, err := db.Exec("INSERT INTO orders (name, address, phone, items, bread_schedule, croissant_schedule, comment, lat, lng, status, reservations) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		"Legacy", "ул. Ленина 1", "+79000000001", items, bread, croissant, "", nil, nil, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListToleratesEmptyJSONColumns(t *testing.T) {
+	tests := []struct {
+		name                    string
+		items, bread, croissant any
+	}{
+		{"empty strings", "", "", ""},
+		{"blank strings", "  ", "\n", " \t "},
+		{"NULL columns", nil, nil, nil},
+		{"empty items only", "", `{"days":["monday"],"frequency":"everyday","start_date":"2026-10-19"}`, `[]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewManual(testNow)
+			repo := NewRepository(memorydriver.OpenTest(t, clk), clk)
+			insertRawOrder(t, repo.db, tt.items, tt.bread, tt.croissant)
+
+			orders, err := repo.List(context.Background())
+			if err != nil {
+				t.Fatalf("List failed on a row with empty JSON columns: %v", err)
+			}
+			if len(orders) != 1 {
+				t.Fatalf("List returned %d orders, want 1", len(orders))
+			}
+			got := orders[0]
+			if got.Items == nil || got.CroissantSchedule == nil {
+				t.Errorf("empty columns decoded to nil slices: items %v, croissants %v", got.Items, got.CroissantSchedule)
+			}
+			if got.Status != StatusPending {
+				t.Errorf("Status = %q, want %q", got.Status, StatusPending)
+			}
+		})
+	}
+}
+
+func TestListStillReportsMalformedJSON(t *testing.T) {
+	clk := clock.NewManual(testNow)
+	repo := NewRepository(memorydriver.OpenTest(t, clk), clk)
+	insertRawOrder(t, repo.db, "[{", "{}", "[]")
+	if _, err := repo.List(context.Background()); err == nil {
+		t.Fatal("List accepted a truncated items column")
+	}
+}
+
+func TestCount(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewManual(testNow)
+	repo := NewRepository(memorydriver.OpenTest(t, clk), clk)
+	for want := int64(0); want < 3; want++ {
+		got, err := repo.Count(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("Count = %d, want %d", got, want)
+		}
+		if _, err := repo.Save(ctx, Order{CustomerName: "Ivan", Items: []OrderItem{{Name: "Хлеб", Quantity: 1}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewManual(testNow)
+	repo := NewRepository(memorydriver.OpenTest(t, clk), clk)
+	names := []string{"Ivan", "Olga", "Petr"}
+	for _, name := range names {
+		if _, err := repo.Save(ctx, Order{CustomerName: name, Items: []OrderItem{{Name: "Хлеб", Quantity: 1}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		id      int64
+		want    string
+		wantErr error
+	}{
+		{1, "Ivan", nil},
+		{3, "Petr", nil},
+		{0, "", ErrNotFound},
+		{4, "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		got, err := repo.Get(ctx, tt.id)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Get(%d) error = %v, want %v", tt.id, err, tt.wantErr)
+			continue
+		}
+		if got.CustomerName != tt.want || (tt.wantErr == nil && got.ID != tt.id) {
+			t.Errorf("Get(%d) = order %d for %q, want %q", tt.id, got.ID, got.CustomerName, tt.want)
+		}
+	}
+}
+
+// A single row with empty schedule JSON used to fail every listing it appeared in, which took the
+// admin board down with a 500.
+func TestEmptyScheduleRowDoesNotBreakListings(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewManual(testNow)
+	repo := NewRepository(memorydriver.OpenTest(t, clk), clk)
+	good, err := repo.Save(ctx, Order{
+		CustomerName:      "Ivan",
+		Items:             []OrderItem{{Name: "Хлеб", Quantity: 1}},
+		BreadSchedule:     BreadSchedule{Days: []string{"monday"}, Frequency: "everyday", StartDate: "2026-10-19"},
+		CroissantSchedule: []CroissantSchedule{{Day: "monday", Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	insertRawOrder(t, repo.db, `[{"name":"Хлеб","quantity":2}]`, "", "")
+
+	svc := NewService(repo, Options{Clock: clk})
+	defer svc.Close()
+	orders, err := svc.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed with an empty schedule row present: %v", err)
+	}
+	if len(orders) != 2 {
+		t.Fatalf("List returned %d orders, want 2", len(orders))
+	}
+	for _, o := range orders {
+		if o.ID == good.ID {
+			if len(o.BreadSchedule.Days) != 1 || len(o.CroissantSchedule) != 1 {
+				t.Errorf("intact order lost its schedules: %+v", o)
+			}
+			continue
+		}
+		if len(o.BreadSchedule.Days) != 0 || len(o.CroissantSchedule) != 0 {
+			t.Errorf("empty schedule row decoded to %+v and %+v, want empty", o.BreadSchedule, o.CroissantSchedule)
+		}
+		if _, ok := NextDelivery(o, testNow); ok {
+			t.Errorf("order without schedules has a next delivery")
+		}
+	}
+
+	cursor, err := repo.ListCursor(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, err := range cursor {
+		if err != nil {
+			t.Fatalf("cursor failed on the empty schedule row: %v", err)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("cursor yielded %d orders, want 2", count)
+	}
+}
+
+func TestCursorsCloseRowsOnEarlyBreak(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewManual(testNow)
+	db := memorydriver.OpenTest(t, clk)
+	// With one connection, rows left open would hold it and the next query would wait forever.
+	db.SetMaxOpenConns(1)
+	// The malformed row is the oldest, so newest-first cursors reach it last, and the good orders
+	// are a minute younger, so a range can leave it out.
+	insertRawOrder(t, db, "[", "{}", "[]")
+	clk.Advance(time.Minute)
+	repo := NewRepository(db, clk)
+	for range 5 {
+		if _, err := repo.Save(ctx, Order{CustomerName: "Ivan", Items: []OrderItem{{Name: "Хлеб", Quantity: 1}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		open   func(context.Context) (iter.Seq2[Order, error], error)
+		breaks int
+	}{
+		{"ListCursor after two", func(ctx context.Context) (iter.Seq2[Order, error], error) { return repo.ListCursor(ctx) }, 2},
+		{"ListCursor on the first", func(ctx context.Context) (iter.Seq2[Order, error], error) { return repo.ListCursor(ctx) }, 1},
+		{"ListPageCursor", func(ctx context.Context) (iter.Seq2[Order, error], error) {
+			return repo.ListPageCursor(ctx, Page{Limit: 4})
+		}, 2},
+		{"ListBetweenCursor", func(ctx context.Context) (iter.Seq2[Order, error], error) {
+			return repo.ListBetweenCursor(ctx, testNow.Add(time.Second), testNow.Add(time.Hour))
+		}, 3},
+		// Reporting the malformed row ends the cursor, which must close the rows too.
+		{"decode error", func(ctx context.Context) (iter.Seq2[Order, error], error) { return repo.ListCursor(ctx) }, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every query gets a deadline, so rows left open fail the test instead of hanging it.
+			ctx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+			cursor, err := tt.open(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen, failed := 0, false
+			for _, err := range cursor {
+				if err != nil {
+					if tt.breaks != 0 {
+						t.Fatalf("unexpected error after %d orders: %v", seen, err)
+					}
+					failed = true
+					continue
+				}
+				seen++
+				if seen == tt.breaks {
+					break
+				}
+			}
+			if tt.breaks == 0 && (!failed || seen != 5) {
+				t.Errorf("read %d orders, failed %v; want the 5 good ones and then the error", seen, failed)
+			}
+			if inUse := db.Stats().InUse; inUse != 0 {
+				t.Errorf("%d connections still in use after the loop ended", inUse)
+			}
+			if _, err := repo.Count(ctx); err != nil {
+				t.Errorf("next query failed, the rows were left open: %v", err)
+			}
+		})
+	}
+}
+
+func TestSaveStampsCreatedAtFromTheClock(t *testing.T) {
+	clk := clock.NewManual(testNow)
+	repo := NewRepository(memorydriver.OpenTest(t, clk), clk)
+	clk.Advance(90 * time.Minute)
+	saved, err := repo.Save(context.Background(), Order{CustomerName: "Ivan", Items: []OrderItem{{Name: "Хлеб", Quantity: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testNow.Add(90 * time.Minute); !saved.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v from the clock", saved.CreatedAt, want)
+	}
+}
+
+func TestCreatedAtSurvivesList(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewManual(testNow.Add(1234567 * time.Nanosecond))
+	repo := NewRepository(memorydriver.OpenTest(t, clk), clk)
+	fresh, err := repo.Save(ctx, Order{CustomerName: "Ivan", Items: []OrderItem{{Name: "Хлеб", Quantity: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A restored order arrives with its original time, possibly in another zone.
+	moscow := time.FixedZone("MSK", 3*60*60)
+	placed := time.Date(2025, 3, 1, 9, 30, 0, 0, moscow)
+	restored, err := repo.Save(ctx, Order{CustomerName: "Olga", Items: []OrderItem{{Name: "Хлеб", Quantity: 1}}, CreatedAt: placed})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		id   int64
+		want time.Time
+	}{
+		{"stamped by Save", fresh.ID, clk.Now()},
+		{"kept from the caller", restored.ID, placed},
+	}
+	orders, err := repo.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := make(map[int64]Order, len(orders))
+	for _, o := range orders {
+		listed[o.ID] = o
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := listed[tt.id].CreatedAt
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("listed CreatedAt = %v, want %v in UTC", got, tt.want)
+			}
+			encoded, err := json.Marshal(listed[tt.id])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := `"CreatedAt":"` + tt.want.UTC().Format(time.RFC3339Nano) + `"`; !strings.Contains(string(encoded), want) {
+				t.Errorf("JSON %s lacks %s", encoded, want)
+			}
+		})
+	}
+}
+
+// benchOrders is the history size the Save and List benchmarks run against, a few months of a busy
+// bakery.
+const benchOrders = 5000
+
+// benchOrder is a typical storefront order: two items, a weekly bread plan and two croissant days.
+func benchOrder() Order {
+	return Order{
+		CustomerName:      "Ivan",
+		Address:           "Lenina 1",
+		Phone:             "+79990000000",
+		Items:             []OrderItem{{Name: "Хлеб", Quantity: 2}, {Name: "Круассан", Quantity: 4, Options: []string{"без сахара"}}},
+		BreadSchedule:     BreadSchedule{Days: []string{"monday", "wednesday", "friday"}, Frequency: "everyday", StartDate: "2026-10-19"},
+		CroissantSchedule: []CroissantSchedule{{Day: "tuesday", Quantity: 2}, {Day: "saturday", Quantity: 6, Item: "Круассан"}},
+		Status:            StatusPending,
+	}
+}
+
+// seedOrders stores n copies of benchOrder.
+func seedOrders(b *testing.B, repo *Repository, n int) {
+	b.Helper()
+	for range n {
+		if _, err := repo.Save(context.Background(), benchOrder()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSave stores one more order on top of benchOrders against the memory driver. Every insert
+// used to copy every table for the next snapshot write; now the persistence goroutine takes the
+// snapshot when it writes. Measured with 5,000 orders when that change went in:
+//
+//	before: ~0.9 ms/op   1.09 MB/op   33 allocs/op
+//	after:  ~9 µs/op     2.4 KB/op    32 allocs/op
+func BenchmarkSave(b *testing.B) {
+	clk := clock.NewManual(testNow)
+	repo := NewRepository(memorydriver.OpenTest(b, clk), clk)
+	seedOrders(b, repo, benchOrders)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		if _, err := repo.Save(ctx, benchOrder()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkList reads back all benchOrders, as the admin board does on every refresh. The scan
+// used to allocate fresh targets and copy each JSON column into a string per row, and sortRows
+// copied every row to sort it. Measured with 5,000 orders when the scan started reusing its targets:
+//
+//	before: ~35-44 ms/op   18.0 MB/op   224,552 allocs/op
+//	after:  ~29-32 ms/op   12.1 MB/op   129,566 allocs/op
+func BenchmarkList(b *testing.B) {
+	clk := clock.NewManual(testNow)
+	repo := NewRepository(memorydriver.OpenTest(b, clk), clk)
+	seedOrders(b, repo, benchOrders)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		orders, err := repo.List(ctx)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(orders) != benchOrders {
+			b.Fatalf("listed %d orders, want %d", len(orders), benchOrders)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package order
+
+import (
+	"context"
+	"time"
+)
+
+// CheckRestore applies the rules Restore stores a backed-up order under, without storing anything,
+// so an import can check every record before it writes any of them. Only the record itself is
+// judged: schedules, contact details, items and status. The menu, the minimum order and the stock
+// were checked when the order was placed, and a batch sold out since, or a minimum raised since,
+// must not make history impossible to restore.
+func (s *Service) CheckRestore(order Order) error {
+	_, err := s.normalizeForRestore(order)
+	return err
+}
+
+// normalizeForRestore canonicalizes the schedules like Submit and validates the result.
+func (s *Service) normalizeForRestore(order Order) (Order, error) {
+	normalized, err := normalizeSchedules(order, s.duplicateDays)
+	if err != nil {
+		return Order{}, err
+	}
+	if err := validateOrder(normalized, s.clock.Now()); err != nil {
+		return Order{}, err
+	}
+	return normalized, nil
+}
+
+// Restore stores an order from a backup as it was: its status, created_at and reservations are
+// kept, and nothing is reserved, because the restored batches carry their reserved units already.
+// The reservations must name the batch ids the order has in this store. No duplicate check runs
+// and no created event is published, since the order was placed long before.
+func (s *Service) Restore(ctx context.Context, order Order) (Order, error) {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, order: order, reply: reply}
+
+	select {
+	case s.restores <- cmd:
+	case <-ctx.Done():
+		return Order{}, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return Order{}, s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res.order, res.err
+	case <-ctx.Done():
+		return Order{}, waitErr(ctx)
+	}
+}
+
+// restore runs on the service goroutine, so a restored order cannot interleave with a submission.
+func (s *Service) restore(cmd command) commandResult {
+	order, err := s.normalizeForRestore(cmd.order)
+	if err != nil {
+		return commandResult{err: err}
+	}
+	if order.Status == "" {
+		order.Status = StatusPending
+	}
+	stored, err := s.repo.Save(cmd.ctx, s.linkCustomer(cmd.ctx, order))
+	return commandResult{order: stored, err: err}
+}
@@ -0,0 +1,70 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// defaultRetentionInterval spaces the sweeps out; old orders only need to go eventually, not the moment they expire.
+const defaultRetentionInterval = time.Hour
+
+// backgroundTimeout bounds one retention sweep or delivery generation run so a slow database
+// cannot stall the order goroutine for long.
+const backgroundTimeout = 30 * time.Second
+
+// archivedOrder is one line in the append-only archive; the timestamp records when the order left the live store.
+type archivedOrder struct {
+	ArchivedAt time.Time `json:"archived_at"`
+	Order      Order     `json:"order"`
+}
+
+// purgeExpired removes orders older than the retention window, archiving them first when an archive file is set.
+// It runs on the service goroutine so a sweep never interleaves with a Submit or List.
+func (s *Service) purgeExpired() {
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+	defer cancel()
+
+	cutoff := s.clock.Now().UTC().Add(-s.retention)
+	if s.archivePath != "" {
+		expired, err := s.repo.ListBefore(ctx, cutoff)
+		if err != nil {
+			s.logger.Printf("order retention: listing expired orders failed: %v", err)
+			return
+		}
+		if len(expired) == 0 {
+			return
+		}
+		// Nothing is deleted unless the archive write succeeded, so a full disk never loses orders.
+		if err := appendArchive(s.archivePath, expired, s.clock.Now().UTC()); err != nil {
+			s.logger.Printf("order retention: archiving to %s failed: %v", s.archivePath, err)
+			return
+		}
+	}
+
+	removed, err := s.repo.DeleteBefore(ctx, cutoff)
+	if err != nil {
+		s.logger.Printf("order retention: deleting orders before %s failed: %v", cutoff.Format(time.RFC3339), err)
+		return
+	}
+	if removed > 0 {
+		s.logger.Printf("order retention: removed %d orders created before %s", removed, cutoff.Format(time.RFC3339))
+	}
+}
+
+// appendArchive writes one JSON line per order so the archive can grow forever without being rewritten.
+func appendArchive(path string, orders []Order, now time.Time) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for _, order := range orders {
+		if err := encoder.Encode(archivedOrder{ArchivedAt: now, Order: order}); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
@@ -0,0 +1,64 @@
+package order
+
+import "math"
+
+// Point is a location in decimal degrees.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// PlanRoute orders stops with the nearest-neighbor heuristic: from the depot, always drive to the
+// closest stop not yet visited. It returns indexes into stops. A nil stop has no coordinates and is
+// appended after the located ones in its original position order, so callers passing stops in
+// submission order get submission order as the fallback. A nil depot starts at the first located stop.
+//
+// Nearest neighbor is not optimal, but for one district's morning run it is close enough and needs
+// no solver.
+func PlanRoute(depot *Point, stops []*Point) []int {
+	route := make([]int, 0, len(stops))
+	visited := make([]bool, len(stops))
+	var located int
+	for _, stop := range stops {
+		if stop != nil {
+			located++
+		}
+	}
+
+	current := depot
+	for len(route) < located {
+		next := -1
+		best := math.Inf(1)
+		for i, stop := range stops {
+			if stop == nil || visited[i] {
+				continue
+			}
+			if current == nil {
+				next = i
+				break
+			}
+			if d := distance(*current, *stop); d < best {
+				next, best = i, d
+			}
+		}
+		visited[next] = true
+		route = append(route, next)
+		current = stops[next]
+	}
+
+	for i, stop := range stops {
+		if stop == nil {
+			route = append(route, i)
+		}
+	}
+	return route
+}
+
+// distance uses the equirectangular approximation, which is accurate over a few kilometres and only
+// has to rank candidates, not measure them.
+func distance(a, b Point) float64 {
+	const rad = math.Pi / 180
+	x := (b.Lng - a.Lng) * rad * math.Cos((a.Lat+b.Lat)/2*rad)
+	y := (b.Lat - a.Lat) * rad
+	return math.Hypot(x, y)
+}
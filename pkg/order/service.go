@@ -3,37 +3,130 @@ package order
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"bakery/pkg/breaker"
+	"bakery/pkg/clock"
+	"bakery/pkg/events"
+	"bakery/pkg/metrics"
+)
+
+// Validation codes let clients react to a rule violation without matching on the message wording.
+const (
+	CodeRequired    = "required"
+	CodeNotPositive = "not_positive"
+	CodeUnknownItem = "unknown_item"
+	CodeDuplicate   = "duplicate"
+	CodeTooMany     = "too_many"
+	CodeTooLong     = "too_long"
+	CodeBadDate     = "bad_date"
+	CodeNotFuture   = "not_future"
+	CodeOutOfStock  = "out_of_stock"
+	// CodeBelowMinimum and CodeNoPrice come from the minimum order check.
+	CodeBelowMinimum = "below_minimum"
+	CodeNoPrice      = "no_price"
+	// CodeUnknownStatus and CodeFinished reject status changes.
+	CodeUnknownStatus = "unknown_status"
+	CodeFinished      = "finished"
+	CodeBadEmail      = "bad_email"
+)
+
+// Item options are notes for the bakers, so they are kept short and few. A custom item's
+// description is the whole brief for something not on the menu, so it gets more room.
+const (
+	MaxItemOptions       = 5
+	MaxItemOptionLength  = 40
+	MaxCustomDescription = 500
+)
+
+// DuplicateDayPolicy decides what happens when a schedule names the same day twice.
+type DuplicateDayPolicy string
+
+const (
+	// DuplicateDaysReject reports repeated days as validation errors.
+	DuplicateDaysReject DuplicateDayPolicy = "reject"
+	// DuplicateDaysMerge folds repeated days together, summing croissant quantities for the same item.
+	DuplicateDaysMerge DuplicateDayPolicy = "merge"
 )
 
 // validationError communicates rule violations back to HTTP handlers.
 type validationError struct {
 	message string
+	field   string
+	code    string
 }
 
 func (e validationError) Error() string { return e.message }
 
 // newValidationError keeps the constructor private to the package.
-func newValidationError(msg string) error {
-	return validationError{message: msg}
+func newValidationError(field, code, msg string) error {
+	return validationError{message: msg, field: field, code: code}
 }
 
+// validationErrors gathers every rule violation so customers can fix the whole form in one pass.
+type validationErrors []error
+
+func (e validationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap exposes the individual failures so errors.As and callers listing field errors can reach them.
+func (e validationErrors) Unwrap() []error { return e }
+
 // IsValidation helps callers distinguish between business and infrastructure failures.
 func IsValidation(err error) bool {
 	var v validationError
 	return errors.As(err, &v)
 }
 
+// ValidationField names the offending payload field so the storefront can highlight it; empty for other errors.
+func ValidationField(err error) string {
+	var v validationError
+	if errors.As(err, &v) {
+		return v.field
+	}
+	return ""
+}
+
+// ValidationCode returns the machine-readable rule that failed; empty for other errors.
+func ValidationCode(err error) string {
+	var v validationError
+	if errors.As(err, &v) {
+		return v.code
+	}
+	return ""
+}
+
 // command envelopes the work the service goroutine must perform.
+// ctx is the caller's context so catalog checks and the insert stop when the request is cancelled;
+// reply is buffered so a caller that already gave up never blocks the loop.
 type command struct {
+	ctx   context.Context
 	order Order
-	reply chan commandResult
+	// queued is when Submit was called, so the loop can tell how long the order waited for it.
+	queued time.Time
+	reply  chan commandResult
 }
 
 // query allows different consumers to request the current order list.
 type query struct {
-	reply chan queryResult
+	ctx  context.Context
+	page Page
+	// from and to select orders by creation time instead of a page; a zero to means no range.
+	from, to time.Time
+	// cursor asks for the orders as a cursor the caller ranges over instead of a loaded slice.
+	cursor bool
+	reply  chan queryResult
 }
 
 // commandResult contains the stored order or an error to propagate back to the caller.
@@ -45,22 +138,164 @@ type commandResult struct {
 // queryResult contains the aggregated orders alongside potential failures.
 type queryResult struct {
 	orders []Order
+	cursor iter.Seq2[Order, error]
 	err    error
 }
 
+// deliveryQuery asks for the generated deliveries within a date range.
+type deliveryQuery struct {
+	ctx      context.Context
+	from, to string
+	reply    chan deliveryResult
+}
+
+// deliveryResult carries the deliveries or the failure back to the caller.
+type deliveryResult struct {
+	deliveries []Delivery
+	err        error
+}
+
+// pauseCommand pauses or resumes the bread schedule of a single order.
+type pauseCommand struct {
+	ctx    context.Context
+	id     int64
+	paused bool
+	until  string
+	reply  chan commandResult
+}
+
+// Catalog answers whether an item name can be ordered; the inventory service satisfies it.
+type Catalog interface {
+	HasItem(ctx context.Context, name string) (bool, error)
+}
+
+// Options tunes optional business rules so bakeries can pick how strict ordering should be.
+type Options struct {
+	// Catalog, when set, rejects order items whose names are not in stock listings.
+	// Leave it nil for bakeries that accept freeform custom orders.
+	Catalog Catalog
+	// DuplicateDays defaults to DuplicateDaysReject when empty.
+	DuplicateDays DuplicateDayPolicy
+	// Retention, when positive, makes the service periodically delete orders older than this window.
+	// Zero keeps every order forever and starts no background sweep.
+	Retention time.Duration
+	// RetentionInterval spaces the sweeps; it defaults to one hour.
+	RetentionInterval time.Duration
+	// ArchivePath, when set, receives expired orders as JSON lines before they are deleted.
+	ArchivePath string
+	// GenerateDeliveries starts an hourly task that materializes recurring schedules into dated deliveries.
+	GenerateDeliveries bool
+	// DeliveryHorizon is how many days ahead, today included, deliveries are generated; it defaults to 7.
+	DeliveryHorizon int
+	// MinOrderCents, when positive, rejects orders whose items total less; Pricer supplies the prices
+	// and the check is skipped without one. Currency is how the shortfall is printed; empty means RUB.
+	MinOrderCents int
+	Pricer        Pricer
+	Currency      string
+	// DedupWindow, when positive, answers an order identical to one submitted this recently with
+	// the earlier order and ErrDuplicate instead of storing it again.
+	DedupWindow time.Duration
+	// Stock, when set, reserves inventory for pending orders and settles it on status changes.
+	Stock Stock
+	// Normalizer tidies addresses on Submit; nil keeps them exactly as typed.
+	Normalizer AddressNormalizer
+	// Clock decides what "now" is for retention cutoffs, delivery generation and pause dates;
+	// nil uses the system clock.
+	Clock clock.Clock
+	// Logger reports retention sweeps and delivery generation; nil discards the messages.
+	Logger *log.Logger
+	// Events receives an event for every order created, paused, resumed, delivered or cancelled;
+	// nil publishes nothing.
+	Events *events.Bus
+	// Customers, when set, records the customer behind every new order and links the order to them.
+	Customers Customers
+	// Breaker fails requests fast once storage has failed Breaker.Threshold times in a row; the zero
+	// value never trips.
+	Breaker breaker.Options
+	// SlowOrder logs every Submit that takes longer, with where the time went; zero logs none.
+	SlowOrder time.Duration
+}
+
 // Service orchestrates the asynchronous handling of incoming orders.
 type Service struct {
 	repo          *Repository
+	catalog       Catalog
+	normalizer    AddressNormalizer
+	stock         Stock
+	clock         clock.Clock
+	dedupWindow   time.Duration
+	minOrderCents int
+	pricer        Pricer
+	currency      string
+	recent        map[string]recentOrder
+	duplicateDays DuplicateDayPolicy
+	retention     time.Duration
+	sweepEvery    time.Duration
+	archivePath   string
+	logger        *log.Logger
+	events        *events.Bus
+	customers     Customers
+	breaker       *breaker.Breaker
+	activity      metrics.Activity
+	latency       latency
+	generate      bool
+	horizon       int
 	commands      chan command
+	restores      chan command
 	queries       chan query
+	deliveryCalls chan deliveryQuery
+	pauses        chan pauseCommand
+	statusChanges chan statusCommand
+	statusBatches chan statusBatchCommand
 	cancellations chan struct{}
 }
 
 // NewService launches the coordinating goroutine immediately so requests never block the caller for scheduling.
-func NewService(repo *Repository) *Service {
+func NewService(repo *Repository, opts Options) *Service {
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	sweepEvery := opts.RetentionInterval
+	if sweepEvery <= 0 {
+		sweepEvery = defaultRetentionInterval
+	}
+	horizon := opts.DeliveryHorizon
+	if horizon <= 0 {
+		horizon = defaultDeliveryHorizon
+	}
+	normalizer := opts.Normalizer
+	if normalizer == nil {
+		normalizer = NoopNormalizer{}
+	}
 	svc := &Service{
 		repo:          repo,
+		catalog:       opts.Catalog,
+		normalizer:    normalizer,
+		stock:         opts.Stock,
+		clock:         clock.OrReal(opts.Clock),
+		dedupWindow:   opts.DedupWindow,
+		minOrderCents: opts.MinOrderCents,
+		pricer:        opts.Pricer,
+		currency:      opts.Currency,
+		recent:        make(map[string]recentOrder),
+		duplicateDays: opts.DuplicateDays,
+		retention:     opts.Retention,
+		sweepEvery:    sweepEvery,
+		archivePath:   opts.ArchivePath,
+		logger:        logger,
+		events:        opts.Events,
+		customers:     opts.Customers,
+		breaker:       breaker.New("order storage", opts.Breaker, opts.Clock, logger),
+		latency:       newLatency(opts.SlowOrder),
+		generate:      opts.GenerateDeliveries,
+		horizon:       horizon,
+		deliveryCalls: make(chan deliveryQuery),
+		pauses:        make(chan pauseCommand),
+		statusChanges: make(chan statusCommand),
+		statusBatches: make(chan statusBatchCommand),
 		commands:      make(chan command),
+		restores:      make(chan command),
 		queries:       make(chan query),
 		cancellations: make(chan struct{}),
 	}
@@ -70,108 +305,456 @@ func NewService(repo *Repository) *Service {
 
 // loop listens to commands and queries so the service honors the Go proverb "Don't communicate by sharing memory".
 func (s *Service) loop() {
+	// A nil channel never fires, so without a retention window the sweep case simply stays idle.
+	var sweep <-chan time.Time
+	if s.retention > 0 {
+		ticker := time.NewTicker(s.sweepEvery)
+		defer ticker.Stop()
+		sweep = ticker.C
+		s.purgeExpired()
+	}
+	var generation <-chan time.Time
+	if s.generate {
+		ticker := time.NewTicker(deliveryGenerationInterval)
+		defer ticker.Stop()
+		generation = ticker.C
+		s.generateDeliveries()
+	}
 	for {
 		select {
 		case cmd := <-s.commands:
-			if err := validateOrder(cmd.order); err != nil {
+			waited := time.Since(cmd.queued)
+			s.latency.wait.Observe(waited)
+			if err := s.breaker.Allow(); err != nil {
 				cmd.reply <- commandResult{err: err}
 				continue
 			}
-			stored, err := s.repo.Save(context.Background(), cmd.order)
-			cmd.reply <- commandResult{order: stored, err: err}
+			res, saved := s.submit(cmd)
+			s.recordOutcome(res.err)
+			s.observeSubmit(cmd, waited, saved, res)
+			cmd.reply <- res
+		case cmd := <-s.restores:
+			if err := s.breaker.Allow(); err != nil {
+				cmd.reply <- commandResult{err: err}
+				continue
+			}
+			res := s.restore(cmd)
+			s.recordOutcome(res.err)
+			cmd.reply <- res
 		case q := <-s.queries:
-			orders, err := s.repo.List(context.Background())
-			q.reply <- queryResult{orders: orders, err: err}
+			if err := s.breaker.Allow(); err != nil {
+				q.reply <- queryResult{err: err}
+				continue
+			}
+			res := s.runQuery(q)
+			s.recordOutcome(res.err)
+			q.reply <- res
+		case <-sweep:
+			s.purgeExpired()
+		case <-generation:
+			s.generateDeliveries()
+		case q := <-s.deliveryCalls:
+			if err := s.breaker.Allow(); err != nil {
+				q.reply <- deliveryResult{err: err}
+				continue
+			}
+			deliveries, err := s.activeDeliveries(q.ctx, q.from, q.to)
+			s.recordOutcome(err)
+			q.reply <- deliveryResult{deliveries: deliveries, err: err}
+		case cmd := <-s.pauses:
+			if err := s.breaker.Allow(); err != nil {
+				cmd.reply <- commandResult{err: err}
+				continue
+			}
+			updated, err := s.applyPause(cmd)
+			s.recordOutcome(err)
+			cmd.reply <- commandResult{order: updated, err: err}
+		case cmd := <-s.statusChanges:
+			if err := s.breaker.Allow(); err != nil {
+				cmd.reply <- commandResult{err: err}
+				continue
+			}
+			updated, err := s.applyStatus(cmd)
+			s.recordOutcome(err)
+			cmd.reply <- commandResult{order: updated, err: err}
+		case cmd := <-s.statusBatches:
+			if err := s.breaker.Allow(); err != nil {
+				cmd.reply <- failedStatuses(cmd.ids, err)
+				continue
+			}
+			results := s.applyStatuses(cmd)
+			s.recordOutcome(batchErr(results))
+			cmd.reply <- results
 		case <-s.cancellations:
 			return
 		}
 	}
 }
 
+// submit runs one order through the checks, reserves its stock and stores it. It also reports how
+// long the repository insert took, zero when the order never got that far.
+func (s *Service) submit(cmd command) (commandResult, time.Duration) {
+	normalized, err := normalizeSchedules(cmd.order, s.duplicateDays)
+	if err != nil {
+		return commandResult{err: err}, 0
+	}
+	cmd.order = normalized
+	if err := validateOrder(cmd.order, s.clock.Now()); err != nil {
+		return commandResult{err: err}, 0
+	}
+	if err := s.checkCatalog(cmd.ctx, cmd.order); err != nil {
+		return commandResult{err: err}, 0
+	}
+	if err := s.checkMinimum(cmd.ctx, cmd.order); err != nil {
+		return commandResult{err: err}, 0
+	}
+	if earlier, ok := s.findDuplicate(cmd.order); ok {
+		return commandResult{order: earlier, err: ErrDuplicate}, 0
+	}
+	cmd.order = s.normalizeAddress(cmd.ctx, cmd.order)
+	if cmd.order.Status == "" {
+		cmd.order.Status = StatusPending
+	}
+	reserved, err := s.reserveStock(cmd.ctx, cmd.order)
+	if err != nil {
+		return commandResult{err: err}, 0
+	}
+	reserved = s.linkCustomer(cmd.ctx, reserved)
+	saveStart := time.Now()
+	stored, err := s.repo.Save(cmd.ctx, reserved)
+	saved := time.Since(saveStart)
+	s.latency.save.Observe(saved)
+	if err != nil {
+		s.releaseStock(cmd.ctx, reserved)
+	} else {
+		s.rememberOrder(stored)
+		s.emit(cmd.ctx, stored.ID, EventCreated, "")
+	}
+	return commandResult{order: stored, err: err}, saved
+}
+
 // Submit registers a new order request and waits for the background goroutine to persist it.
 func (s *Service) Submit(ctx context.Context, order Order) (Order, error) {
-	reply := make(chan commandResult)
-	cmd := command{order: order, reply: reply}
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, order: order, queued: time.Now(), reply: reply}
 
 	select {
 	case s.commands <- cmd:
 	case <-ctx.Done():
 		return Order{}, ctx.Err()
 	case <-time.After(2 * time.Second):
-		return Order{}, errors.New("queue is busy processing other orders")
+		return Order{}, s.busy()
 	}
 
 	select {
 	case res := <-reply:
 		return res.order, res.err
 	case <-ctx.Done():
-		return Order{}, ctx.Err()
-	case <-time.After(2 * time.Second):
-		return Order{}, errors.New("order processing took too long")
+		return Order{}, waitErr(ctx)
 	}
 }
 
 // List returns the stored orders; useful for dashboards or tests.
 func (s *Service) List(ctx context.Context) ([]Order, error) {
-	reply := make(chan queryResult)
-	req := query{reply: reply}
+	return s.ListPage(ctx, Page{})
+}
+
+// ListPage returns one window of orders, newest first, filtered by status when the page names one.
+func (s *Service) ListPage(ctx context.Context, page Page) ([]Order, error) {
+	return s.list(ctx, query{ctx: ctx, page: page})
+}
+
+// ListBetween returns the orders created within [from, to], oldest first.
+func (s *Service) ListBetween(ctx context.Context, from, to time.Time) ([]Order, error) {
+	return s.list(ctx, query{ctx: ctx, from: from, to: to})
+}
+
+// ListPageCursor is ListPage one order at a time, for streaming long listings. The query is opened
+// on the service goroutine like every other read, but the rows are decoded on the caller's as it
+// ranges over the cursor, so a slow client never holds the service up. Range over it exactly once;
+// a cursor that is never ranged keeps its rows open until ctx is done.
+func (s *Service) ListPageCursor(ctx context.Context, page Page) (iter.Seq2[Order, error], error) {
+	res, err := s.ask(ctx, query{ctx: ctx, page: page, cursor: true})
+	return res.cursor, err
+}
+
+// ListBetweenCursor is ListBetween one order at a time, with the same rules as ListPageCursor.
+func (s *Service) ListBetweenCursor(ctx context.Context, from, to time.Time) (iter.Seq2[Order, error], error) {
+	res, err := s.ask(ctx, query{ctx: ctx, from: from, to: to, cursor: true})
+	return res.cursor, err
+}
+
+// runQuery answers a query on the loop: a page unless it names a time range, loaded or as a cursor.
+func (s *Service) runQuery(q query) queryResult {
+	var res queryResult
+	switch {
+	case q.cursor && q.to.IsZero():
+		res.cursor, res.err = s.repo.ListPageCursor(q.ctx, q.page)
+	case q.cursor:
+		res.cursor, res.err = s.repo.ListBetweenCursor(q.ctx, q.from, q.to)
+	case q.to.IsZero():
+		res.orders, res.err = s.repo.ListPage(q.ctx, q.page)
+	default:
+		res.orders, res.err = s.repo.ListBetween(q.ctx, q.from, q.to)
+	}
+	return res
+}
+
+// list hands a query to the loop and returns the loaded orders.
+func (s *Service) list(ctx context.Context, req query) ([]Order, error) {
+	res, err := s.ask(ctx, req)
+	return res.orders, err
+}
+
+// ask hands a query to the loop and waits for its answer.
+func (s *Service) ask(ctx context.Context, req query) (queryResult, error) {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan queryResult, 1)
+	req.reply = reply
 
 	select {
 	case s.queries <- req:
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return queryResult{}, ctx.Err()
 	case <-time.After(2 * time.Second):
-		return nil, errors.New("queue is busy processing other orders")
+		return queryResult{}, s.busy()
 	}
 
 	select {
 	case res := <-reply:
-		return res.orders, res.err
+		return res, res.err
+	case <-ctx.Done():
+		return queryResult{}, waitErr(ctx)
+	}
+}
+
+// Deliveries lists generated deliveries dated from..to inclusive, both YYYY-MM-DD.
+func (s *Service) Deliveries(ctx context.Context, from, to string) ([]Delivery, error) {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan deliveryResult, 1)
+	q := deliveryQuery{ctx: ctx, from: from, to: to, reply: reply}
+
+	select {
+	case s.deliveryCalls <- q:
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-time.After(2 * time.Second):
-		return nil, errors.New("listing orders took too long")
+		return nil, s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res.deliveries, res.err
+	case <-ctx.Done():
+		return nil, waitErr(ctx)
 	}
 }
 
+// Pause stops deliveries for an order until the given YYYY-MM-DD date; an empty date pauses until Resume.
+func (s *Service) Pause(ctx context.Context, id int64, until string) (Order, error) {
+	return s.sendPause(ctx, pauseCommand{ctx: ctx, id: id, paused: true, until: strings.TrimSpace(until)})
+}
+
+// Resume lifts a pause so deliveries are generated again from the next run on.
+func (s *Service) Resume(ctx context.Context, id int64) (Order, error) {
+	return s.sendPause(ctx, pauseCommand{ctx: ctx, id: id})
+}
+
+func (s *Service) sendPause(ctx context.Context, cmd pauseCommand) (Order, error) {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd.reply = reply
+
+	select {
+	case s.pauses <- cmd:
+	case <-ctx.Done():
+		return Order{}, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return Order{}, s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res.order, res.err
+	case <-ctx.Done():
+		return Order{}, waitErr(ctx)
+	}
+}
+
+// DeliveryHorizon reports how many days ahead deliveries are generated, so callers can pick a default range.
+func (s *Service) DeliveryHorizon() int {
+	return s.horizon
+}
+
 // Close stops the goroutine to allow graceful shutdown.
 func (s *Service) Close() {
 	close(s.cancellations)
 }
 
+// NormalizeDay lowercases and trims a weekday so "Monday " and "monday" count as the same delivery.
+func NormalizeDay(day string) string {
+	return strings.ToLower(strings.TrimSpace(day))
+}
+
+// normalizeSchedules canonicalizes day names and applies the duplicate-day policy before validation,
+// so stored orders never double-count a day in production summaries.
+func normalizeSchedules(order Order, policy DuplicateDayPolicy) (Order, error) {
+	var errs validationErrors
+
+	seenDays := make(map[string]bool, len(order.BreadSchedule.Days))
+	days := make([]string, 0, len(order.BreadSchedule.Days))
+	for i, day := range order.BreadSchedule.Days {
+		day = NormalizeDay(day)
+		if day != "" && seenDays[day] {
+			if policy != DuplicateDaysMerge {
+				errs = append(errs, newValidationError(fmt.Sprintf("breadSchedule.days[%d]", i), CodeDuplicate, "bread delivery day is listed twice"))
+			}
+			continue
+		}
+		seenDays[day] = true
+		days = append(days, day)
+	}
+
+	type slotKey struct{ day, item string }
+	seenSlots := make(map[slotKey]int, len(order.CroissantSchedule))
+	slots := make([]CroissantSchedule, 0, len(order.CroissantSchedule))
+	for i, slot := range order.CroissantSchedule {
+		slot.Day = NormalizeDay(slot.Day)
+		key := slotKey{day: slot.Day, item: strings.ToLower(strings.TrimSpace(slot.Item))}
+		if at, ok := seenSlots[key]; ok && slot.Day != "" {
+			if policy == DuplicateDaysMerge {
+				slots[at].Quantity += slot.Quantity
+			} else {
+				errs = append(errs, newValidationError(fmt.Sprintf("croissantSchedule[%d].day", i), CodeDuplicate, "croissant day is listed twice"))
+			}
+			continue
+		}
+		seenSlots[key] = len(slots)
+		slots = append(slots, slot)
+	}
+
+	if len(errs) > 0 {
+		return Order{}, errs
+	}
+	order.BreadSchedule.Days = days
+	order.CroissantSchedule = slots
+	return order, nil
+}
+
+// checkCatalog names every ordered item the catalog does not know, when strict item checks are
+// enabled. Custom items are not on the menu by definition, so they pass.
+func (s *Service) checkCatalog(ctx context.Context, order Order) error {
+	if s.catalog == nil {
+		return nil
+	}
+	var errs validationErrors
+	for i, item := range order.Items {
+		if item.Custom {
+			continue
+		}
+		known, err := s.catalog.HasItem(ctx, item.Name)
+		if err != nil {
+			return fmt.Errorf("check catalog for %q: %w", item.Name, err)
+		}
+		if !known {
+			errs = append(errs, newValidationError(fmt.Sprintf("items[%d].name", i), CodeUnknownItem, fmt.Sprintf("%q is not on the menu", item.Name)))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
 // validateOrder keeps the business rules near the service so multiple HTTP endpoints can reuse them.
-func validateOrder(order Order) error {
+// Field names follow the JSON payload so the storefront can map errors straight onto its inputs,
+// and every failure is collected rather than stopping at the first one. now is the service clock's
+// reading, which a pause end date has to lie after.
+func validateOrder(order Order, now time.Time) error {
+	var errs validationErrors
 	if strings.TrimSpace(order.CustomerName) == "" {
-		return newValidationError("name is required")
+		errs = append(errs, newValidationError("name", CodeRequired, "name is required"))
 	}
 	if strings.TrimSpace(order.Address) == "" {
-		return newValidationError("address is required")
+		errs = append(errs, newValidationError("address", CodeRequired, "address is required"))
 	}
 	if strings.TrimSpace(order.Phone) == "" {
-		return newValidationError("phone is required")
+		errs = append(errs, newValidationError("phone", CodeRequired, "phone is required"))
+	}
+	if email := strings.TrimSpace(order.Email); email != "" && !validEmail(email) {
+		errs = append(errs, newValidationError("email", CodeBadEmail, "email looks invalid"))
 	}
 	if len(order.Items) == 0 {
-		return newValidationError("at least one item is required")
+		errs = append(errs, newValidationError("items", CodeRequired, "at least one item is required"))
+	}
+	for i, item := range order.Items {
+		if strings.TrimSpace(item.Name) == "" {
+			errs = append(errs, newValidationError(fmt.Sprintf("items[%d].name", i), CodeRequired, "item name is required"))
+		}
+		if item.Quantity <= 0 {
+			errs = append(errs, newValidationError(fmt.Sprintf("items[%d].quantity", i), CodeNotPositive, "item quantity must be positive"))
+		}
+		if len(item.Options) > MaxItemOptions {
+			errs = append(errs, newValidationError(fmt.Sprintf("items[%d].options", i), CodeTooMany, "too many item options"))
+		}
+		if item.Custom {
+			field := fmt.Sprintf("items[%d].description", i)
+			switch description := strings.TrimSpace(item.Description); {
+			case description == "":
+				errs = append(errs, newValidationError(field, CodeRequired, "describe the custom item"))
+			case utf8.RuneCountInString(description) > MaxCustomDescription:
+				errs = append(errs, newValidationError(field, CodeTooLong, "custom item description is too long"))
+			}
+		}
+		for j, option := range item.Options {
+			field := fmt.Sprintf("items[%d].options[%d]", i, j)
+			switch {
+			case strings.TrimSpace(option) == "":
+				errs = append(errs, newValidationError(field, CodeRequired, "item option must not be blank"))
+			case utf8.RuneCountInString(option) > MaxItemOptionLength:
+				errs = append(errs, newValidationError(field, CodeTooLong, "item option is too long"))
+			}
+		}
 	}
 	if len(order.BreadSchedule.Days) == 0 {
-		return newValidationError("select at least one bread delivery day")
+		errs = append(errs, newValidationError("breadSchedule.days", CodeRequired, "select at least one bread delivery day"))
 	}
 	if order.BreadSchedule.Frequency == "" {
-		return newValidationError("select a bread delivery frequency")
+		errs = append(errs, newValidationError("breadSchedule.frequency", CodeRequired, "select a bread delivery frequency"))
 	}
 	if strings.TrimSpace(order.BreadSchedule.StartDate) == "" {
-		return newValidationError("select a bread start date")
+		errs = append(errs, newValidationError("breadSchedule.startDate", CodeRequired, "select a bread start date"))
+	}
+	if until := strings.TrimSpace(order.BreadSchedule.PausedUntil); until != "" {
+		// The same rule as applyPause: a pause that already ended is almost always a typo in the year.
+		switch parsed, err := time.Parse(dateLayout, until); {
+		case err != nil:
+			errs = append(errs, newValidationError("breadSchedule.pausedUntil", CodeBadDate, "dates must look like 2006-01-02"))
+		case !parsed.After(dateOnly(now.UTC())):
+			errs = append(errs, newValidationError("breadSchedule.pausedUntil", CodeNotFuture, "pause end date must be in the future"))
+		}
+	}
+	if !knownStatus(order.Status) {
+		errs = append(errs, newValidationError("status", CodeUnknownStatus, "unknown order status"))
 	}
 	if len(order.CroissantSchedule) == 0 {
-		return newValidationError("select croissant days")
+		errs = append(errs, newValidationError("croissantSchedule", CodeRequired, "select croissant days"))
 	}
-	for _, slot := range order.CroissantSchedule {
-		if slot.Day == "" {
-			return newValidationError("croissant day is required")
+	for i, slot := range order.CroissantSchedule {
+		if strings.TrimSpace(slot.Day) == "" {
+			errs = append(errs, newValidationError(fmt.Sprintf("croissantSchedule[%d].day", i), CodeRequired, "croissant day is required"))
 		}
 		if slot.Quantity <= 0 {
-			return newValidationError("croissant quantity must be positive")
+			errs = append(errs, newValidationError(fmt.Sprintf("croissantSchedule[%d].quantity", i), CodeNotPositive, "croissant quantity must be positive"))
 		}
 	}
-	return nil
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
 }
@@ -0,0 +1,96 @@
+package order
+
+import (
+	"context"
+	"testing"
+
+	"bakery/pkg/clock"
+	"bakery/pkg/storage/memorydriver"
+)
+
+// validOrder passes validateOrder, so a test can break one field at a time.
+func validOrder() Order {
+	return Order{
+		CustomerName:      "Ivan",
+		Address:           "Lenina 1",
+		Phone:             "+79990000000",
+		Items:             []OrderItem{{Name: "Хлеб", Quantity: 1}},
+		BreadSchedule:     BreadSchedule{Days: []string{"monday"}, Frequency: "everyday", StartDate: "2026-10-19"},
+		CroissantSchedule: []CroissantSchedule{{Day: "monday", Quantity: 2}},
+	}
+}
+
+func TestValidateOrderPausedUntil(t *testing.T) {
+	tests := []struct {
+		name     string
+		until    string
+		wantCode string
+	}{
+		{"no pause end", "", ""},
+		{"tomorrow", "2026-10-16", ""},
+		{"today", "2026-10-15", CodeNotFuture},
+		{"last year", "2025-10-16", CodeNotFuture},
+		{"not a date", "16.10.2026", CodeBadDate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			order.BreadSchedule.Paused = tt.until != ""
+			order.BreadSchedule.PausedUntil = tt.until
+			err := validateOrder(order, testNow)
+			if got := ValidationCode(err); got != tt.wantCode {
+				t.Fatalf("code = %q, want %q (err: %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != "" && ValidationField(err) != "breadSchedule.pausedUntil" {
+				t.Errorf("field = %q, want breadSchedule.pausedUntil", ValidationField(err))
+			}
+		})
+	}
+}
+
+func TestDeliveriesFollowOrderStatus(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewManual(testNow)
+	repo := NewRepository(memorydriver.OpenTest(t, clk), clk)
+	save := func(name, status string) int64 {
+		t.Helper()
+		order := validOrder()
+		order.CustomerName, order.Status = name, status
+		order.BreadSchedule = BreadSchedule{Days: []string{"thursday", "friday", "saturday"}, Frequency: "everyday", StartDate: "2026-10-15"}
+		order.CroissantSchedule = []CroissantSchedule{}
+		saved, err := repo.Save(ctx, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return saved.ID
+	}
+	kept := save("Ivan", StatusPending)
+	cancelled := save("Olga", StatusPending)
+	finished := save("Petr", StatusDelivered)
+
+	// Generation runs once when the loop starts, before any call is served.
+	svc := NewService(repo, Options{Clock: clk, GenerateDeliveries: true, DeliveryHorizon: 3})
+	defer svc.Close()
+	perOrder := func() map[int64]int {
+		t.Helper()
+		deliveries, err := svc.Deliveries(ctx, "2026-10-15", "2026-10-17")
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts := make(map[int64]int)
+		for _, delivery := range deliveries {
+			counts[delivery.OrderID]++
+		}
+		return counts
+	}
+
+	if got := perOrder(); got[kept] != 3 || got[cancelled] != 3 || got[finished] != 0 {
+		t.Fatalf("before cancelling: deliveries per order %v, want 3 for %d and %d and none for the delivered %d", got, kept, cancelled, finished)
+	}
+	if _, err := svc.SetStatus(ctx, cancelled, StatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	if got := perOrder(); got[kept] != 3 || got[cancelled] != 0 {
+		t.Errorf("after cancelling %d: deliveries per order %v, want 3 for %d only", cancelled, got, kept)
+	}
+}
@@ -0,0 +1,236 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Order statuses. Stored orders without a status predate them and read as pending.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+)
+
+// Stock holds inventory for pending orders so the storefront never sells units already promised;
+// the inventory service satisfies it. Reserve returns the held units per batch id, or names the
+// item that ran short and holds nothing.
+type Stock interface {
+	Reserve(ctx context.Context, wanted map[string]int) (held map[int64]int, short string, err error)
+	Release(ctx context.Context, held map[int64]int) error
+	Deduct(ctx context.Context, held map[int64]int) error
+}
+
+// statusCommand moves one order to a new status.
+type statusCommand struct {
+	ctx    context.Context
+	id     int64
+	status string
+	reply  chan commandResult
+}
+
+// statusBatchCommand moves several orders to the same status in one loop turn.
+type statusBatchCommand struct {
+	ctx    context.Context
+	ids    []int64
+	status string
+	reply  chan []StatusResult
+}
+
+// StatusResult is the outcome of one order in a batch status change: the updated order, or the
+// error SetStatus would have returned for it alone.
+type StatusResult struct {
+	ID    int64
+	Order Order
+	Err   error
+}
+
+// checkTargetStatus rejects anything but the two statuses a pending order can move to.
+func checkTargetStatus(status string) error {
+	if status != StatusDelivered && status != StatusCancelled {
+		return newValidationError("status", CodeUnknownStatus, "status must be delivered or cancelled")
+	}
+	return nil
+}
+
+// knownStatus reports whether the status is one the service understands; empty means pending.
+func knownStatus(status string) bool {
+	switch status {
+	case "", StatusPending, StatusDelivered, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// ParseStatus reads a status filter in any case or spacing. "new" is accepted for pending, since
+// that is what the admins processing the queue call an order nobody has handled yet.
+func ParseStatus(status string) (string, bool) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status == "new" {
+		return StatusPending, true
+	}
+	if status == "" || !knownStatus(status) {
+		return "", false
+	}
+	return status, true
+}
+
+// SetStatus marks a pending order delivered, which turns its reservation into a deduction,
+// or cancelled, which releases the reservation. Finished orders cannot change again.
+func (s *Service) SetStatus(ctx context.Context, id int64, status string) (Order, error) {
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan commandResult, 1)
+	cmd := statusCommand{ctx: ctx, id: id, status: strings.ToLower(strings.TrimSpace(status)), reply: reply}
+
+	select {
+	case s.statusChanges <- cmd:
+	case <-ctx.Done():
+		return Order{}, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return Order{}, s.busy()
+	}
+
+	select {
+	case res := <-reply:
+		return res.order, res.err
+	case <-ctx.Done():
+		return Order{}, waitErr(ctx)
+	}
+}
+
+// SetStatuses applies one status change to many orders, such as marking a whole morning round
+// delivered. The batch runs in a single turn of the service loop, so no other write interleaves
+// with it, and each order is checked exactly like SetStatus checks it: a missing order or a finished
+// one fails on its own line of the result without stopping the rest. An unknown target status
+// fails the whole call, since it would fail every order alike.
+func (s *Service) SetStatuses(ctx context.Context, ids []int64, status string) ([]StatusResult, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if err := checkTargetStatus(status); err != nil {
+		return nil, err
+	}
+	s.activity.Enter()
+	defer s.activity.Leave()
+	reply := make(chan []StatusResult, 1)
+	cmd := statusBatchCommand{ctx: ctx, ids: ids, status: status, reply: reply}
+
+	select {
+	case s.statusBatches <- cmd:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return nil, s.busy()
+	}
+
+	select {
+	case results := <-reply:
+		return results, nil
+	case <-ctx.Done():
+		return nil, waitErr(ctx)
+	}
+}
+
+// applyStatuses runs applyStatus for every id in the order given.
+func (s *Service) applyStatuses(cmd statusBatchCommand) []StatusResult {
+	results := make([]StatusResult, 0, len(cmd.ids))
+	for _, id := range cmd.ids {
+		updated, err := s.applyStatus(statusCommand{ctx: cmd.ctx, id: id, status: cmd.status})
+		results = append(results, StatusResult{ID: id, Order: updated, Err: err})
+	}
+	return results
+}
+
+// failedStatuses answers a batch whose every order failed the same way, such as an open breaker.
+func failedStatuses(ids []int64, err error) []StatusResult {
+	results := make([]StatusResult, 0, len(ids))
+	for _, id := range ids {
+		results = append(results, StatusResult{ID: id, Err: err})
+	}
+	return results
+}
+
+// reserveStock holds inventory for a pending order about to be saved. Items without a tracked
+// batch are not reserved, so freeform orders keep working, and custom items are baked to order, so
+// they never claim a batch that happens to share their name.
+func (s *Service) reserveStock(ctx context.Context, order Order) (Order, error) {
+	if s.stock == nil || order.Status != StatusPending {
+		return order, nil
+	}
+	wanted := make(map[string]int, len(order.Items))
+	for _, item := range order.Items {
+		if item.Custom {
+			continue
+		}
+		wanted[strings.TrimSpace(item.Name)] += item.Quantity
+	}
+	held, short, err := s.stock.Reserve(ctx, wanted)
+	if err != nil {
+		return order, err
+	}
+	if short != "" {
+		field := "items"
+		for i, item := range order.Items {
+			if strings.EqualFold(strings.TrimSpace(item.Name), short) {
+				field = fmt.Sprintf("items[%d].name", i)
+				break
+			}
+		}
+		return order, newValidationError(field, CodeOutOfStock, "not enough stock for this item")
+	}
+	if len(held) > 0 {
+		order.Reservations = held
+	}
+	return order, nil
+}
+
+// releaseStock gives back a reservation whose order could not be saved.
+func (s *Service) releaseStock(ctx context.Context, order Order) {
+	if s.stock == nil || len(order.Reservations) == 0 {
+		return
+	}
+	if err := s.stock.Release(ctx, order.Reservations); err != nil {
+		s.logger.Printf("releasing stock for an unsaved order failed: %v", err)
+	}
+}
+
+// applyStatus settles the reservation first so a failed inventory write leaves the order pending
+// and the change can simply be retried.
+func (s *Service) applyStatus(cmd statusCommand) (Order, error) {
+	if err := checkTargetStatus(cmd.status); err != nil {
+		return Order{}, err
+	}
+	order, err := s.repo.Get(cmd.ctx, cmd.id)
+	if err != nil {
+		return Order{}, err
+	}
+	if order.Status != StatusPending {
+		return Order{}, newValidationError("status", CodeFinished, "only pending orders can change status")
+	}
+	if s.stock != nil && len(order.Reservations) > 0 {
+		settle := s.stock.Release
+		if cmd.status == StatusDelivered {
+			settle = s.stock.Deduct
+		}
+		if err := settle(cmd.ctx, order.Reservations); err != nil {
+			return Order{}, err
+		}
+	}
+	if err := s.repo.UpdateStatus(cmd.ctx, order.ID, cmd.status); err != nil {
+		return Order{}, err
+	}
+	order.Status = cmd.status
+	s.emit(cmd.ctx, order.ID, cmd.status, "")
+	return order, nil
+}
+
+// batchErr returns the first storage failure in a status batch, or nil when storage answered every order.
+func batchErr(results []StatusResult) error {
+	for _, r := range results {
+		if storageFailure(r.Err) {
+			return r.Err
+		}
+	}
+	return nil
+}
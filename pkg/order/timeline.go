@@ -0,0 +1,184 @@
+package order
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"bakery/pkg/events"
+)
+
+// EventTopic is the events.Event topic the order service publishes under.
+const EventTopic = "order"
+
+// Order event types, in the order an order usually meets them.
+const (
+	EventCreated   = "created"
+	EventPaused    = "paused"
+	EventResumed   = "resumed"
+	EventDelivered = StatusDelivered
+	EventCancelled = StatusCancelled
+)
+
+// ErrTimelineOff is returned when no timeline was wired in.
+var ErrTimelineOff = errors.New("order timeline is not enabled")
+
+// emit publishes an order event tagged with the actor on ctx. The service never waits for the
+// timeline to store it, only for the bus to queue it.
+func (s *Service) emit(ctx context.Context, id int64, eventType, detail string) {
+	s.events.Publish(events.Event{
+		At:        s.clock.Now().UTC(),
+		Topic:     EventTopic,
+		SubjectID: id,
+		Type:      eventType,
+		Actor:     events.Actor(ctx),
+		Detail:    detail,
+	})
+}
+
+// TimelineOptions tunes the timeline; the zero value keeps events in memory only.
+type TimelineOptions struct {
+	// Path, when set, receives every event as a JSON line and is read back on start, so timelines
+	// survive restarts.
+	Path string
+	// Logger reports failed writes; nil discards them.
+	Logger *log.Logger
+}
+
+// timelineQuery asks the timeline goroutine for one order's events.
+type timelineQuery struct {
+	id    int64
+	reply chan []events.Event
+}
+
+// Timeline records order events from the bus, keyed by order id, so admins can see how an order
+// got where it is. It owns its goroutine like the services do, and recording never slows the
+// order service down because the bus sits in between.
+type Timeline struct {
+	path    string
+	logger  *log.Logger
+	byOrder map[int64][]events.Event
+	source  <-chan events.Event
+	queries chan timelineQuery
+	done    chan struct{}
+}
+
+// NewTimeline subscribes to the order topic and loads the events already stored at opts.Path.
+func NewTimeline(bus *events.Bus, opts TimelineOptions) (*Timeline, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	t := &Timeline{
+		path:    opts.Path,
+		logger:  logger,
+		byOrder: make(map[int64][]events.Event),
+		queries: make(chan timelineQuery),
+		done:    make(chan struct{}),
+	}
+	if t.path != "" {
+		if err := t.load(); err != nil {
+			return nil, fmt.Errorf("unable to read order events from %s: %w", t.path, err)
+		}
+	}
+	t.source = bus.Subscribe(EventTopic)
+	go t.loop()
+	return t, nil
+}
+
+// loop records events until the bus closes the subscription.
+func (t *Timeline) loop() {
+	defer close(t.done)
+	for {
+		select {
+		case event, ok := <-t.source:
+			if !ok {
+				return
+			}
+			t.byOrder[event.SubjectID] = append(t.byOrder[event.SubjectID], event)
+			if t.path != "" {
+				if err := appendEvent(t.path, event); err != nil {
+					t.logger.Printf("order timeline: writing %s event for order %d failed: %v", event.Type, event.SubjectID, err)
+				}
+			}
+		case q := <-t.queries:
+			// The caller gets its own copy so later appends never show through.
+			q.reply <- append([]events.Event(nil), t.byOrder[q.id]...)
+		}
+	}
+}
+
+// Events returns an order's events, oldest first. Events are recorded shortly after the change
+// that caused them, so a read right after a change may not include it yet.
+func (t *Timeline) Events(ctx context.Context, id int64) ([]events.Event, error) {
+	if t == nil {
+		return nil, ErrTimelineOff
+	}
+	reply := make(chan []events.Event, 1)
+
+	select {
+	case t.queries <- timelineQuery{id: id, reply: reply}:
+	case <-t.done:
+		return nil, ErrTimelineOff
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return nil, errors.New("order timeline is busy")
+	}
+
+	select {
+	case list := <-reply:
+		return list, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Wait blocks until the bus has closed and the last events are written, so shutdown keeps them.
+func (t *Timeline) Wait() {
+	<-t.done
+}
+
+// load reads the stored events; a missing file just means nothing happened yet.
+func (t *Timeline) load() error {
+	file, err := os.Open(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event events.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return err
+		}
+		t.byOrder[event.SubjectID] = append(t.byOrder[event.SubjectID], event)
+	}
+	return scanner.Err()
+}
+
+// appendEvent adds one JSON line to the event file.
+func appendEvent(path string, event events.Event) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(event); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
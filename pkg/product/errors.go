@@ -0,0 +1,6 @@
+package product
+
+import "errors"
+
+// ErrNotFound is returned when a product is missing so HTTP handlers can respond with 404.
+var ErrNotFound = errors.New("product not found")
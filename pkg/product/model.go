@@ -0,0 +1,14 @@
+package product
+
+import "time"
+
+// Product describes a catalog entry that stays on the menu even when no batch is currently in stock.
+type Product struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Category       string    `json:"category"`
+	BasePriceCents int       `json:"base_price_cents"`
+	Image          string    `json:"image"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,102 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+
+	"bakery/pkg/clock"
+)
+
+// Repository persists catalog entries through database/sql so storage backends stay swappable.
+type Repository struct {
+	db    *sql.DB
+	clock clock.Clock
+}
+
+// NewRepository wires the handle so the service goroutine can own all catalog access.
+// clk stamps CreatedAt on saved records; nil uses the system clock.
+func NewRepository(db *sql.DB, clk clock.Clock) *Repository {
+	return &Repository{db: db, clock: clock.OrReal(clk)}
+}
+
+// Save inserts a new catalog entry and returns it with the generated identifier.
+func (r *Repository) Save(ctx context.Context, p Product) (Product, error) {
+	query := "INSERT INTO product (name, description, category, base_price_cents, image) VALUES (?, ?, ?, ?, ?)"
+	result, err := r.db.ExecContext(ctx, query, p.Name, p.Description, p.Category, p.BasePriceCents, p.Image)
+	if err != nil {
+		return Product{}, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return Product{}, err
+	}
+	p.ID = id
+	p.CreatedAt = r.clock.Now().UTC()
+	return p, nil
+}
+
+// List fetches the whole catalog; it is small enough that the menu can overlay stock in memory.
+func (r *Repository) List(ctx context.Context) ([]Product, error) {
+	query := "SELECT id, name, description, category, base_price_cents, image FROM product ORDER BY id"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []Product
+	for rows.Next() {
+		// Optional catalog fields may be NULL on SQL backends; they read as empty strings.
+		var (
+			p                                  Product
+			name, description, category, image sql.NullString
+			basePrice                          sql.NullInt64
+		)
+		if err := rows.Scan(&p.ID, &name, &description, &category, &basePrice, &image); err != nil {
+			return nil, err
+		}
+		p.Name = name.String
+		p.Description = description.String
+		p.Category = category.String
+		p.BasePriceCents = int(basePrice.Int64)
+		p.Image = image.String
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// Update replaces the descriptive fields and base price of an existing entry.
+func (r *Repository) Update(ctx context.Context, p Product) error {
+	query := "UPDATE product SET name = ?, description = ?, category = ?, base_price_cents = ?, image = ? WHERE id = ?"
+	result, err := r.db.ExecContext(ctx, query, p.Name, p.Description, p.Category, p.BasePriceCents, p.Image, p.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a catalog entry; batches that referenced it fall back to being listed on their own.
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM product WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
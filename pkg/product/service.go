@@ -0,0 +1,172 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// command defines a mutation so the goroutine can serialize writes through a channel.
+// ctx travels with the command so a cancelled admin request also aborts its catalog query,
+// and the buffered reply lets the loop move on even when nobody is waiting anymore.
+type command struct {
+	ctx     context.Context
+	action  string
+	product Product
+	id      int64
+	reply   chan commandResult
+}
+
+// listQuery enables consumers to fetch the catalog without touching shared memory.
+type listQuery struct {
+	ctx   context.Context
+	reply chan queryResult
+}
+
+// commandResult forwards either the persisted product or an error back to the caller.
+type commandResult struct {
+	product Product
+	err     error
+}
+
+// queryResult returns the full catalog for rendering.
+type queryResult struct {
+	products []Product
+	err      error
+}
+
+// Service owns a goroutine so catalog edits are serialized without mutexes, like the inventory service.
+type Service struct {
+	repo      *Repository
+	commands  chan command
+	listCalls chan listQuery
+	quit      chan struct{}
+}
+
+// NewService starts the background goroutine immediately so HTTP handlers only see non-blocking calls.
+func NewService(repo *Repository) *Service {
+	svc := &Service{
+		repo:      repo,
+		commands:  make(chan command),
+		listCalls: make(chan listQuery),
+		quit:      make(chan struct{}),
+	}
+	go svc.loop()
+	return svc
+}
+
+// loop processes commands and queries sequentially so no mutexes are needed.
+func (s *Service) loop() {
+	for {
+		select {
+		case cmd := <-s.commands:
+			switch cmd.action {
+			case "save":
+				stored, err := s.repo.Save(cmd.ctx, cmd.product)
+				cmd.reply <- commandResult{product: stored, err: err}
+			case "update":
+				err := s.repo.Update(cmd.ctx, cmd.product)
+				cmd.reply <- commandResult{err: err}
+			case "delete":
+				err := s.repo.Delete(cmd.ctx, cmd.id)
+				cmd.reply <- commandResult{err: err}
+			default:
+				cmd.reply <- commandResult{err: errors.New("unknown product action")}
+			}
+		case q := <-s.listCalls:
+			products, err := s.repo.List(q.ctx)
+			q.reply <- queryResult{products: products, err: err}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// Add registers a catalog entry and returns the stored record with its generated identifier.
+func (s *Service) Add(ctx context.Context, p Product) (Product, error) {
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, action: "save", product: p, reply: reply}
+
+	select {
+	case s.commands <- cmd:
+	case <-ctx.Done():
+		return Product{}, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return Product{}, errors.New("product queue is busy")
+	}
+
+	select {
+	case res := <-reply:
+		return res.product, res.err
+	case <-ctx.Done():
+		return Product{}, ctx.Err()
+	}
+}
+
+// Update replaces a catalog entry when the admin edits its description or base price.
+func (s *Service) Update(ctx context.Context, p Product) error {
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, action: "update", product: p, reply: reply}
+
+	select {
+	case s.commands <- cmd:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(2 * time.Second):
+		return errors.New("product queue is busy")
+	}
+
+	select {
+	case res := <-reply:
+		return res.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Delete removes the catalog entry when the bakery stops offering it.
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	reply := make(chan commandResult, 1)
+	cmd := command{ctx: ctx, action: "delete", id: id, reply: reply}
+
+	select {
+	case s.commands <- cmd:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(2 * time.Second):
+		return errors.New("product queue is busy")
+	}
+
+	select {
+	case res := <-reply:
+		return res.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// List returns the catalog so the menu can overlay current stock on top of it.
+func (s *Service) List(ctx context.Context) ([]Product, error) {
+	reply := make(chan queryResult, 1)
+	q := listQuery{ctx: ctx, reply: reply}
+
+	select {
+	case s.listCalls <- q:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return nil, errors.New("product queue is busy")
+	}
+
+	select {
+	case res := <-reply:
+		return res.products, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops the background goroutine when the application shuts down.
+func (s *Service) Close() {
+	close(s.quit)
+}
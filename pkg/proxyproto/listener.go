@@ -0,0 +1,166 @@
+// Package proxyproto reads the PROXY protocol header that TCP load balancers prepend to each
+// connection, so handlers see the real client address instead of the balancer's.
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// v2Signature opens every binary (version 2) header.
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// maxV1Length is the longest text header the specification allows, CRLF included.
+const maxV1Length = 107
+
+// ErrMissingHeader is returned for connections that do not start with a PROXY header. Once the
+// protocol is enabled every peer must send one; accepting bare connections would let clients that
+// skip the balancer blend in with proxied traffic.
+var ErrMissingHeader = errors.New("proxy protocol header missing")
+
+// Listener wraps accepted connections so their RemoteAddr reports the client named in the header.
+type Listener struct {
+	net.Listener
+	// HeaderTimeout bounds how long a connection may take to send its header.
+	HeaderTimeout time.Duration
+}
+
+// NewListener enables PROXY protocol parsing on every connection accepted from inner.
+func NewListener(inner net.Listener, headerTimeout time.Duration) *Listener {
+	return &Listener{Listener: inner, HeaderTimeout: headerTimeout}
+}
+
+// Accept hands back the connection without reading from it; the header is parsed lazily on the
+// connection's own goroutine so one slow peer cannot stall the accept loop.
+func (l *Listener) Accept() (net.Conn, error) {
+	inner, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{Conn: inner, reader: bufio.NewReader(inner), timeout: l.HeaderTimeout}, nil
+}
+
+// Conn reports the proxied client address and strips the header from the byte stream.
+type Conn struct {
+	net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+	once    sync.Once
+	remote  net.Addr
+	err     error
+}
+
+// Read returns the payload after the header, or the header error for malformed connections.
+func (c *Conn) Read(p []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(p)
+}
+
+// RemoteAddr prefers the address from the header and falls back to the socket peer for LOCAL
+// health checks and UNKNOWN families.
+func (c *Conn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// readHeader consumes the header under its own deadline and clears the deadline afterwards so the
+// HTTP server's timeouts take over for the rest of the connection.
+func (c *Conn) readHeader() {
+	if c.timeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		defer c.Conn.SetReadDeadline(time.Time{})
+	}
+	prefix, err := c.reader.Peek(len(v2Signature))
+	switch {
+	case err == nil && bytes.Equal(prefix, v2Signature):
+		c.remote, c.err = readV2(c.reader)
+	case len(prefix) >= 6 && string(prefix[:6]) == "PROXY ":
+		c.remote, c.err = readV1(c.reader)
+	case err != nil && len(prefix) == 0:
+		c.err = err
+	default:
+		c.err = ErrMissingHeader
+	}
+}
+
+// readV1 parses the text form: "PROXY TCP4 <src> <dst> <sport> <dport>\r\n".
+func readV1(reader *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < maxV1Length {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("proxy protocol v1: %w", err)
+		}
+		line = append(line, b)
+		if bytes.HasSuffix(line, []byte("\r\n")) {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("proxy protocol v1: header too long")
+	}
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("proxy protocol v1: malformed header %q", line)
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.Atoi(fields[4])
+	if ip == nil || err != nil || port < 0 || port > 65535 {
+		return nil, fmt.Errorf("proxy protocol v1: malformed source %s:%s", fields[2], fields[4])
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// readV2 parses the binary form: signature, version/command, family, length, then addresses.
+func readV2(reader *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("proxy protocol v2: %w", err)
+	}
+	if header[12]>>4 != 2 {
+		return nil, fmt.Errorf("proxy protocol v2: unsupported version %d", header[12]>>4)
+	}
+	command := header[12] & 0x0f
+	family := header[13] >> 4
+	body := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, fmt.Errorf("proxy protocol v2: %w", err)
+	}
+	if command == 0 {
+		// LOCAL connections come from the balancer itself, typically health checks.
+		return nil, nil
+	}
+	switch family {
+	case 1:
+		if len(body) < 12 {
+			return nil, errors.New("proxy protocol v2: short IPv4 address block")
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:4]), Port: int(binary.BigEndian.Uint16(body[8:10]))}, nil
+	case 2:
+		if len(body) < 36 {
+			return nil, errors.New("proxy protocol v2: short IPv6 address block")
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:16]), Port: int(binary.BigEndian.Uint16(body[32:34]))}, nil
+	default:
+		// UNSPEC and UNIX families carry no client IP worth reporting.
+		return nil, nil
+	}
+}
@@ -4,16 +4,19 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
+
+	"bakery/pkg/clock"
 )
 
 // orderRecord keeps the raw persisted representation for the lightweight driver.
@@ -26,26 +29,77 @@ type orderRecord struct {
 	BreadJSON     string    `json:"bread_schedule"`
 	CroissantJSON string    `json:"croissant_schedule"`
 	Comment       string    `json:"comment"`
+	Lat           *float64  `json:"lat,omitempty"`
+	Lng           *float64  `json:"lng,omitempty"`
+	Status        string    `json:"status,omitempty"`
+	Reservations  string    `json:"reservations,omitempty"`
 	CreatedAt     time.Time `json:"created_at"`
+	Email         string    `json:"email,omitempty"`
+	CustomerID    int64     `json:"customer_id,omitempty"`
 }
 
 // inventoryRecord tracks available batches so the admin panel can read and mutate them.
 type inventoryRecord struct {
 	ID             int64     `json:"id"`
+	ProductID      int64     `json:"product_id"`
 	Name           string    `json:"name"`
 	Category       string    `json:"category"`
 	AvailableCount int       `json:"available_count"`
+	ReservedCount  int       `json:"reserved_count,omitempty"`
 	PriceCents     int       `json:"price_cents"`
+	Image          string    `json:"image,omitempty"`
+	Featured       bool      `json:"featured,omitempty"`
 	BakedAt        time.Time `json:"baked_at"`
 	CreatedAt      time.Time `json:"created_at"`
 }
 
+// productRecord keeps catalog entries separate from the per-batch inventory rows.
+type productRecord struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Category       string    `json:"category"`
+	BasePriceCents int       `json:"base_price_cents"`
+	Image          string    `json:"image"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// customerRecord is a returning household keyed by its normalized phone.
+type customerRecord struct {
+	ID          int64     `json:"id"`
+	Phone       string    `json:"phone"`
+	Name        string    `json:"name"`
+	Address     string    `json:"address"`
+	Email       string    `json:"email,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastOrderAt time.Time `json:"last_order_at"`
+}
+
+// deliveryRecord is one concrete drop generated from an order's recurring schedule.
+type deliveryRecord struct {
+	ID        int64     `json:"id"`
+	OrderID   int64     `json:"order_id"`
+	Date      string    `json:"delivery_date"`
+	Kind      string    `json:"kind"`
+	Item      string    `json:"item"`
+	Quantity  int       `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // snapshot is written to disk after each mutation so the driver survives restarts.
+// Version records the layout, see snapshotVersion; readSnapshot migrates older ones.
 type snapshot struct {
+	Version          int               `json:"version"`
 	Orders           []orderRecord     `json:"orders"`
 	Inventory        []inventoryRecord `json:"inventory"`
+	Products         []productRecord   `json:"products"`
+	Deliveries       []deliveryRecord  `json:"deliveries"`
+	Customers        []customerRecord  `json:"customers,omitempty"`
 	OrderCounter     int64             `json:"order_counter"`
 	InventoryCounter int64             `json:"inventory_counter"`
+	ProductCounter   int64             `json:"product_counter"`
+	DeliveryCounter  int64             `json:"delivery_counter"`
+	CustomerCounter  int64             `json:"customer_counter,omitempty"`
 }
 
 // storeCommand models every operation executed against the in-memory store.
@@ -53,48 +107,106 @@ type storeCommand struct {
 	action    string
 	order     orderRecord
 	inventory inventoryRecord
+	product   productRecord
+	delivery  deliveryRecord
+	customer  customerRecord
 	id        int64
-	reply     chan storeResult
+	cutoff    time.Time
+	// until closes the created_at BETWEEN range that cutoff opens.
+	until    time.Time
+	from, to string
+	// page narrows listOrders to a status and a LIMIT/OFFSET window.
+	page orderPage
+	// multiplier and delta describe a bulk price change: round(price * multiplier) + delta.
+	multiplier float64
+	delta      int
+	reply      chan storeResult
+}
+
+// categoryGroup is one row of the inventory GROUP BY category aggregate.
+type categoryGroup struct {
+	category string
+	count    int64
 }
 
 // storeResult transfers either the new identifier, a record list, or an error.
 type storeResult struct {
-	id        int64
-	orders    []orderRecord
-	inventory []inventoryRecord
-	err       error
+	id         int64
+	affected   int64
+	scalar     int64
+	groups     []categoryGroup
+	orders     []orderRecord
+	inventory  []inventoryRecord
+	products   []productRecord
+	deliveries []deliveryRecord
+	customers  []customerRecord
+	snapshot   *snapshot
+	err        error
 }
 
 // store keeps the sequence of orders guarded by a dedicated goroutine.
 type store struct {
-	commands         chan storeCommand
-	closed           chan struct{}
-	persistRequests  chan snapshot
+	commands chan storeCommand
+	closed   chan struct{}
+	// dirty holds at most one pending "write a snapshot" signal, and flushes carries synchronous
+	// write requests; both are served by persistenceLoop, the only goroutine writing the file.
+	dirty            chan struct{}
+	flushes          chan chan error
 	orders           []orderRecord
 	inventory        []inventoryRecord
+	products         []productRecord
+	deliveries       []deliveryRecord
+	customers        []customerRecord
 	orderCounter     int64
 	inventoryCounter int64
+	productCounter   int64
+	deliveryCounter  int64
+	customerCounter  int64
 	snapshotPath     string
+	compress         bool
+	clock            clock.Clock
+	maxBytes         int64
+	logger           *log.Logger
 }
 
 // newStore creates a store and spins the goroutines so every access flows through a channel.
-func newStore(path string) (*store, error) {
-	loaded, err := readSnapshot(path)
+// loadPath differs from path only when an uncompressed snapshot is being carried over into a gzip one.
+func newStore(path, loadPath string, compress bool, opts Options) (*store, error) {
+	maxBytes := opts.MaxSnapshotBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxSnapshotBytes
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	loaded, err := readSnapshot(loadPath, maxBytes)
 	if err != nil {
 		return nil, err
 	}
 	s := &store{
 		// A small buffer keeps bootstrap operations from blocking before the store goroutine spins up.
-		commands:        make(chan storeCommand, 32),
-		closed:          make(chan struct{}),
-		persistRequests: make(chan snapshot, 1),
-		snapshotPath:    path,
+		commands:     make(chan storeCommand, 32),
+		closed:       make(chan struct{}),
+		dirty:        make(chan struct{}, 1),
+		flushes:      make(chan chan error),
+		snapshotPath: path,
+		compress:     compress,
+		clock:        clock.OrReal(opts.Clock),
+		maxBytes:     maxBytes,
+		logger:       logger,
 	}
 	if loaded != nil {
 		s.orders = loaded.Orders
 		s.inventory = loaded.Inventory
+		s.products = loaded.Products
+		s.deliveries = loaded.Deliveries
+		s.customers = loaded.Customers
 		s.orderCounter = loaded.OrderCounter
 		s.inventoryCounter = loaded.InventoryCounter
+		s.productCounter = loaded.ProductCounter
+		s.deliveryCounter = loaded.DeliveryCounter
+		s.customerCounter = loaded.CustomerCounter
 	}
 	go s.loop()
 	go s.persistenceLoop()
@@ -110,22 +222,94 @@ func (s *store) loop() {
 			case "insertOrder":
 				id := atomic.AddInt64(&s.orderCounter, 1)
 				cmd.order.ID = id
-				cmd.order.CreatedAt = time.Now().UTC()
+				if cmd.order.CreatedAt.IsZero() {
+					cmd.order.CreatedAt = s.clock.Now().UTC()
+				}
 				s.orders = append(s.orders, cmd.order)
 				s.queuePersist()
 				cmd.reply <- storeResult{id: id}
 			case "listOrders":
-				cloned := cloneOrders(s.orders)
-				cmd.reply <- storeResult{orders: cloned}
+				cmd.reply <- storeResult{orders: s.listOrders(cmd.page)}
+			case "findOrder":
+				cmd.reply <- storeResult{orders: s.findOrder(cmd.id)}
+			case "updateOrderBreadSchedule":
+				updated := false
+				for i := range s.orders {
+					if s.orders[i].ID == cmd.order.ID {
+						s.orders[i].BreadJSON = cmd.order.BreadJSON
+						updated = true
+						break
+					}
+				}
+				if !updated {
+					// Zero rows affected lets the repository decide how to report the missing order.
+					cmd.reply <- storeResult{}
+					continue
+				}
+				s.queuePersist()
+				cmd.reply <- storeResult{affected: 1}
+			case "updateOrderStatus":
+				updated := false
+				for i := range s.orders {
+					if s.orders[i].ID == cmd.order.ID {
+						s.orders[i].Status = cmd.order.Status
+						updated = true
+						break
+					}
+				}
+				if !updated {
+					cmd.reply <- storeResult{}
+					continue
+				}
+				s.queuePersist()
+				cmd.reply <- storeResult{affected: 1}
+			case "listOrdersBefore":
+				var matched []orderRecord
+				for _, record := range s.orders {
+					if record.CreatedAt.Before(cmd.cutoff) {
+						matched = append(matched, record)
+					}
+				}
+				cmd.reply <- storeResult{orders: cloneOrders(matched)}
+			case "listOrdersBetween":
+				var matched []orderRecord
+				for _, record := range s.orders {
+					if !record.CreatedAt.Before(cmd.cutoff) && !record.CreatedAt.After(cmd.until) {
+						matched = append(matched, record)
+					}
+				}
+				cmd.reply <- storeResult{orders: cloneOrders(matched)}
+			case "deleteOrdersBefore":
+				// Kept rows are copied into a fresh slice so the persisted snapshot never aliases the old backing array.
+				kept := make([]orderRecord, 0, len(s.orders))
+				for _, record := range s.orders {
+					if !record.CreatedAt.Before(cmd.cutoff) {
+						kept = append(kept, record)
+					}
+				}
+				removed := int64(len(s.orders) - len(kept))
+				if removed > 0 {
+					s.orders = kept
+					s.queuePersist()
+				}
+				cmd.reply <- storeResult{affected: removed}
 			case "insertInventory":
 				id := atomic.AddInt64(&s.inventoryCounter, 1)
 				cmd.inventory.ID = id
-				cmd.inventory.CreatedAt = time.Now().UTC()
+				cmd.inventory.CreatedAt = s.clock.Now().UTC()
 				s.inventory = append(s.inventory, cmd.inventory)
 				s.queuePersist()
 				cmd.reply <- storeResult{id: id}
 			case "listInventory":
 				cmd.reply <- storeResult{inventory: cloneInventory(s.inventory)}
+			case "findInventory":
+				var matched []inventoryRecord
+				for _, record := range s.inventory {
+					if record.Name == cmd.inventory.Name && record.Category == cmd.inventory.Category && record.BakedAt.Equal(cmd.inventory.BakedAt) {
+						matched = append(matched, record)
+					}
+				}
+				cmd.reply <- storeResult{inventory: cloneInventory(matched)}
 			case "updateInventory":
 				updated := false
 				for i := range s.inventory {
@@ -144,11 +328,83 @@ func (s *store) loop() {
 					}
 				}
 				if !updated {
-					cmd.reply <- storeResult{err: errors.New("inventory item not found")}
+					// Zero rows affected lets the repository report ErrNotFound, which the API turns into a 404.
+					cmd.reply <- storeResult{}
 					continue
 				}
 				s.queuePersist()
-				cmd.reply <- storeResult{}
+				cmd.reply <- storeResult{affected: 1}
+			case "setInventoryStock":
+				updated := false
+				for i := range s.inventory {
+					if s.inventory[i].ID == cmd.inventory.ID {
+						s.inventory[i].AvailableCount = cmd.inventory.AvailableCount
+						s.inventory[i].ReservedCount = cmd.inventory.ReservedCount
+						updated = true
+						break
+					}
+				}
+				if !updated {
+					cmd.reply <- storeResult{}
+					continue
+				}
+				s.queuePersist()
+				cmd.reply <- storeResult{affected: 1}
+			case "adjustInventoryPrices":
+				// Every matching row is checked before any is written so a refused adjustment leaves prices untouched.
+				var matched []int
+				negative := false
+				for i := range s.inventory {
+					if s.inventory[i].Category != cmd.inventory.Category {
+						continue
+					}
+					if adjustPrice(s.inventory[i].PriceCents, cmd.multiplier, cmd.delta) < 0 {
+						negative = true
+						break
+					}
+					matched = append(matched, i)
+				}
+				if negative {
+					cmd.reply <- storeResult{err: errors.New("price adjustment would make a price negative")}
+					continue
+				}
+				for _, i := range matched {
+					s.inventory[i].PriceCents = adjustPrice(s.inventory[i].PriceCents, cmd.multiplier, cmd.delta)
+				}
+				if len(matched) > 0 {
+					s.queuePersist()
+				}
+				cmd.reply <- storeResult{affected: int64(len(matched))}
+			case "setInventoryImage":
+				updated := false
+				for i := range s.inventory {
+					if s.inventory[i].ID == cmd.inventory.ID {
+						s.inventory[i].Image = cmd.inventory.Image
+						updated = true
+						break
+					}
+				}
+				if !updated {
+					cmd.reply <- storeResult{}
+					continue
+				}
+				s.queuePersist()
+				cmd.reply <- storeResult{affected: 1}
+			case "setInventoryFeatured":
+				updated := false
+				for i := range s.inventory {
+					if s.inventory[i].ID == cmd.inventory.ID {
+						s.inventory[i].Featured = cmd.inventory.Featured
+						updated = true
+						break
+					}
+				}
+				if !updated {
+					cmd.reply <- storeResult{}
+					continue
+				}
+				s.queuePersist()
+				cmd.reply <- storeResult{affected: 1}
 			case "deleteInventory":
 				removed := false
 				for i := range s.inventory {
@@ -159,11 +415,127 @@ func (s *store) loop() {
 					}
 				}
 				if !removed {
-					cmd.reply <- storeResult{err: errors.New("inventory item not found")}
+					cmd.reply <- storeResult{}
 					continue
 				}
 				s.queuePersist()
-				cmd.reply <- storeResult{}
+				cmd.reply <- storeResult{affected: 1}
+			case "insertProduct":
+				id := atomic.AddInt64(&s.productCounter, 1)
+				cmd.product.ID = id
+				cmd.product.CreatedAt = s.clock.Now().UTC()
+				s.products = append(s.products, cmd.product)
+				s.queuePersist()
+				cmd.reply <- storeResult{id: id}
+			case "listProducts":
+				cmd.reply <- storeResult{products: cloneProducts(s.products)}
+			case "updateProduct":
+				updated := false
+				for i := range s.products {
+					if s.products[i].ID == cmd.product.ID {
+						cmd.product.CreatedAt = s.products[i].CreatedAt
+						s.products[i] = cmd.product
+						updated = true
+						break
+					}
+				}
+				if !updated {
+					cmd.reply <- storeResult{}
+					continue
+				}
+				s.queuePersist()
+				cmd.reply <- storeResult{id: cmd.product.ID}
+			case "deleteProduct":
+				removed := false
+				for i := range s.products {
+					if s.products[i].ID == cmd.id {
+						s.products = append(s.products[:i], s.products[i+1:]...)
+						removed = true
+						break
+					}
+				}
+				if !removed {
+					cmd.reply <- storeResult{}
+					continue
+				}
+				s.queuePersist()
+				cmd.reply <- storeResult{id: cmd.id}
+			case "insertDelivery":
+				id := atomic.AddInt64(&s.deliveryCounter, 1)
+				cmd.delivery.ID = id
+				cmd.delivery.CreatedAt = s.clock.Now().UTC()
+				s.deliveries = append(s.deliveries, cmd.delivery)
+				s.queuePersist()
+				cmd.reply <- storeResult{id: id}
+			case "listDeliveries":
+				// Dates are ISO strings, so comparing them as text orders them by day.
+				var matched []deliveryRecord
+				for _, record := range s.deliveries {
+					if record.Date >= cmd.from && record.Date <= cmd.to {
+						matched = append(matched, record)
+					}
+				}
+				cmd.reply <- storeResult{deliveries: matched}
+			case "insertCustomer":
+				id := atomic.AddInt64(&s.customerCounter, 1)
+				cmd.customer.ID = id
+				s.customers = append(s.customers, cmd.customer)
+				s.queuePersist()
+				cmd.reply <- storeResult{id: id}
+			case "listCustomers":
+				cmd.reply <- storeResult{customers: cloneCustomers(s.customers)}
+			case "findCustomer":
+				var matched []customerRecord
+				for _, record := range s.customers {
+					if record.Phone == cmd.customer.Phone {
+						matched = append(matched, record)
+					}
+				}
+				cmd.reply <- storeResult{customers: matched}
+			case "updateCustomer":
+				updated := false
+				for i := range s.customers {
+					if s.customers[i].ID == cmd.customer.ID {
+						s.customers[i].Name = cmd.customer.Name
+						s.customers[i].Address = cmd.customer.Address
+						s.customers[i].Email = cmd.customer.Email
+						s.customers[i].LastOrderAt = cmd.customer.LastOrderAt
+						updated = true
+						break
+					}
+				}
+				if !updated {
+					cmd.reply <- storeResult{}
+					continue
+				}
+				s.queuePersist()
+				cmd.reply <- storeResult{affected: 1}
+			case "countOrders":
+				cmd.reply <- storeResult{scalar: int64(len(s.orders))}
+			case "countInventory":
+				cmd.reply <- storeResult{scalar: int64(len(s.inventory))}
+			case "countProducts":
+				cmd.reply <- storeResult{scalar: int64(len(s.products))}
+			case "groupInventoryCategories":
+				counts := make(map[string]int64)
+				for _, record := range s.inventory {
+					counts[record.Category]++
+				}
+				groups := make([]categoryGroup, 0, len(counts))
+				for category, count := range counts {
+					groups = append(groups, categoryGroup{category: category, count: count})
+				}
+				sort.Slice(groups, func(i, j int) bool { return groups[i].category < groups[j].category })
+				cmd.reply <- storeResult{groups: groups}
+			case "sumInventoryValue":
+				var total int64
+				for _, record := range s.inventory {
+					total += int64(record.PriceCents) * int64(record.AvailableCount)
+				}
+				cmd.reply <- storeResult{scalar: total}
+			case "snapshot":
+				snap := s.snapshot()
+				cmd.reply <- storeResult{snapshot: &snap}
 			case "noop":
 				cmd.reply <- storeResult{}
 			default:
@@ -175,37 +547,86 @@ func (s *store) loop() {
 	}
 }
 
-// persistenceLoop writes snapshots asynchronously so the main loop stays responsive.
+// persistenceLoop writes snapshots asynchronously so the main loop stays responsive. It pulls the
+// snapshot from the store when it is ready to write rather than being handed one per mutation, so a
+// burst of inserts costs one copy of the tables per write instead of one per insert.
 func (s *store) persistenceLoop() {
 	for {
 		select {
-		case snap := <-s.persistRequests:
-			if s.snapshotPath == "" {
-				continue
+		case <-s.dirty:
+			if err := s.persist(); err != nil {
+				s.logger.Printf("memory driver: snapshot not written to %s: %v", s.snapshotPath, err)
+			}
+		case done := <-s.flushes:
+			// The flush writes everything the pending signal would have, so the signal is spent.
+			select {
+			case <-s.dirty:
+			default:
 			}
-			_ = writeSnapshot(s.snapshotPath, snap)
+			done <- s.persist()
 		case <-s.closed:
 			return
 		}
 	}
 }
 
-// queuePersist sends the current snapshot to the background writer without blocking.
+// persist asks the store goroutine for the current snapshot and writes it.
+func (s *store) persist() error {
+	reply := make(chan storeResult, 1)
+	select {
+	case s.commands <- storeCommand{action: "snapshot", reply: reply}:
+	case <-s.closed:
+		return errors.New("store is closed")
+	}
+	var res storeResult
+	select {
+	case res = <-reply:
+	case <-s.closed:
+		return errors.New("store is closed")
+	}
+	return writeSnapshot(s.snapshotPath, *res.snapshot, s.compress, s.maxBytes)
+}
+
+// queuePersist marks the tables as changed so the background writer saves them soon. It never
+// blocks: a signal already pending covers this change too.
 func (s *store) queuePersist() {
 	if s.snapshotPath == "" {
 		return
 	}
-	snap := snapshot{
+	select {
+	case s.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// flush writes the current tables now and waits for the write, for shutdown.
+func (s *store) flush() error {
+	if s.snapshotPath == "" {
+		return nil
+	}
+	done := make(chan error, 1)
+	select {
+	case s.flushes <- done:
+	case <-s.closed:
+		return errors.New("store is closed")
+	}
+	return <-done
+}
+
+// snapshot copies the tables for writing; it runs on the store goroutine, which owns them.
+func (s *store) snapshot() snapshot {
+	return snapshot{
+		Version:          snapshotVersion,
 		Orders:           cloneOrders(s.orders),
 		Inventory:        cloneInventory(s.inventory),
+		Products:         cloneProducts(s.products),
+		Deliveries:       cloneDeliveries(s.deliveries),
+		Customers:        cloneCustomers(s.customers),
 		OrderCounter:     atomic.LoadInt64(&s.orderCounter),
 		InventoryCounter: atomic.LoadInt64(&s.inventoryCounter),
-	}
-	select {
-	case s.persistRequests <- snap:
-	default:
-		<-s.persistRequests
-		s.persistRequests <- snap
+		ProductCounter:   atomic.LoadInt64(&s.productCounter),
+		DeliveryCounter:  atomic.LoadInt64(&s.deliveryCounter),
+		CustomerCounter:  atomic.LoadInt64(&s.customerCounter),
 	}
 }
 
@@ -219,6 +640,12 @@ type Driver struct {
 	store *store
 }
 
+// Flush writes the snapshot now and returns once it is on disk, instead of leaving it to the
+// background writer. Writes that land while it runs go to the next snapshot as usual.
+func (d *Driver) Flush() error {
+	return d.store.flush()
+}
+
 // Open creates a connection that forwards calls to the shared store.
 func (d *Driver) Open(name string) (driver.Conn, error) {
 	if d.store == nil {
@@ -233,21 +660,99 @@ type conn struct {
 }
 
 // Prepare builds a statement object for the small set of supported queries.
+// Aggregates are matched as whole statements rather than parsed, and each answers with one scalar row:
+//
+//	SELECT COUNT(*) FROM orders | inventory | product
+//	SELECT SUM(price_cents * available_count) FROM inventory
+//	SELECT category, COUNT(*) FROM inventory GROUP BY category ORDER BY category
+//
+// The bulk reprice UPDATE inventory SET price_cents = ROUND(price_cents * ?) + ? WHERE category = ?
+// is matched the same way.
+//
+// Listing queries honor their ORDER BY clause, column by column with ASC or DESC, through sortRows.
 func (c *conn) Prepare(query string) (driver.Stmt, error) {
 	trimmed := strings.TrimSpace(strings.ToLower(query))
+	orderBy, err := parseOrderBy(trimmed)
+	if err != nil {
+		return nil, err
+	}
 	switch {
+	case strings.HasPrefix(trimmed, "select count(*)"):
+		// Counting is answered from the slice length, so the WHERE-less form is the only one accepted.
+		switch {
+		case strings.HasSuffix(trimmed, "from orders"):
+			return &stmt{store: c.store, query: "countOrders"}, nil
+		case strings.HasSuffix(trimmed, "from inventory"):
+			return &stmt{store: c.store, query: "countInventory"}, nil
+		case strings.HasSuffix(trimmed, "from product"):
+			return &stmt{store: c.store, query: "countProducts"}, nil
+		}
+		return nil, fmt.Errorf("unsupported query: %s", query)
+	case trimmed == "select category, count(*) from inventory group by category order by category":
+		return &stmt{store: c.store, query: "groupInventoryCategories"}, nil
+	case trimmed == "select sum(price_cents * available_count) from inventory":
+		return &stmt{store: c.store, query: "sumInventoryValue"}, nil
 	case strings.HasPrefix(trimmed, "insert into orders"):
 		return &stmt{store: c.store, query: "insertOrder"}, nil
+	case strings.HasPrefix(trimmed, "select") && strings.Contains(trimmed, "from orders") && strings.Contains(trimmed, "created_at between ? and ?"):
+		return &stmt{store: c.store, query: "listOrdersBetween", orderBy: orderBy}, nil
+	case strings.HasPrefix(trimmed, "select") && strings.Contains(trimmed, "from orders") && strings.Contains(trimmed, "created_at <"):
+		return &stmt{store: c.store, query: "listOrdersBefore", orderBy: orderBy}, nil
+	case strings.HasPrefix(trimmed, "select") && strings.HasSuffix(trimmed, "from orders where id = ?"):
+		return &stmt{store: c.store, query: "findOrder"}, nil
 	case strings.HasPrefix(trimmed, "select") && strings.Contains(trimmed, "from orders"):
-		return &stmt{store: c.store, query: "listOrders"}, nil
+		return &stmt{
+			store:          c.store,
+			query:          "listOrders",
+			orderBy:        orderBy,
+			statusFilter:   strings.Contains(trimmed, " where status = ?"),
+			customerFilter: strings.Contains(trimmed, "customer_id = ?"),
+			paged:          strings.Contains(trimmed, " limit ? offset ?"),
+		}, nil
+	case strings.HasPrefix(trimmed, "update orders set bread_schedule"):
+		return &stmt{store: c.store, query: "updateOrderBreadSchedule"}, nil
+	case strings.HasPrefix(trimmed, "update orders set status"):
+		return &stmt{store: c.store, query: "updateOrderStatus"}, nil
+	case strings.HasPrefix(trimmed, "delete from orders") && strings.Contains(trimmed, "created_at <"):
+		return &stmt{store: c.store, query: "deleteOrdersBefore"}, nil
 	case strings.HasPrefix(trimmed, "insert into inventory"):
 		return &stmt{store: c.store, query: "insertInventory"}, nil
+	case strings.HasPrefix(trimmed, "select") && strings.Contains(trimmed, "from inventory where name = ? and category = ? and baked_at = ?"):
+		return &stmt{store: c.store, query: "findInventory"}, nil
 	case strings.HasPrefix(trimmed, "select") && strings.Contains(trimmed, "from inventory"):
-		return &stmt{store: c.store, query: "listInventory"}, nil
+		return &stmt{store: c.store, query: "listInventory", orderBy: orderBy}, nil
+	case strings.HasPrefix(trimmed, "update inventory set price_cents = round(price_cents * ?) + ? where category = ?"):
+		return &stmt{store: c.store, query: "adjustInventoryPrices"}, nil
+	case strings.HasPrefix(trimmed, "update inventory set featured = ?"):
+		return &stmt{store: c.store, query: "setInventoryFeatured"}, nil
+	case strings.HasPrefix(trimmed, "update inventory set image = ?"):
+		return &stmt{store: c.store, query: "setInventoryImage"}, nil
+	case strings.HasPrefix(trimmed, "update inventory set available_count = ?, reserved_count = ?"):
+		return &stmt{store: c.store, query: "setInventoryStock"}, nil
 	case strings.HasPrefix(trimmed, "update inventory"):
 		return &stmt{store: c.store, query: "updateInventory"}, nil
 	case strings.HasPrefix(trimmed, "delete from inventory"):
 		return &stmt{store: c.store, query: "deleteInventory"}, nil
+	case strings.HasPrefix(trimmed, "insert into product"):
+		return &stmt{store: c.store, query: "insertProduct"}, nil
+	case strings.HasPrefix(trimmed, "select") && strings.Contains(trimmed, "from product"):
+		return &stmt{store: c.store, query: "listProducts", orderBy: orderBy}, nil
+	case strings.HasPrefix(trimmed, "update product"):
+		return &stmt{store: c.store, query: "updateProduct"}, nil
+	case strings.HasPrefix(trimmed, "delete from product"):
+		return &stmt{store: c.store, query: "deleteProduct"}, nil
+	case strings.HasPrefix(trimmed, "insert into deliveries"):
+		return &stmt{store: c.store, query: "insertDelivery"}, nil
+	case strings.HasPrefix(trimmed, "select") && strings.Contains(trimmed, "from deliveries"):
+		return &stmt{store: c.store, query: "listDeliveries", orderBy: orderBy}, nil
+	case strings.HasPrefix(trimmed, "insert into customers"):
+		return &stmt{store: c.store, query: "insertCustomer"}, nil
+	case strings.HasPrefix(trimmed, "select") && strings.Contains(trimmed, "from customers where phone = ?"):
+		return &stmt{store: c.store, query: "findCustomer"}, nil
+	case strings.HasPrefix(trimmed, "select") && strings.Contains(trimmed, "from customers"):
+		return &stmt{store: c.store, query: "listCustomers", orderBy: orderBy}, nil
+	case strings.HasPrefix(trimmed, "update customers"):
+		return &stmt{store: c.store, query: "updateCustomer"}, nil
 	case strings.HasPrefix(trimmed, "create table"):
 		return &stmt{store: c.store, query: "noop"}, nil
 	default:
@@ -267,6 +772,13 @@ func (c *conn) Begin() (driver.Tx, error) {
 type stmt struct {
 	store *store
 	query string
+	// orderBy is the parsed ORDER BY clause of listing queries; Query sorts the rows by it.
+	orderBy []sortKey
+	// statusFilter, customerFilter and paged mark an order listing with WHERE status = ?,
+	// customer_id = ? and LIMIT ? OFFSET ?, whose values arrive as arguments in that order.
+	statusFilter   bool
+	customerFilter bool
+	paged          bool
 }
 
 // Close is a no-op since statements do not maintain resources in this simple driver.
@@ -286,8 +798,8 @@ func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
 
 	switch s.query {
 	case "insertOrder":
-		if len(args) < 7 {
-			return nil, fmt.Errorf("expected 7 arguments, got %d", len(args))
+		if len(args) < 11 {
+			return nil, fmt.Errorf("expected 11 arguments, got %d", len(args))
 		}
 		cmd.order = orderRecord{
 			Name:          toString(args[0]),
@@ -297,6 +809,23 @@ func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
 			BreadJSON:     toString(args[4]),
 			CroissantJSON: toString(args[5]),
 			Comment:       toString(args[6]),
+			Lat:           toFloatPtr(args[7]),
+			Lng:           toFloatPtr(args[8]),
+			Status:        toString(args[9]),
+			Reservations:  toString(args[10]),
+		}
+		// Older callers insert without created_at; the store stamps those rows itself.
+		if len(args) > 11 && args[11] != nil {
+			created, err := toTime(args[11])
+			if err != nil {
+				return nil, err
+			}
+			cmd.order.CreatedAt = created.UTC()
+		}
+		// The customer columns came later still and are optional in the same way.
+		if len(args) > 13 {
+			cmd.order.Email = toString(args[12])
+			cmd.order.CustomerID = toInt64(args[13])
 		}
 	case "insertInventory":
 		if len(args) < 5 {
@@ -313,6 +842,16 @@ func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
 			PriceCents:     toInt(args[3]),
 			BakedAt:        baked,
 		}
+		if len(args) > 5 {
+			// The product link is optional so callers predating the catalog keep working.
+			cmd.inventory.ProductID = toInt64(args[5])
+		}
+		// So are the reservation, photo and featured columns, which only a restore sets on insert.
+		if len(args) > 8 {
+			cmd.inventory.ReservedCount = toInt(args[6])
+			cmd.inventory.Image = toString(args[7])
+			cmd.inventory.Featured = toBool(args[8])
+		}
 	case "updateInventory":
 		if len(args) < 4 {
 			return nil, fmt.Errorf("expected 4 arguments, got %d", len(args))
@@ -327,11 +866,122 @@ func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
 			BakedAt:        baked,
 			ID:             toInt64(args[3]),
 		}
-	case "deleteInventory":
+	case "setInventoryStock":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("expected 3 arguments, got %d", len(args))
+		}
+		cmd.inventory = inventoryRecord{
+			AvailableCount: toInt(args[0]),
+			ReservedCount:  toInt(args[1]),
+			ID:             toInt64(args[2]),
+		}
+	case "adjustInventoryPrices":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("expected 3 arguments, got %d", len(args))
+		}
+		cmd.multiplier = toFloat(args[0])
+		cmd.delta = toInt(args[1])
+		cmd.inventory = inventoryRecord{Category: toString(args[2])}
+	case "setInventoryFeatured":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+		}
+		cmd.inventory = inventoryRecord{Featured: toBool(args[0]), ID: toInt64(args[1])}
+	case "setInventoryImage":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+		}
+		cmd.inventory = inventoryRecord{Image: toString(args[0]), ID: toInt64(args[1])}
+	case "updateOrderStatus":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+		}
+		cmd.order = orderRecord{Status: toString(args[0]), ID: toInt64(args[1])}
+	case "deleteInventory", "deleteProduct":
 		if len(args) < 1 {
 			return nil, errors.New("expected id for delete")
 		}
 		cmd.id = toInt64(args[0])
+	case "updateOrderBreadSchedule":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+		}
+		cmd.order = orderRecord{BreadJSON: toString(args[0]), ID: toInt64(args[1])}
+	case "insertDelivery":
+		if len(args) < 5 {
+			return nil, fmt.Errorf("expected 5 arguments, got %d", len(args))
+		}
+		cmd.delivery = deliveryRecord{
+			OrderID:  toInt64(args[0]),
+			Date:     toString(args[1]),
+			Kind:     toString(args[2]),
+			Item:     toString(args[3]),
+			Quantity: toInt(args[4]),
+		}
+	case "deleteOrdersBefore":
+		cutoff, err := cutoffArg(args)
+		if err != nil {
+			return nil, err
+		}
+		cmd.cutoff = cutoff
+	case "insertProduct":
+		if len(args) < 5 {
+			return nil, fmt.Errorf("expected 5 arguments, got %d", len(args))
+		}
+		cmd.product = productRecord{
+			Name:           toString(args[0]),
+			Description:    toString(args[1]),
+			Category:       toString(args[2]),
+			BasePriceCents: toInt(args[3]),
+			Image:          toString(args[4]),
+		}
+	case "updateProduct":
+		if len(args) < 6 {
+			return nil, fmt.Errorf("expected 6 arguments, got %d", len(args))
+		}
+		cmd.product = productRecord{
+			Name:           toString(args[0]),
+			Description:    toString(args[1]),
+			Category:       toString(args[2]),
+			BasePriceCents: toInt(args[3]),
+			Image:          toString(args[4]),
+			ID:             toInt64(args[5]),
+		}
+	case "insertCustomer":
+		if len(args) < 6 {
+			return nil, fmt.Errorf("expected 6 arguments, got %d", len(args))
+		}
+		created, err := toTime(args[4])
+		if err != nil {
+			return nil, err
+		}
+		lastOrder, err := toTime(args[5])
+		if err != nil {
+			return nil, err
+		}
+		cmd.customer = customerRecord{
+			Phone:       toString(args[0]),
+			Name:        toString(args[1]),
+			Address:     toString(args[2]),
+			Email:       toString(args[3]),
+			CreatedAt:   created,
+			LastOrderAt: lastOrder,
+		}
+	case "updateCustomer":
+		if len(args) < 5 {
+			return nil, fmt.Errorf("expected 5 arguments, got %d", len(args))
+		}
+		lastOrder, err := toTime(args[3])
+		if err != nil {
+			return nil, err
+		}
+		cmd.customer = customerRecord{
+			Name:        toString(args[0]),
+			Address:     toString(args[1]),
+			Email:       toString(args[2]),
+			LastOrderAt: lastOrder,
+			ID:          toInt64(args[4]),
+		}
 	default:
 		return nil, fmt.Errorf("unsupported exec action %s", s.query)
 	}
@@ -344,7 +994,7 @@ func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
 	if res.err != nil {
 		return nil, res.err
 	}
-	return execResult{id: res.id}, nil
+	return execResult{id: res.id, affected: res.affected}, nil
 }
 
 // enqueue sends the command to the store while honoring a timeout to avoid blocking forever.
@@ -361,6 +1011,57 @@ func (s *stmt) enqueue(cmd storeCommand) error {
 func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
 	reply := make(chan storeResult)
 	cmd := storeCommand{action: s.query, reply: reply}
+	switch s.query {
+	case "listOrdersBefore":
+		cutoff, err := cutoffArg(args)
+		if err != nil {
+			return nil, err
+		}
+		cmd.cutoff = cutoff
+	case "findInventory":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("expected 3 arguments, got %d", len(args))
+		}
+		baked, err := toTime(args[2])
+		if err != nil {
+			return nil, err
+		}
+		cmd.inventory = inventoryRecord{Name: toString(args[0]), Category: toString(args[1]), BakedAt: baked}
+	case "listOrdersBetween":
+		if len(args) < 2 {
+			return nil, errors.New("expected created_at range")
+		}
+		from, err := toTime(args[0])
+		if err != nil {
+			return nil, err
+		}
+		until, err := toTime(args[1])
+		if err != nil {
+			return nil, err
+		}
+		cmd.cutoff, cmd.until = from, until
+	case "listDeliveries":
+		if len(args) < 2 {
+			return nil, errors.New("expected date range for deliveries")
+		}
+		cmd.from, cmd.to = toString(args[0]), toString(args[1])
+	case "findOrder":
+		if len(args) < 1 {
+			return nil, errors.New("expected order id")
+		}
+		cmd.id = toInt64(args[0])
+	case "findCustomer":
+		if len(args) < 1 {
+			return nil, errors.New("expected phone")
+		}
+		cmd.customer = customerRecord{Phone: toString(args[0])}
+	case "listOrders":
+		page, err := s.orderPage(args)
+		if err != nil {
+			return nil, err
+		}
+		cmd.page = page
+	}
 
 	if err := s.enqueue(cmd); err != nil {
 		return nil, err
@@ -372,21 +1073,70 @@ func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
 	}
 	switch s.query {
 	case "listOrders":
+		if cmd.page.paged && !cmd.page.window {
+			// The store could not window rows it does not sort, so the page is cut after sorting.
+			sorted := &rows{kind: "orders", orders: res.orders}
+			if err := sortRows(sorted, s.orderBy); err != nil {
+				return nil, err
+			}
+			sorted.orders = cmd.page.cut(sorted.orders)
+			return sorted, nil
+		}
+		if cmd.page.window {
+			return &rows{kind: "orders", orders: res.orders}, nil
+		}
+		return s.sorted(&rows{kind: "orders", orders: res.orders})
+	case "findOrder":
 		return &rows{kind: "orders", orders: res.orders}, nil
-	case "listInventory":
-		return &rows{kind: "inventory", inventory: res.inventory}, nil
+	case "listOrdersBefore", "listOrdersBetween":
+		return s.sorted(&rows{kind: "orders", orders: res.orders})
+	case "listInventory", "findInventory":
+		return s.sorted(&rows{kind: "inventory", inventory: res.inventory})
+	case "listProducts":
+		return s.sorted(&rows{kind: "products", products: res.products})
+	case "listCustomers", "findCustomer":
+		return s.sorted(&rows{kind: "customers", customers: res.customers})
+	case "countOrders", "countInventory", "countProducts":
+		return &rows{kind: "scalar", column: "count", scalar: res.scalar}, nil
+	case "sumInventoryValue":
+		return &rows{kind: "scalar", column: "sum", scalar: res.scalar}, nil
+	case "groupInventoryCategories":
+		return &rows{kind: "categories", groups: res.groups}, nil
+	case "listDeliveries":
+		return s.sorted(&rows{kind: "deliveries", deliveries: res.deliveries})
 	default:
 		return nil, errors.New("query only supports listing")
 	}
 }
 
+// sorted applies the statement's ORDER BY to freshly listed rows.
+func (s *stmt) sorted(r *rows) (driver.Rows, error) {
+	if err := sortRows(r, s.orderBy); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// cutoffArg reads the single timestamp argument shared by the date-bounded order statements.
+func cutoffArg(args []driver.Value) (time.Time, error) {
+	if len(args) < 1 {
+		return time.Time{}, errors.New("expected cutoff time")
+	}
+	return toTime(args[0])
+}
+
 // execResult fulfills the driver.Result interface with the generated identifier.
+// affected carries the count for bulk statements that touch more than one row.
 type execResult struct {
-	id int64
+	id       int64
+	affected int64
 }
 
 func (r execResult) LastInsertId() (int64, error) { return r.id, nil }
 func (r execResult) RowsAffected() (int64, error) {
+	if r.affected > 0 {
+		return r.affected, nil
+	}
 	if r.id == 0 {
 		return 0, nil
 	}
@@ -395,26 +1145,77 @@ func (r execResult) RowsAffected() (int64, error) {
 
 // rows iterates through the stored records while serving Columns and Next calls.
 type rows struct {
-	kind      string
-	orders    []orderRecord
-	inventory []inventoryRecord
-	index     int
+	kind       string
+	orders     []orderRecord
+	inventory  []inventoryRecord
+	products   []productRecord
+	column     string
+	scalar     int64
+	groups     []categoryGroup
+	deliveries []deliveryRecord
+	customers  []customerRecord
+	index      int
 }
 
 // Columns aligns with the SELECT projection used by the repository.
 func (r *rows) Columns() []string {
-	if r.kind == "inventory" {
-		return []string{"id", "name", "category", "available_count", "price_cents", "baked_at"}
+	switch r.kind {
+	case "inventory":
+		return []string{"id", "name", "category", "available_count", "price_cents", "baked_at", "product_id", "reserved_count", "image", "featured"}
+	case "products":
+		return []string{"id", "name", "description", "category", "base_price_cents", "image"}
+	case "scalar":
+		return []string{r.column}
+	case "categories":
+		return []string{"category", "count"}
+	case "deliveries":
+		return []string{"id", "order_id", "delivery_date", "kind", "item", "quantity"}
+	case "customers":
+		return []string{"id", "phone", "name", "address", "email", "created_at", "last_order_at"}
 	}
-	return []string{"id", "name", "address", "phone", "items", "bread_schedule", "croissant_schedule", "comment"}
+	return []string{"id", "name", "address", "phone", "items", "bread_schedule", "croissant_schedule", "comment", "lat", "lng", "status", "reservations", "created_at", "email", "customer_id"}
 }
 
-// Close is a no-op for the lightweight row iterator.
-func (r *rows) Close() error { return nil }
+// Close drops the copied records. The rows already hold a private copy taken on the store goroutine
+// and Next walks it lazily, so a repository cursor broken off early releases the rest here instead of
+// waiting for the *sql.Rows to be collected.
+func (r *rows) Close() error {
+	r.orders, r.inventory, r.products, r.groups, r.deliveries, r.customers = nil, nil, nil, nil, nil, nil
+	return nil
+}
 
 // Next moves through the records and writes the column data into the provided slice.
 func (r *rows) Next(dest []driver.Value) error {
 	switch r.kind {
+	case "scalar":
+		if r.index > 0 {
+			return io.EOF
+		}
+		r.index++
+		dest[0] = r.scalar
+		return nil
+	case "deliveries":
+		if r.index >= len(r.deliveries) {
+			return io.EOF
+		}
+		record := r.deliveries[r.index]
+		r.index++
+		dest[0] = record.ID
+		dest[1] = record.OrderID
+		dest[2] = record.Date
+		dest[3] = record.Kind
+		dest[4] = record.Item
+		dest[5] = int64(record.Quantity)
+		return nil
+	case "categories":
+		if r.index >= len(r.groups) {
+			return io.EOF
+		}
+		group := r.groups[r.index]
+		r.index++
+		dest[0] = group.category
+		dest[1] = group.count
+		return nil
 	case "inventory":
 		if r.index >= len(r.inventory) {
 			return io.EOF
@@ -426,7 +1227,38 @@ func (r *rows) Next(dest []driver.Value) error {
 		dest[2] = record.Category
 		dest[3] = record.AvailableCount
 		dest[4] = record.PriceCents
-		dest[5] = record.BakedAt
+		dest[5] = nullableTime(record.BakedAt)
+		dest[6] = record.ProductID
+		dest[7] = int64(record.ReservedCount)
+		dest[8] = record.Image
+		dest[9] = record.Featured
+		return nil
+	case "customers":
+		if r.index >= len(r.customers) {
+			return io.EOF
+		}
+		record := r.customers[r.index]
+		r.index++
+		dest[0] = record.ID
+		dest[1] = record.Phone
+		dest[2] = record.Name
+		dest[3] = record.Address
+		dest[4] = record.Email
+		dest[5] = nullableTime(record.CreatedAt)
+		dest[6] = nullableTime(record.LastOrderAt)
+		return nil
+	case "products":
+		if r.index >= len(r.products) {
+			return io.EOF
+		}
+		record := r.products[r.index]
+		r.index++
+		dest[0] = record.ID
+		dest[1] = record.Name
+		dest[2] = record.Description
+		dest[3] = record.Category
+		dest[4] = record.BasePriceCents
+		dest[5] = record.Image
 		return nil
 	default:
 		if r.index >= len(r.orders) {
@@ -442,6 +1274,13 @@ func (r *rows) Next(dest []driver.Value) error {
 		dest[5] = record.BreadJSON
 		dest[6] = record.CroissantJSON
 		dest[7] = record.Comment
+		dest[8] = nullableFloat(record.Lat)
+		dest[9] = nullableFloat(record.Lng)
+		dest[10] = record.Status
+		dest[11] = record.Reservations
+		dest[12] = nullableTime(record.CreatedAt)
+		dest[13] = record.Email
+		dest[14] = nullableInt(record.CustomerID)
 		return nil
 	}
 }
@@ -504,9 +1343,78 @@ func toInt64(value driver.Value) int64 {
 	}
 }
 
+// toBool converts driver.Value to a bool for flag columns, accepting SQLite-style 0/1 as well.
+func toBool(value driver.Value) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	default:
+		return false
+	}
+}
+
+// toFloat converts driver.Value to a float64 for multipliers.
+func toFloat(value driver.Value) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	default:
+		return 0
+	}
+}
+
+// adjustPrice mirrors the SQL round(price_cents * ?) + ? used by bulk price changes.
+func adjustPrice(cents int, multiplier float64, delta int) int {
+	return int(math.Round(float64(cents)*multiplier)) + delta
+}
+
+// toFloatPtr reads an optional REAL argument; nil stays nil so missing coordinates are not stored as zero.
+func toFloatPtr(value driver.Value) *float64 {
+	var v float64
+	switch typed := value.(type) {
+	case float64:
+		v = typed
+	case int64:
+		v = float64(typed)
+	default:
+		return nil
+	}
+	return &v
+}
+
+// nullableFloat emits a stored optional float, or NULL when it was never set.
+func nullableFloat(v *float64) driver.Value {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// nullableInt reports an unset reference such as an unlinked customer_id as NULL.
+func nullableInt(v int64) driver.Value {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
+
+// nullableTime reports an unset timestamp as NULL, the way a SQL backend returns a column never written.
+func nullableTime(t time.Time) driver.Value {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
 // toTime handles the baked_at column conversions.
 func toTime(value driver.Value) (time.Time, error) {
 	switch v := value.(type) {
+	case nil:
+		return time.Time{}, nil
 	case time.Time:
 		return v.UTC(), nil
 	case string:
@@ -532,31 +1440,60 @@ func toTime(value driver.Value) (time.Time, error) {
 	}
 }
 
-// Register exposes the driver under the requested label for consumers.
-func Register(dbType, path string) (string, func(), error) {
+// Options tunes how the store persists its snapshot.
+type Options struct {
+	// Compress gzips the snapshot; the default file name becomes <driver>.json.gz.
+	Compress bool
+	// Clock stamps created_at on inserted rows; nil uses the system clock.
+	Clock clock.Clock
+	// MaxSnapshotBytes limits the uncompressed snapshot on load and on every write;
+	// zero means DefaultMaxSnapshotBytes.
+	MaxSnapshotBytes int64
+	// Logger reports snapshots that could not be written; nil uses the standard logger.
+	Logger *log.Logger
+}
+
+// Register exposes the driver under the requested label for consumers. Besides the name it returns
+// the driver's Flush, for checkpoints on demand, and the cleanup that writes the final snapshot.
+func Register(dbType, path string, opts Options) (string, func() error, func(), error) {
 	switch dbType {
 	case "chai", "sqlite", "duckdb", "pgx", "clickhouse":
 	default:
-		return "", func() {}, fmt.Errorf("unsupported db type %s", dbType)
+		return "", nil, func() {}, fmt.Errorf("unsupported db type %s", dbType)
 	}
 	driverName := "bakery-" + dbType
+	loadPath := path
 	if path == "" {
 		cwd, err := os.Getwd()
 		if err != nil {
-			return "", func() {}, err
+			return "", nil, func() {}, err
 		}
 		path = filepath.Join(cwd, driverName+".json")
+		loadPath = path
+		if opts.Compress {
+			path += ".gz"
+			loadPath = path
+			// Turning compression on should not orphan the existing plain snapshot, so it seeds the first load.
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				loadPath = strings.TrimSuffix(path, ".gz")
+			}
+		}
 	}
-	store, err := newStore(path)
+	// An explicit .gz path keeps being written compressed even when the flag is forgotten.
+	compress := opts.Compress || strings.HasSuffix(path, ".gz")
+	store, err := newStore(path, loadPath, compress, opts)
 	if err != nil {
-		return "", func() {}, err
+		return "", nil, func() {}, err
 	}
-	sql.Register(driverName, &Driver{store: store})
+	drv := &Driver{store: store}
+	sql.Register(driverName, drv)
 	cleanup := func() {
-		store.queuePersist()
+		if err := store.flush(); err != nil {
+			store.logger.Printf("memory driver: final snapshot not written to %s: %v", store.snapshotPath, err)
+		}
 		store.close()
 	}
-	return driverName, cleanup, nil
+	return driverName, drv.Flush, cleanup, nil
 }
 
 // EnsureSchema executes CREATE TABLE statements so external databases get the right layout.
@@ -570,7 +1507,14 @@ func EnsureSchema(ctx context.Context, db *sql.DB) error {
                         items TEXT,
                         bread_schedule TEXT,
                         croissant_schedule TEXT,
-                        comment TEXT
+                        comment TEXT,
+                        lat REAL,
+                        lng REAL,
+                        status TEXT,
+                        reservations TEXT,
+                        created_at TIMESTAMP,
+                        email TEXT,
+                        customer_id INTEGER
                 )`,
 		`CREATE TABLE IF NOT EXISTS inventory (
                         id INTEGER PRIMARY KEY,
@@ -578,7 +1522,37 @@ func EnsureSchema(ctx context.Context, db *sql.DB) error {
                         category TEXT,
                         available_count INTEGER,
                         price_cents INTEGER,
-                        baked_at TIMESTAMP
+                        baked_at TIMESTAMP,
+                        product_id INTEGER,
+                        reserved_count INTEGER,
+                        image TEXT,
+                        featured BOOLEAN
+                )`,
+		`CREATE TABLE IF NOT EXISTS deliveries (
+                        id INTEGER PRIMARY KEY,
+                        order_id INTEGER,
+                        delivery_date TEXT,
+                        kind TEXT,
+                        item TEXT,
+                        quantity INTEGER,
+                        UNIQUE (order_id, delivery_date, kind, item)
+                )`,
+		`CREATE TABLE IF NOT EXISTS customers (
+                        id INTEGER PRIMARY KEY,
+                        phone TEXT UNIQUE,
+                        name TEXT,
+                        address TEXT,
+                        email TEXT,
+                        created_at TIMESTAMP,
+                        last_order_at TIMESTAMP
+                )`,
+		`CREATE TABLE IF NOT EXISTS product (
+                        id INTEGER PRIMARY KEY,
+                        name TEXT,
+                        description TEXT,
+                        category TEXT,
+                        base_price_cents INTEGER,
+                        image TEXT
                 )`,
 	}
 	for _, stmt := range statements {
@@ -592,42 +1566,11 @@ func EnsureSchema(ctx context.Context, db *sql.DB) error {
 	return nil
 }
 
-// readSnapshot loads the persisted JSON file if it exists.
-func readSnapshot(path string) (*snapshot, error) {
-	if path == "" {
-		return nil, nil
-	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	if len(data) == 0 {
-		return nil, nil
-	}
-	var snap snapshot
-	if err := json.Unmarshal(data, &snap); err != nil {
-		return nil, err
-	}
-	return &snap, nil
-}
-
-// writeSnapshot persists the current state to disk.
-func writeSnapshot(path string, snap snapshot) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent(snap, "", "  ")
-	if err != nil {
-		return err
-	}
-	temp := path + ".tmp"
-	if err := os.WriteFile(temp, data, 0o644); err != nil {
-		return err
-	}
-	return os.Rename(temp, path)
+// cloneDeliveries duplicates the slice so snapshots never share the live backing array.
+func cloneDeliveries(src []deliveryRecord) []deliveryRecord {
+	out := make([]deliveryRecord, len(src))
+	copy(out, src)
+	return out
 }
 
 // cloneOrders duplicates the slice so callers cannot mutate internal state.
@@ -637,6 +1580,20 @@ func cloneOrders(src []orderRecord) []orderRecord {
 	return out
 }
 
+// cloneCustomers duplicates the customer slice for safe sharing.
+func cloneCustomers(src []customerRecord) []customerRecord {
+	out := make([]customerRecord, len(src))
+	copy(out, src)
+	return out
+}
+
+// cloneProducts duplicates the catalog slice for safe sharing.
+func cloneProducts(src []productRecord) []productRecord {
+	out := make([]productRecord, len(src))
+	copy(out, src)
+	return out
+}
+
 // cloneInventory duplicates the inventory slice for safe sharing.
 func cloneInventory(src []inventoryRecord) []inventoryRecord {
 	out := make([]inventoryRecord, len(src))
@@ -0,0 +1,62 @@
+package memorydriver
+
+import "fmt"
+
+// snapshotVersion is the layout this build writes. Bump it together with a new entry in
+// snapshotMigrations whenever a change to the records needs more than Go's zero values on load.
+//
+//	1: every snapshot written before versioning, including those without a "version" key.
+//	2: orders always carry a status, and counters are never behind the ids they hand out.
+const snapshotVersion = 2
+
+// snapshotMigrations upgrade a snapshot by one version each; the entry at index i turns version
+// i+1 into version i+2. They run in order on load, so a migration only has to know the version
+// right before its own.
+var snapshotMigrations = []func(*snapshot){
+	migrateV1,
+}
+
+// migrateSnapshot brings a freshly decoded snapshot up to snapshotVersion. The upgraded snapshot is
+// what the store keeps in memory and writes back on the next change, so a migration runs once per
+// file. A snapshot from a newer build is refused rather than loaded, because this build would drop
+// whatever the newer one added the first time it saved.
+func migrateSnapshot(snap *snapshot) error {
+	if snap.Version == 0 {
+		snap.Version = 1
+	}
+	if snap.Version > snapshotVersion {
+		return fmt.Errorf("snapshot version %d is newer than this build understands (%d); upgrade the bakery binary", snap.Version, snapshotVersion)
+	}
+	for snap.Version < snapshotVersion {
+		snapshotMigrations[snap.Version-1](snap)
+		snap.Version++
+	}
+	return nil
+}
+
+// migrateV1 fills what version 1 snapshots could leave out. Orders from before statuses existed
+// were all still open, so they become pending as the repository already read them, and the
+// customer counter, added later than the table it counts, catches up with the customers present.
+// Any counter behind its ids is raised so the next insert cannot reuse an id.
+func migrateV1(snap *snapshot) {
+	for i := range snap.Orders {
+		if snap.Orders[i].Status == "" {
+			snap.Orders[i].Status = "pending"
+		}
+	}
+	for _, o := range snap.Orders {
+		snap.OrderCounter = max(snap.OrderCounter, o.ID)
+	}
+	for _, item := range snap.Inventory {
+		snap.InventoryCounter = max(snap.InventoryCounter, item.ID)
+	}
+	for _, p := range snap.Products {
+		snap.ProductCounter = max(snap.ProductCounter, p.ID)
+	}
+	for _, d := range snap.Deliveries {
+		snap.DeliveryCounter = max(snap.DeliveryCounter, d.ID)
+	}
+	for _, c := range snap.Customers {
+		snap.CustomerCounter = max(snap.CustomerCounter, c.ID)
+	}
+}
@@ -0,0 +1,112 @@
+package memorydriver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bakery/pkg/clock"
+)
+
+// v1Snapshot is laid out the way builds before versioning wrote it: no version key, an order from
+// before statuses, counters behind their ids and customers without a customer_counter.
+const v1Snapshot = `{
+	"orders": [
+		{"id": 3, "name": "Ivan", "items": "[]", "bread_schedule": "{}", "croissant_schedule": "[]", "created_at": "2026-01-05T07:00:00Z"},
+		{"id": 7, "name": "Olga", "items": "[]", "bread_schedule": "{}", "croissant_schedule": "[]", "status": "delivered", "created_at": "2026-01-06T07:00:00Z"}
+	],
+	"inventory": [{"id": 4, "name": "Багет", "category": "bread", "available_count": 5, "price_cents": 12000, "baked_at": "2026-01-06T05:00:00Z"}],
+	"products": [],
+	"deliveries": [],
+	"customers": [{"id": 2, "phone": "+79990000000", "name": "Ivan"}],
+	"order_counter": 5,
+	"inventory_counter": 4,
+	"product_counter": 0,
+	"delivery_counter": 0
+}`
+
+func TestReadSnapshotMigratesV1(t *testing.T) {
+	gzipped := func(data string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(data))
+		gz.Close()
+		return buf.Bytes()
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"without a version key", []byte(v1Snapshot)},
+		{"marked version 1", []byte(strings.Replace(v1Snapshot, "{", `{"version": 1,`, 1))},
+		{"gzipped", gzipped(v1Snapshot)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bakery.json")
+			if err := os.WriteFile(path, tt.data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			snap, err := readSnapshot(path, DefaultMaxSnapshotBytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if snap.Version != snapshotVersion {
+				t.Errorf("version = %d, want %d", snap.Version, snapshotVersion)
+			}
+			if got := []string{snap.Orders[0].Status, snap.Orders[1].Status}; got[0] != "pending" || got[1] != "delivered" {
+				t.Errorf("statuses = %v, want [pending delivered]", got)
+			}
+			if snap.OrderCounter != 7 || snap.InventoryCounter != 4 || snap.CustomerCounter != 2 {
+				t.Errorf("counters orders %d, inventory %d, customers %d; want 7, 4, 2", snap.OrderCounter, snap.InventoryCounter, snap.CustomerCounter)
+			}
+			if snap.Inventory[0].Name != "Багет" || snap.Customers[0].Phone != "+79990000000" {
+				t.Errorf("records changed on the way: %+v %+v", snap.Inventory[0], snap.Customers[0])
+			}
+		})
+	}
+}
+
+// Once opened, an upgraded store hands out fresh ids and writes the current version back.
+func TestOpenUpgradesV1Snapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bakery.json")
+	if err := os.WriteFile(path, []byte(v1Snapshot), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	db, cleanup := openTestStore(t, path, clock.NewManual(testNow))
+	result, err := db.Exec("INSERT INTO orders (name, address, phone, items, bread_schedule, croissant_schedule, comment, lat, lng, status, reservations) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		"Petr", "", "", "[]", "{}", "[]", "", nil, nil, "pending", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := result.LastInsertId(); id != 8 {
+		t.Errorf("new order id = %d, want 8 after the stale counter of 5 was raised to 7", id)
+	}
+	if got := queryIDs(t, db, "SELECT "+orderColumns+" FROM orders WHERE status = ? ORDER BY id", "pending"); len(got) != 2 || got[0] != 3 || got[1] != 8 {
+		t.Errorf("pending orders = %v, want [3 8]", got)
+	}
+
+	cleanup()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &written); err != nil || written.Version != snapshotVersion {
+		t.Errorf("written version = %d (%v), want %d", written.Version, err, snapshotVersion)
+	}
+}
+
+func TestMigrateSnapshotRefusesNewerVersions(t *testing.T) {
+	snap := snapshot{Version: snapshotVersion + 1}
+	if err := migrateSnapshot(&snap); err == nil || !strings.Contains(err.Error(), "upgrade the bakery binary") {
+		t.Errorf("error = %v, want a refusal asking for an upgrade", err)
+	}
+}
@@ -0,0 +1,156 @@
+package memorydriver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sortKey is one term of an ORDER BY clause.
+type sortKey struct {
+	column string
+	desc   bool
+}
+
+// parseOrderBy reads the ORDER BY clause of a lowercased listing query, so results come back in the
+// order a SQL backend would return them instead of in insertion order. A query without the clause
+// yields no keys and keeps insertion order.
+func parseOrderBy(query string) ([]sortKey, error) {
+	_, clause, found := strings.Cut(query, " order by ")
+	if !found {
+		return nil, nil
+	}
+	// LIMIT and OFFSET follow ORDER BY in SQL and are not sort terms.
+	clause, _, _ = strings.Cut(clause, " limit ")
+	clause, _, _ = strings.Cut(clause, " offset ")
+	var keys []sortKey
+	for _, term := range strings.Split(clause, ",") {
+		fields := strings.Fields(term)
+		switch {
+		case len(fields) == 1:
+			keys = append(keys, sortKey{column: fields[0]})
+		case len(fields) == 2 && (fields[1] == "asc" || fields[1] == "desc"):
+			keys = append(keys, sortKey{column: fields[0], desc: fields[1] == "desc"})
+		default:
+			return nil, fmt.Errorf("unsupported ORDER BY term %q", strings.TrimSpace(term))
+		}
+	}
+	return keys, nil
+}
+
+// sortRows reorders the records behind r by the keys. Values are read through Next, so every column
+// a query can select can also be sorted on; ties keep insertion order, like a stable sort on id.
+func sortRows(r *rows, keys []sortKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	columns := r.Columns()
+	positions := make([]int, len(keys))
+	for i, key := range keys {
+		positions[i] = -1
+		for j, column := range columns {
+			if column == key.column {
+				positions[i] = j
+			}
+		}
+		if positions[i] < 0 {
+			return fmt.Errorf("unsupported ORDER BY column %q", key.column)
+		}
+	}
+
+	// Only the sort columns are kept, one flat run of len(keys) values per record, read through a
+	// single scratch row; long listings would otherwise hold a full copy of every row while sorting.
+	row := make([]driver.Value, len(columns))
+	var values []driver.Value
+	for r.Next(row) == nil {
+		for _, position := range positions {
+			values = append(values, row[position])
+		}
+	}
+	perm := make([]int, len(values)/len(keys))
+	for i := range perm {
+		perm[i] = i
+	}
+	width := len(keys)
+	sort.SliceStable(perm, func(a, b int) bool {
+		for i, key := range keys {
+			cmp := compareValues(values[perm[a]*width+i], values[perm[b]*width+i])
+			if cmp == 0 {
+				continue
+			}
+			if key.desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+
+	switch r.kind {
+	case "orders":
+		r.orders = permute(r.orders, perm)
+	case "inventory":
+		r.inventory = permute(r.inventory, perm)
+	case "products":
+		r.products = permute(r.products, perm)
+	case "deliveries":
+		r.deliveries = permute(r.deliveries, perm)
+	case "customers":
+		r.customers = permute(r.customers, perm)
+	}
+	r.index = 0
+	return nil
+}
+
+// permute returns the records in perm's order.
+func permute[T any](records []T, perm []int) []T {
+	out := make([]T, len(perm))
+	for i, j := range perm {
+		out[i] = records[j]
+	}
+	return out
+}
+
+// compareValues orders two column values of the same column. NULL sorts before everything else,
+// as it does in SQLite, the default -db-type.
+func compareValues(a, b driver.Value) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return cmpOrdered(x, y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmpOrdered(x, y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func cmpOrdered[T int64 | float64](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
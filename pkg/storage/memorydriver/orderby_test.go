@@ -0,0 +1,107 @@
+package memorydriver
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"bakery/pkg/clock"
+)
+
+var testNow = time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)
+
+// orderColumns is the projection the order repository selects.
+const orderColumns = "id, name, address, phone, items, bread_schedule, croissant_schedule, comment, lat, lng, status, reservations, created_at, email, customer_id"
+
+// queryIDs runs a listing and returns the first column of every row, which is the id in every
+// projection the driver serves.
+func queryIDs(t *testing.T, db *sql.DB, query string, args ...any) []int64 {
+	t.Helper()
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	defer rows.Close()
+	columns, _ := rows.Columns()
+	var ids []int64
+	for rows.Next() {
+		values := make([]any, len(columns))
+		targets := make([]any, len(columns))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, values[0].(int64))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestParseOrderBy(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    []sortKey
+		wantErr bool
+	}{
+		{"select id from orders", nil, false},
+		{"select id from orders order by id desc", []sortKey{{"id", true}}, false},
+		{"select id from orders order by id desc limit ? offset ?", []sortKey{{"id", true}}, false},
+		{"select id from deliveries order by delivery_date, id", []sortKey{{"delivery_date", false}, {"id", false}}, false},
+		{"select id from inventory order by baked_at asc", []sortKey{{"baked_at", false}}, false},
+		{"select id from orders order by id desc nulls last", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseOrderBy(tt.query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: keys = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+// Rows are inserted out of order, so insertion order and the requested order disagree and only a
+// real sort passes.
+func TestListingsSortDescending(t *testing.T) {
+	db := OpenTest(t, clock.NewManual(testNow))
+	for i, baked := range []time.Time{testNow.Add(-2 * time.Hour), testNow, testNow.Add(-5 * time.Hour)} {
+		if _, err := db.Exec("INSERT INTO inventory (name, category, available_count, price_cents, baked_at, product_id) VALUES (?, ?, ?, ?, ?, ?)",
+			"loaf", "bread", i+1, 100, baked, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, created := range []time.Time{testNow.Add(-time.Hour), testNow.Add(-3 * time.Hour), testNow} {
+		if _, err := db.Exec("INSERT INTO orders (name, address, phone, items, bread_schedule, croissant_schedule, comment, lat, lng, status, reservations, created_at, email, customer_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			"Ivan", "Lenina 1", "+7999", "[]", "{}", "[]", "", nil, nil, "pending", "{}", created, "", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		args  []any
+		want  []int64
+	}{
+		{"inventory by baked_at desc", "SELECT id, name, category, available_count, price_cents, baked_at, product_id, reserved_count, image, featured FROM inventory ORDER BY baked_at DESC", nil, []int64{2, 1, 3}},
+		{"inventory by baked_at asc", "SELECT id, name, category, available_count, price_cents, baked_at, product_id, reserved_count, image, featured FROM inventory ORDER BY baked_at", nil, []int64{3, 1, 2}},
+		{"orders by id desc", "SELECT " + orderColumns + " FROM orders ORDER BY id DESC", nil, []int64{3, 2, 1}},
+		{"orders by created_at desc", "SELECT " + orderColumns + " FROM orders ORDER BY created_at DESC", nil, []int64{3, 1, 2}},
+		{"orders by created_at desc, paged", "SELECT " + orderColumns + " FROM orders ORDER BY created_at DESC LIMIT ? OFFSET ?", []any{2, 1}, []int64{1, 2}},
+		{"orders by id desc, paged", "SELECT " + orderColumns + " FROM orders ORDER BY id DESC LIMIT ? OFFSET ?", []any{1, 1}, []int64{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queryIDs(t, db, tt.query, tt.args...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package memorydriver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sort"
+)
+
+// orderPage is the WHERE status = ? AND customer_id = ? and LIMIT ? OFFSET ? part of an order listing. When the listing is
+// ordered by id alone, window is set and the store copies just the rows on the page; any other order
+// needs every matching row sorted first, so the cut happens in the statement instead.
+type orderPage struct {
+	filter bool
+	status string
+	// byCustomer keeps only the orders linked to customer.
+	byCustomer bool
+	customer   int64
+	paged      bool
+	limit      int
+	offset     int
+	window     bool
+	desc       bool
+}
+
+// orderPage reads the listing's bound values and decides whether the store can window the rows.
+func (s *stmt) orderPage(args []driver.Value) (orderPage, error) {
+	want := 0
+	if s.statusFilter {
+		want++
+	}
+	if s.customerFilter {
+		want++
+	}
+	if s.paged {
+		want += 2
+	}
+	if len(args) < want {
+		return orderPage{}, fmt.Errorf("expected %d arguments, got %d", want, len(args))
+	}
+	var page orderPage
+	if s.statusFilter {
+		page.filter, page.status = true, toString(args[0])
+		args = args[1:]
+	}
+	if s.customerFilter {
+		page.byCustomer, page.customer = true, toInt64(args[0])
+		args = args[1:]
+	}
+	if s.paged {
+		page.paged = true
+		page.limit, page.offset = toInt(args[0]), max(toInt(args[1]), 0)
+	}
+	switch {
+	case len(s.orderBy) == 0:
+		page.window = page.paged
+	case len(s.orderBy) == 1 && s.orderBy[0].column == "id":
+		page.window, page.desc = page.paged, s.orderBy[0].desc
+	}
+	return page, nil
+}
+
+// cut applies LIMIT and OFFSET to rows that are already filtered and sorted. A negative limit is
+// SQLite's "no limit".
+func (p orderPage) cut(records []orderRecord) []orderRecord {
+	if p.offset >= len(records) {
+		return nil
+	}
+	records = records[p.offset:]
+	if p.limit >= 0 && p.limit < len(records) {
+		records = records[:p.limit]
+	}
+	return records
+}
+
+// listOrders copies only what a listing asks for instead of the whole history. Orders are kept in
+// ascending id order, because ids come from a counter and rows are only ever appended, so a window
+// by id is a walk from either end that stops once the page is full.
+func (s *store) listOrders(page orderPage) []orderRecord {
+	if !page.filter && !page.byCustomer && !page.window {
+		return cloneOrders(s.orders)
+	}
+	limit, offset := -1, 0
+	if page.window {
+		limit, offset = page.limit, page.offset
+	}
+	var out []orderRecord
+	if limit >= 0 {
+		out = make([]orderRecord, 0, min(limit, len(s.orders)))
+	}
+	skipped := 0
+	for i := range s.orders {
+		if limit >= 0 && len(out) >= limit {
+			break
+		}
+		record := s.orders[i]
+		if page.desc {
+			record = s.orders[len(s.orders)-1-i]
+		}
+		if page.filter && record.Status != page.status {
+			continue
+		}
+		if page.byCustomer && record.CustomerID != page.customer {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		out = append(out, record)
+	}
+	return out
+}
+
+// findOrder answers SELECT ... WHERE id = ? with a binary search over the same ascending ids, so
+// looking one order up does not copy the whole history the way an unfiltered listing does.
+func (s *store) findOrder(id int64) []orderRecord {
+	i := sort.Search(len(s.orders), func(i int) bool { return s.orders[i].ID >= id })
+	if i == len(s.orders) || s.orders[i].ID != id {
+		return nil
+	}
+	return []orderRecord{s.orders[i]}
+}
@@ -0,0 +1,97 @@
+package memorydriver
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bakery/pkg/clock"
+)
+
+// openSeeded opens a store loaded from a snapshot of n orders, a quarter of them still pending, so a
+// benchmark starts from a long history without timing n inserts.
+func openSeeded(tb testing.TB, n int) *sql.DB {
+	tb.Helper()
+	seed := snapshot{Version: snapshotVersion, OrderCounter: int64(n)}
+	seed.Orders = make([]orderRecord, n)
+	for i := range seed.Orders {
+		status := "delivered"
+		if i%4 == 0 {
+			status = "pending"
+		}
+		seed.Orders[i] = orderRecord{
+			ID:            int64(i + 1),
+			Name:          "Ivan",
+			Address:       "Lenina 1",
+			Phone:         "+79990000000",
+			ItemsJSON:     `[{"name":"Хлеб","quantity":2}]`,
+			BreadJSON:     `{"days":["monday"],"frequency":"everyday","start_date":"2026-10-19"}`,
+			CroissantJSON: `[{"day":"monday","quantity":2}]`,
+			Status:        status,
+			CreatedAt:     testNow.Add(time.Duration(i-n) * time.Minute),
+		}
+	}
+	path := filepath.Join(tb.TempDir(), "bakery.json")
+	data, err := json.Marshal(seed)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		tb.Fatal(err)
+	}
+	db, cleanup := openTestStore(tb, path, clock.NewManual(testNow))
+	tb.Cleanup(cleanup)
+	return db
+}
+
+// BenchmarkListOrdersPage runs the admin board's listings against 100k orders. The id-ordered pages
+// are windowed on the store goroutine and copy only their rows, whatever the offset; the full
+// listing and the created_at sort copy every matching row and show what the window saves.
+func BenchmarkListOrdersPage(b *testing.B) {
+	const orders = 100_000
+	db := openSeeded(b, orders)
+	benchmarks := []struct {
+		name  string
+		query string
+		args  []any
+		rows  int
+	}{
+		{"first page", "SELECT " + orderColumns + " FROM orders ORDER BY id DESC LIMIT ? OFFSET ?", []any{50, 0}, 50},
+		{"deep page", "SELECT " + orderColumns + " FROM orders ORDER BY id DESC LIMIT ? OFFSET ?", []any{50, 90_000}, 50},
+		{"pending page", "SELECT " + orderColumns + " FROM orders WHERE status = ? ORDER BY id DESC LIMIT ? OFFSET ?", []any{"pending", 50, 0}, 50},
+		{"page by created_at", "SELECT " + orderColumns + " FROM orders ORDER BY created_at DESC LIMIT ? OFFSET ?", []any{50, 0}, 50},
+		{"all pending", "SELECT " + orderColumns + " FROM orders WHERE status = ? ORDER BY id DESC", []any{"pending"}, orders / 4},
+		{"everything", "SELECT " + orderColumns + " FROM orders ORDER BY id DESC", nil, orders},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if got := countRows(b, db, bm.query, bm.args...); got != bm.rows {
+					b.Fatalf("got %d rows, want %d", got, bm.rows)
+				}
+			}
+		})
+	}
+}
+
+// countRows runs a listing and counts its rows without decoding them.
+func countRows(tb testing.TB, db *sql.DB, query string, args ...any) int {
+	tb.Helper()
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer rows.Close()
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		tb.Fatal(err)
+	}
+	return n
+}
@@ -0,0 +1,104 @@
+package memorydriver
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// DefaultMaxSnapshotBytes caps the uncompressed snapshot when Options.MaxSnapshotBytes is zero. The whole
+// snapshot is held in memory, so a runaway one would otherwise take the process down at startup.
+const DefaultMaxSnapshotBytes = 512 << 20
+
+// gzipMagic starts every gzip stream, which lets readSnapshot detect compression regardless of the file name.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// readSnapshot loads the persisted JSON file if it exists, transparently decompressing gzip snapshots,
+// and upgrades older layouts to snapshotVersion.
+// Snapshots larger than maxBytes once decompressed are refused before they are fully read.
+func readSnapshot(path string, maxBytes int64) (*snapshot, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewReader(file)
+	head, err := buffered.Peek(len(gzipMagic))
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var reader io.Reader = buffered
+	if bytes.Equal(head, gzipMagic) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("snapshot %s is larger than the %d byte limit; raise the limit or trim the snapshot", path, maxBytes)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	if err := migrateSnapshot(&snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// writeSnapshot persists the current state to disk, gzip-compressed when requested.
+// A snapshot over maxBytes is refused so the previous, loadable file stays in place.
+func writeSnapshot(path string, snap snapshot, compress bool, maxBytes int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > maxBytes {
+		return fmt.Errorf("snapshot of %d bytes is larger than the %d byte limit; kept the last snapshot", len(data), maxBytes)
+	}
+	if compress {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(data); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
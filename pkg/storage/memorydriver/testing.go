@@ -0,0 +1,56 @@
+package memorydriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"bakery/pkg/clock"
+)
+
+// OpenTest opens a store of the test's own on a snapshot in the test's temporary directory, with the
+// schema in place, and closes it when the test ends. clk stamps created_at on inserted rows; nil uses
+// the system clock. Tests cannot go through Register: database/sql panics when a driver name is
+// registered twice, and every test wants a database nothing else has touched. The snapshot is
+// written as in production, so benchmarks pay for it too.
+func OpenTest(t testing.TB, clk clock.Clock) *sql.DB {
+	t.Helper()
+	db, cleanup := openTestStore(t, filepath.Join(t.TempDir(), "bakery.json"), clk)
+	t.Cleanup(cleanup)
+	if err := EnsureSchema(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// openTestStore opens a store on the snapshot at path for OpenTest and for the driver's own tests,
+// which load prepared snapshots and read back what the store writes. The cleanup closes the handle,
+// writes the final snapshot and stops the store.
+func openTestStore(t testing.TB, path string, clk clock.Clock) (*sql.DB, func()) {
+	t.Helper()
+	store, err := newStore(path, path, false, Options{Clock: clk, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector{driver: &Driver{store: store}})
+	return db, func() {
+		db.Close()
+		if err := store.flush(); err != nil {
+			t.Errorf("memory driver: final snapshot not written to %s: %v", path, err)
+		}
+		store.close()
+	}
+}
+
+// connector hands database/sql the connections of one unregistered store.
+type connector struct {
+	driver *Driver
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open("") }
+
+func (c connector) Driver() driver.Driver { return c.driver }
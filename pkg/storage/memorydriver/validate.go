@@ -0,0 +1,143 @@
+package memorydriver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ValidateSnapshot loads the snapshot at path the way the driver does on startup and reports
+// everything about it that would make the store misbehave, one readable line per issue: ids that
+// repeat or that the next insert would reuse, timestamps that never got set, JSON columns that do
+// not parse, and references to rows that are gone. A snapshot that does not decode at all yields a
+// single issue saying where it broke, which is what a partial write looks like. The error is only
+// for files that cannot be read; no issues means the store would load it cleanly.
+func ValidateSnapshot(path string, maxBytes int64) ([]string, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxSnapshotBytes
+	}
+	snap, err := readSnapshot(path, maxBytes)
+	var syntax *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		return []string{fmt.Sprintf("snapshot is not valid JSON at byte %d: %v; it was probably cut off mid-write", syntax.Offset, err)}, nil
+	case errors.As(err, &typeErr):
+		return []string{fmt.Sprintf("snapshot field %s holds a %s at byte %d", typeErr.Field, typeErr.Value, typeErr.Offset)}, nil
+	case err != nil && strings.Contains(err.Error(), "parsing time"):
+		return []string{fmt.Sprintf("snapshot has an unparseable timestamp: %v", err)}, nil
+	case err != nil:
+		return nil, err
+	case snap == nil:
+		return nil, fmt.Errorf("no snapshot at %s, or it is empty", path)
+	}
+
+	var issues []string
+	report := func(format string, args ...any) {
+		issues = append(issues, fmt.Sprintf(format, args...))
+	}
+
+	orderIDs := make([]int64, len(snap.Orders))
+	for i, o := range snap.Orders {
+		orderIDs[i] = o.ID
+	}
+	inventoryIDs := make([]int64, len(snap.Inventory))
+	for i, item := range snap.Inventory {
+		inventoryIDs[i] = item.ID
+	}
+	productIDs := make([]int64, len(snap.Products))
+	for i, p := range snap.Products {
+		productIDs[i] = p.ID
+	}
+	deliveryIDs := make([]int64, len(snap.Deliveries))
+	for i, d := range snap.Deliveries {
+		deliveryIDs[i] = d.ID
+	}
+	customerIDs := make([]int64, len(snap.Customers))
+	for i, c := range snap.Customers {
+		customerIDs[i] = c.ID
+	}
+	orders := checkIDs("orders", orderIDs, snap.OrderCounter, report)
+	checkIDs("inventory", inventoryIDs, snap.InventoryCounter, report)
+	products := checkIDs("products", productIDs, snap.ProductCounter, report)
+	checkIDs("deliveries", deliveryIDs, snap.DeliveryCounter, report)
+	customers := checkIDs("customers", customerIDs, snap.CustomerCounter, report)
+
+	for _, o := range snap.Orders {
+		checkTime("order", o.ID, "created_at", o.CreatedAt, report)
+		for _, column := range []struct{ name, value string }{
+			{"items", o.ItemsJSON},
+			{"bread_schedule", o.BreadJSON},
+			{"croissant_schedule", o.CroissantJSON},
+			{"reservations", o.Reservations},
+		} {
+			// Empty columns are how older rows and orders without reservations look; they read as empty.
+			if strings.TrimSpace(column.value) != "" && !json.Valid([]byte(column.value)) {
+				report("order %d: %s is not valid JSON", o.ID, column.name)
+			}
+		}
+		if o.CustomerID != 0 && !customers[o.CustomerID] {
+			report("order %d: customer %d does not exist", o.ID, o.CustomerID)
+		}
+	}
+	for _, item := range snap.Inventory {
+		checkTime("inventory", item.ID, "created_at", item.CreatedAt, report)
+		if item.ProductID != 0 && !products[item.ProductID] {
+			report("inventory %d: product %d does not exist", item.ID, item.ProductID)
+		}
+		if item.AvailableCount < 0 || item.ReservedCount < 0 {
+			report("inventory %d: negative count (available %d, reserved %d)", item.ID, item.AvailableCount, item.ReservedCount)
+		}
+	}
+	for _, p := range snap.Products {
+		checkTime("product", p.ID, "created_at", p.CreatedAt, report)
+	}
+	for _, d := range snap.Deliveries {
+		if _, err := time.Parse("2006-01-02", d.Date); err != nil {
+			report("delivery %d: delivery_date %q is not a date", d.ID, d.Date)
+		}
+		if !orders[d.OrderID] {
+			report("delivery %d: order %d does not exist", d.ID, d.OrderID)
+		}
+	}
+	phones := make(map[string]int64, len(snap.Customers))
+	for _, c := range snap.Customers {
+		checkTime("customer", c.ID, "created_at", c.CreatedAt, report)
+		if first, ok := phones[c.Phone]; ok {
+			report("customer %d: phone %s already belongs to customer %d", c.ID, c.Phone, first)
+			continue
+		}
+		phones[c.Phone] = c.ID
+	}
+	return issues, nil
+}
+
+// checkIDs reports repeated ids and a counter behind the largest id, which would hand the next
+// insert an id already in use, and returns the ids present for reference checks.
+func checkIDs(table string, ids []int64, counter int64, report func(string, ...any)) map[int64]bool {
+	seen := make(map[int64]bool, len(ids))
+	var highest int64
+	for _, id := range ids {
+		if id <= 0 {
+			report("%s: row with id %d", table, id)
+		}
+		if seen[id] {
+			report("%s: id %d appears more than once", table, id)
+		}
+		seen[id] = true
+		highest = max(highest, id)
+	}
+	if counter < highest {
+		report("%s: counter %d is behind the largest id %d", table, counter, highest)
+	}
+	return seen
+}
+
+// checkTime reports a timestamp that was never set; the driver stamps every row it inserts.
+func checkTime(table string, id int64, column string, value time.Time, report func(string, ...any)) {
+	if value.IsZero() {
+		report("%s %d: %s is missing", table, id, column)
+	}
+}
@@ -1,6 +1,87 @@
 package version
 
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// These values are meant to be overwritten at build time, for example:
+//
+//	go build -ldflags "-X bakery/pkg/version.commit=$(git rev-parse --short HEAD) -X bakery/pkg/version.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// version keeps the release tag in source so plain builds still report which release they came from.
+var (
+	version = "1.1.0"
+	commit  = ""
+	date    = ""
+)
+
+// unknown marks metadata that neither ldflags nor the Go toolchain could provide.
+const unknown = "unknown"
+
+// BuildInfo describes the running binary so support can tell exactly which build an operator runs.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// String renders the info on one line for the -version flag and startup logs.
+func (b BuildInfo) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s, %s)", b.Version, b.Commit, b.BuildDate, b.GoVersion)
+}
+
 // Version keeps the human readable release tag so operators can inspect builds quickly.
 func Version() string {
-	return "1.1.0"
+	if version == "" {
+		return "dev"
+	}
+	return version
+}
+
+// Info gathers the build metadata; when ldflags were not passed it falls back to the VCS
+// stamp the Go toolchain embeds, and finally to "unknown".
+func Info() BuildInfo {
+	info := BuildInfo{
+		Version:   Version(),
+		Commit:    commit,
+		BuildDate: date,
+		GoVersion: runtime.Version(),
+	}
+	if info.Commit == "" || info.BuildDate == "" {
+		if build, ok := debug.ReadBuildInfo(); ok {
+			revision, modified, stamp := "", false, ""
+			for _, setting := range build.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					revision = setting.Value
+				case "vcs.modified":
+					modified = setting.Value == "true"
+				case "vcs.time":
+					stamp = setting.Value
+				}
+			}
+			if info.Commit == "" && revision != "" {
+				if len(revision) > 12 {
+					revision = revision[:12]
+				}
+				if modified {
+					revision += "-dirty"
+				}
+				info.Commit = revision
+			}
+			if info.BuildDate == "" {
+				info.BuildDate = stamp
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = unknown
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = unknown
+	}
+	return info
 }
@@ -11,6 +11,11 @@ mkdir -p "${OUTPUT_DIR}"
 OS_LIST="linux darwin windows freebsd openbsd"
 ARCH_LIST="amd64 arm64"
 
+# Stamp every binary with the commit and build time so -version identifies the exact build.
+COMMIT="$(git rev-parse --short HEAD 2>/dev/null || echo unknown)"
+BUILD_DATE="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+LDFLAGS="-s -w -X bakery/pkg/version.commit=${COMMIT} -X bakery/pkg/version.date=${BUILD_DATE}"
+
 for os in ${OS_LIST}; do
   for arch in ${ARCH_LIST}; do
     echo "Building ${APP_NAME} for ${os}/${arch}"
@@ -20,6 +25,6 @@ for os in ${OS_LIST}; do
       EXT=".exe"
     fi
     GOOS="${os}" GOARCH="${arch}" CGO_ENABLED=0 \
-      go build -ldflags="-s -w" -o "${OUTPUT_DIR}/${BIN_NAME}${EXT}" ./cmd/server
+      go build -ldflags="${LDFLAGS}" -o "${OUTPUT_DIR}/${BIN_NAME}${EXT}" ./cmd/server
   done
 done